@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"inva/config"
+	"inva/pkg/events"
 	"inva/pkg/logging"
 	"inva/routes"
+	"inva/services"
 	"log"
 	"net/http"
 	"os"
@@ -53,6 +56,31 @@ func main() {
 		logger.Fatalf("Ошибка при проверке соединения с базой данных: %v", err)
 	}
 
+	// Шина событий фоновых задач
+	bus := events.NewBus()
+	bus.Subscribe(events.LogHandler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Проверка просроченных выдач
+	if appConfig.Overdue.CheckInterval > 0 {
+		checker := services.NewOverdueChecker(services.NewEquipmentService(db), bus, appConfig.Overdue.CheckInterval)
+		go checker.Run(ctx)
+	}
+
+	// Создание задач планового обслуживания по расписаниям
+	if appConfig.Maintenance.CheckInterval > 0 {
+		scheduler := services.NewMaintenanceScheduler(services.NewMaintenanceScheduleService(db), bus, appConfig.Maintenance.CheckInterval)
+		go scheduler.Run(ctx)
+	}
+
+	// Предупреждения об окончании гарантии
+	if appConfig.Warranty.CheckInterval > 0 {
+		warranty := services.NewWarrantyChecker(services.NewEquipmentService(db), bus, appConfig.Warranty.CheckInterval, appConfig.Warranty.Notice)
+		go warranty.Run(ctx)
+	}
+
 	// Настройка маршрутизации
 	r := mux.NewRouter()
 	routes.SetupRoutes(r, db)
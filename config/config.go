@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
@@ -34,11 +35,34 @@ type ServerConfig struct {
 	Port string `yaml:"port"`
 }
 
+// OverdueConfig структура для конфигурации проверки просроченных выдач
+type OverdueConfig struct {
+	// CheckInterval интервал проверки, например "1h"; 0 отключает проверку
+	CheckInterval time.Duration `yaml:"check_interval"`
+}
+
+// MaintenanceConfig структура для конфигурации планировщика обслуживания
+type MaintenanceConfig struct {
+	// CheckInterval интервал создания задач по расписаниям, например "1h"; 0 отключает планировщик
+	CheckInterval time.Duration `yaml:"check_interval"`
+}
+
+// WarrantyConfig структура для конфигурации проверки истекающих гарантий
+type WarrantyConfig struct {
+	// CheckInterval интервал проверки, например "24h"; 0 отключает проверку
+	CheckInterval time.Duration `yaml:"check_interval"`
+	// Notice за сколько до окончания гарантии предупреждать, например "720h"; по умолчанию 30 дней
+	Notice time.Duration `yaml:"notice"`
+}
+
 // AppConfig структура для общей конфигурации приложения
 type AppConfig struct {
-	Logging  LogConfig      `yaml:"logging"`
-	Database DatabaseConfig `yaml:"database"`
-	Server   ServerConfig   `yaml:"server"`
+	Logging     LogConfig         `yaml:"logging"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Server      ServerConfig      `yaml:"server"`
+	Overdue     OverdueConfig     `yaml:"overdue"`
+	Maintenance MaintenanceConfig `yaml:"maintenance"`
+	Warranty    WarrantyConfig    `yaml:"warranty"`
 }
 
 // LoadConfig загружает конфигурацию приложения из YAML файла
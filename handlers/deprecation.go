@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Deprecated возвращает middleware для устаревших маршрутов. Ответ дополняется заголовками
+// Deprecation (RFC 9745), Sunset (RFC 8594) и ссылкой Link на тот же ресурс под префиксом successorPrefix.
+func Deprecated(deprecatedAt, sunset time.Time, successorPrefix string) mux.MiddlewareFunc {
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			successor := successorPrefix + r.URL.Path
+			if r.URL.RawQuery != "" {
+				successor += "?" + r.URL.RawQuery
+			}
+
+			w.Header().Set("Deprecation", deprecation)
+			w.Header().Set("Sunset", sunsetDate)
+			w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+
+			logrus.WithFields(logrus.Fields{
+				"method":    r.Method,
+				"path":      r.URL.Path,
+				"successor": successor,
+			}).Debug("Запрос к устаревшему пути без версии API")
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"inva/pkg/validation"
+	"inva/services"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// DepreciationRuleRequest тело запроса на установку правила амортизации категории
+type DepreciationRuleRequest struct {
+	Method           string  `json:"method" validate:"required,oneof=straight_line declining_balance"`
+	UsefulLifeMonths int     `json:"useful_life_months" validate:"required,min=1,max=600"`
+	SalvagePercent   float64 `json:"salvage_percent"`
+}
+
+// DepreciationHandler представляет обработчик для амортизации и оценки оборудования
+type DepreciationHandler struct {
+	service *services.DepreciationService
+}
+
+// NewDepreciationHandler создаёт новый экземпляр DepreciationHandler
+func NewDepreciationHandler(service *services.DepreciationService) *DepreciationHandler {
+	return &DepreciationHandler{service: service}
+}
+
+// SetRuleHandler создаёт или заменяет правило амортизации категории
+func (h *DepreciationHandler) SetRuleHandler(w http.ResponseWriter, r *http.Request) {
+	var request DepreciationRuleRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на установку правила амортизации")
+		return
+	}
+
+	rule, err := h.service.SetRule(&services.DepreciationRule{
+		Category:         mux.Vars(r)["category"],
+		Method:           request.Method,
+		UsefulLifeMonths: request.UsefulLifeMonths,
+		SalvagePercent:   request.SalvagePercent,
+	})
+	if err != nil {
+		respondError(w, "Error saving depreciation rule", err)
+		logrus.WithError(err).Error("Ошибка при сохранении правила амортизации")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(rule); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+	logrus.WithFields(logrus.Fields{
+		"category": rule.Category,
+		"method":   rule.Method,
+	}).Info("Установлено правило амортизации")
+}
+
+// GetAllRulesHandler возвращает правила амортизации всех категорий
+func (h *DepreciationHandler) GetAllRulesHandler(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.GetAllRules()
+	if err != nil {
+		http.Error(w, "Error retrieving depreciation rules", http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при получении правил амортизации")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(rules); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
+
+// DeleteRuleHandler удаляет правило амортизации категории
+func (h *DepreciationHandler) DeleteRuleHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteRule(mux.Vars(r)["category"]); err != nil {
+		respondError(w, "Error deleting depreciation rule", err)
+		logrus.WithError(err).Error("Ошибка при удалении правила амортизации")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetValuationReportHandler возвращает балансовую стоимость оборудования на дату as_of (по умолчанию сегодня)
+// по единицам, отделам или категориям в зависимости от group_by
+func (h *DepreciationHandler) GetValuationReportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	now := time.Now()
+	asOf := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if value := query.Get("as_of"); value != "" {
+		var err error
+		if asOf, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(w, "Invalid as_of parameter, YYYY-MM-DD expected", http.StatusBadRequest)
+			return
+		}
+	}
+
+	groupBy := query.Get("group_by")
+	if groupBy != "" && groupBy != services.ValuationByItem && groupBy != services.ValuationByDepartment && groupBy != services.ValuationByCategory {
+		http.Error(w, "Invalid group_by parameter, item, department or category expected", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.GetValuationReport(asOf, groupBy)
+	if err != nil {
+		respondError(w, "Error building valuation report", err)
+		logrus.WithError(err).Error("Ошибка при расчёте оценки оборудования")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+	logrus.WithFields(logrus.Fields{
+		"as_of":    asOf.Format("2006-01-02"),
+		"group_by": report.GroupBy,
+		"unvalued": len(report.Unvalued),
+	}).Info("Отчёт об оценке оборудования успешно возвращен")
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"inva/pkg/certificate"
+	"inva/pkg/validation"
+	"inva/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// DisposalRequest тело запроса на утилизацию оборудования
+type DisposalRequest struct {
+	Reason      string `json:"reason" validate:"required,max=500"`
+	Method      string `json:"method" validate:"required,oneof=recycle sell destroy"`
+	Recipient   string `json:"recipient" validate:"max=255"`
+	RequestedBy int    `json:"requested_by" validate:"required,min=1"`
+}
+
+// DataWipeRequest тело запроса на подтверждение очистки данных
+type DataWipeRequest struct {
+	EmployeeID int        `json:"employee_id" validate:"required,min=1"`
+	Method     string     `json:"method" validate:"required,max=255"`
+	WipedAt    *time.Time `json:"wiped_at"`
+}
+
+// DisposalDecisionRequest тело запроса на одобрение или отклонение утилизации
+type DisposalDecisionRequest struct {
+	ApproverID int    `json:"approver_id" validate:"required,min=1"`
+	Note       string `json:"note" validate:"max=500"`
+}
+
+// DisposalHandler представляет обработчик для утилизации и списания оборудования
+type DisposalHandler struct {
+	service *services.DisposalService
+}
+
+// NewDisposalHandler создаёт новый экземпляр DisposalHandler
+func NewDisposalHandler(service *services.DisposalService) *DisposalHandler {
+	return &DisposalHandler{service: service}
+}
+
+// RequestDisposalHandler создаёт заявку на утилизацию оборудования
+func (h *DisposalHandler) RequestDisposalHandler(w http.ResponseWriter, r *http.Request) {
+	equipmentID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid equipment ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID оборудования")
+		return
+	}
+
+	var request DisposalRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на утилизацию")
+		return
+	}
+
+	disposal, err := h.service.RequestDisposal(equipmentID, services.DisposalRequest{
+		Reason:      request.Reason,
+		Method:      request.Method,
+		Recipient:   request.Recipient,
+		RequestedBy: request.RequestedBy,
+	})
+	if err != nil {
+		respondError(w, "Error requesting disposal", err)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+		}).Error("Ошибка при создании заявки на утилизацию")
+		return
+	}
+
+	writeDisposalJSON(w, http.StatusCreated, disposal)
+	logrus.WithFields(logrus.Fields{
+		"disposal_id":  disposal.ID,
+		"equipment_id": equipmentID,
+		"method":       disposal.Method,
+	}).Info("Создана заявка на утилизацию")
+}
+
+// GetDisposalsHandler возвращает заявки на утилизацию с фильтрами status и equipment_id
+func (h *DisposalHandler) GetDisposalsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := services.DisposalFilter{Status: query.Get("status")}
+	if value := query.Get("equipment_id"); value != "" {
+		equipmentID, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid equipment_id", http.StatusBadRequest)
+			return
+		}
+		filter.EquipmentID = equipmentID
+	}
+
+	disposals, err := h.service.GetDisposals(filter)
+	if err != nil {
+		http.Error(w, "Error retrieving disposals", http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при получении заявок на утилизацию")
+		return
+	}
+
+	writeDisposalJSON(w, http.StatusOK, disposals)
+}
+
+// GetDisposalHandler возвращает заявку на утилизацию по идентификатору
+func (h *DisposalHandler) GetDisposalHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := disposalID(w, r)
+	if !ok {
+		return
+	}
+
+	disposal, err := h.service.GetDisposal(id)
+	if err != nil {
+		respondError(w, "Error retrieving disposal", err)
+		logrus.WithError(err).Error("Ошибка при получении заявки на утилизацию")
+		return
+	}
+
+	writeDisposalJSON(w, http.StatusOK, disposal)
+}
+
+// ConfirmDataWipeHandler подтверждает очистку данных на утилизируемом оборудовании
+func (h *DisposalHandler) ConfirmDataWipeHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := disposalID(w, r)
+	if !ok {
+		return
+	}
+
+	var request DataWipeRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании подтверждения очистки данных")
+		return
+	}
+
+	disposal, err := h.service.ConfirmDataWipe(id, request.EmployeeID, request.Method, request.WipedAt)
+	if err != nil {
+		respondError(w, "Error confirming data wipe", err)
+		logrus.WithFields(logrus.Fields{
+			"error":       err,
+			"disposal_id": id,
+		}).Error("Ошибка при подтверждении очистки данных")
+		return
+	}
+
+	writeDisposalJSON(w, http.StatusOK, disposal)
+	logrus.WithFields(logrus.Fields{
+		"disposal_id": id,
+		"employee_id": request.EmployeeID,
+	}).Info("Очистка данных подтверждена")
+}
+
+// ApproveDisposalHandler одобряет заявку и переводит оборудование в статус disposed
+func (h *DisposalHandler) ApproveDisposalHandler(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, true)
+}
+
+// RejectDisposalHandler отклоняет заявку на утилизацию
+func (h *DisposalHandler) RejectDisposalHandler(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, false)
+}
+
+// decide принимает решение по заявке на утилизацию
+func (h *DisposalHandler) decide(w http.ResponseWriter, r *http.Request, approve bool) {
+	id, ok := disposalID(w, r)
+	if !ok {
+		return
+	}
+
+	var request DisposalDecisionRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании решения по утилизации")
+		return
+	}
+
+	var disposal *services.Disposal
+	var err error
+	if approve {
+		disposal, err = h.service.Approve(id, request.ApproverID, request.Note)
+	} else {
+		disposal, err = h.service.Reject(id, request.ApproverID, request.Note)
+	}
+	if err != nil {
+		respondError(w, "Error deciding disposal", err)
+		logrus.WithFields(logrus.Fields{
+			"error":       err,
+			"disposal_id": id,
+			"approve":     approve,
+		}).Error("Ошибка при принятии решения по утилизации")
+		return
+	}
+
+	writeDisposalJSON(w, http.StatusOK, disposal)
+	logrus.WithFields(logrus.Fields{
+		"disposal_id":  id,
+		"equipment_id": disposal.EquipmentID,
+		"status":       disposal.Status,
+		"approver_id":  request.ApproverID,
+	}).Info("Принято решение по утилизации")
+}
+
+// GetCertificateHandler возвращает акт об утилизации в формате PDF; акт доступен только после одобрения
+func (h *DisposalHandler) GetCertificateHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := disposalID(w, r)
+	if !ok {
+		return
+	}
+
+	disposal, err := h.service.GetCertificate(id)
+	if err != nil {
+		respondError(w, "Error retrieving disposal certificate", err)
+		logrus.WithError(err).Error("Ошибка при получении акта об утилизации")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := certificate.WriteDisposalPDF(&buf, certificateFor(disposal)); err != nil {
+		http.Error(w, "Error rendering disposal certificate: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithField("error", err).Error("Ошибка при формировании акта об утилизации")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="disposal-%s.pdf"`, disposal.CertificateNumber))
+	w.Write(buf.Bytes())
+	logrus.WithField("certificate", disposal.CertificateNumber).Info("Акт об утилизации успешно сформирован")
+}
+
+// certificateFor переносит данные завершённой утилизации в акт
+func certificateFor(d *services.Disposal) certificate.Disposal {
+	c := certificate.Disposal{
+		Number:         d.CertificateNumber,
+		AssetTag:       d.AssetTag,
+		Model:          d.Model,
+		SerialNumber:   d.SerialNumber,
+		Category:       d.Category,
+		Reason:         d.Reason,
+		Method:         d.Method,
+		Recipient:      d.Recipient,
+		DataWipeMethod: d.DataWipeMethod,
+		DataWipedBy:    d.DataWipedByName,
+		RequestedBy:    d.RequestedByName,
+		ApprovedBy:     d.ApprovedByName,
+	}
+	if d.DataWipedAt != nil {
+		c.DataWipedAt = *d.DataWipedAt
+	}
+	if d.DecidedAt != nil {
+		c.DisposedAt = *d.DecidedAt
+	}
+	return c
+}
+
+// disposalID читает идентификатор заявки на утилизацию из пути запроса
+func disposalID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid disposal ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID заявки на утилизацию")
+		return 0, false
+	}
+	return id, true
+}
+
+// writeDisposalJSON отправляет ответ в формате JSON
+func writeDisposalJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
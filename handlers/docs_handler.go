@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"inva/pkg/openapi"
+	"net/http"
+)
+
+// DocsHandler отдаёт описание API в формате OpenAPI и страницу документации
+type DocsHandler struct{}
+
+// NewDocsHandler создаёт новый экземпляр DocsHandler
+func NewDocsHandler() *DocsHandler {
+	return &DocsHandler{}
+}
+
+// OpenAPIHandler возвращает документ OpenAPI 3
+func (h *DocsHandler) OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openapi.Spec)
+}
+
+// DocsPageHandler возвращает страницу интерактивной документации
+func (h *DocsHandler) DocsPageHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(openapi.DocsPage)
+}
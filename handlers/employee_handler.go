@@ -2,15 +2,21 @@ package handlers
 
 import (
 	"encoding/json"
+	"inva/pkg/validation"
 	"inva/services"
 	"net/http"
 	"strconv"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
+// EmployeeRequest тело запроса на создание и обновление сотрудника
+type EmployeeRequest struct {
+	Name  string `json:"name" validate:"required,max=255"`
+	Email string `json:"email,omitempty" validate:"max=255"`
+}
+
 // EmployeeHandler представляет обработчик для операций с сотрудниками
 type EmployeeHandler struct {
 	service *services.EmployeeService
@@ -23,19 +29,19 @@ func NewEmployeeHandler(service *services.EmployeeService) *EmployeeHandler {
 
 // CreateEmployeeHandler обрабатывает HTTP запрос для создания нового сотрудника
 func (h *EmployeeHandler) CreateEmployeeHandler(w http.ResponseWriter, r *http.Request) {
-	var employee services.Employee
+	var employee EmployeeRequest
 
-	// Декодируем тело запроса в структуру Employee
-	if err := json.NewDecoder(r.Body).Decode(&employee); err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+	// Декодируем и проверяем тело запроса
+	if err := validation.DecodeJSON(w, r, &employee); err != nil {
+		respondError(w, "Invalid request payload", err)
 		logrus.WithError(err).Error("Ошибка при декодировании запроса")
 		return
 	}
 
 	// Создаем сотрудника через сервис
-	createdEmployee, err := h.service.CreateEmployee(&employee)
+	createdEmployee, err := h.service.CreateEmployee(&services.Employee{Name: employee.Name, Email: employee.Email})
 	if err != nil {
-		http.Error(w, "Error creating employee", http.StatusInternalServerError)
+		respondError(w, "Error creating employee", err)
 		logrus.WithError(err).Error("Ошибка при создании сотрудника")
 		return
 	}
@@ -68,7 +74,7 @@ func (h *EmployeeHandler) GetAllEmployeesHandler(w http.ResponseWriter, r *http.
 
 // UpdateEmployeeHandler обрабатывает HTTP запрос для обновления данных сотрудника
 func (h *EmployeeHandler) UpdateEmployeeHandler(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
+	idStr := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		http.Error(w, "Invalid employee ID", http.StatusBadRequest)
@@ -76,25 +82,76 @@ func (h *EmployeeHandler) UpdateEmployeeHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	var employee services.Employee
-	if err := json.NewDecoder(r.Body).Decode(&employee); err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var employee EmployeeRequest
+	if err := validation.DecodeJSON(w, r, &employee); err != nil {
+		respondError(w, "Invalid request payload", err)
 		logrus.WithError(err).Error("Ошибка при декодировании запроса")
 		return
 	}
 
-	// Обновляем сотрудника через сервис
-	err = h.service.UpdateEmployee(id, employee.Name)
+	// Обновляем сотрудника через сервис; при заданном If-Match — только если версия не изменилась
+	updated, err := h.service.UpdateEmployeeIfMatch(id, version, employee.Name, employee.Email)
 	if err != nil {
-		http.Error(w, "Error updating employee", http.StatusInternalServerError)
+		respondError(w, "Error updating employee", err)
 		logrus.WithError(err).Error("Ошибка при обновлении сотрудника")
 		return
 	}
 
-	// Возвращаем ответ с кодом 204 (No Content) после успешного обновления
+	// Возвращаем ответ с кодом 204 (No Content) и новой версией в ETag
+	w.Header().Set("ETag", etag(updated))
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// PatchEmployeeHandler обрабатывает HTTP запрос для частичного обновления сотрудника в формате JSON Merge Patch
+func (h *EmployeeHandler) PatchEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid employee ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID")
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	values, err := decodeMergePatch(w, r, "name", "email", "department", "position", "role")
+	if err != nil {
+		respondError(w, "Invalid merge patch", err)
+		logrus.WithError(err).Error("Ошибка при разборе частичного обновления сотрудника")
+		return
+	}
+
+	employee, err := h.service.PatchEmployee(id, version, services.EmployeePatch{
+		Name:       values["name"],
+		Email:      values["email"],
+		Department: values["department"],
+		Position:   values["position"],
+		Role:       values["role"],
+	})
+	if err != nil {
+		respondError(w, "Error updating employee", err)
+		logrus.WithError(err).Error("Ошибка при частичном обновлении сотрудника")
+		return
+	}
+
+	// Возвращаем ответ с кодом 200 (OK), обновлённой записью и новой версией в ETag
+	w.Header().Set("ETag", etag(employee.Version))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(employee); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
+
 // DeleteEmployeeHandler обрабатывает HTTP запрос для удаления сотрудника
 func (h *EmployeeHandler) DeleteEmployeeHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := mux.Vars(r)["id"]
@@ -105,8 +162,14 @@ func (h *EmployeeHandler) DeleteEmployeeHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	if err := h.service.DeleteEmployee(id); err != nil {
-		http.Error(w, "Error deleting employee", http.StatusInternalServerError)
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteEmployeeIfMatch(id, version); err != nil {
+		respondError(w, "Error deleting employee", err)
 		logrus.WithError(err).Error("Ошибка при удалении сотрудника")
 		return
 	}
@@ -126,7 +189,7 @@ func (h *EmployeeHandler) GetEmployeeHandler(w http.ResponseWriter, r *http.Requ
 
 	employee, err := h.service.GetEmployeeByID(id)
 	if err != nil {
-		http.Error(w, "Error retrieving employee", http.StatusInternalServerError)
+		http.Error(w, "Error retrieving employee", statusForError(err))
 		logrus.WithError(err).Error("Ошибка при получении сотрудника")
 		return
 	}
@@ -136,6 +199,11 @@ func (h *EmployeeHandler) GetEmployeeHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// Версия записи передаётся в ETag для последующих условных изменений
+	if notModified(w, r, employee.Version) {
+		return
+	}
+
 	// Возвращаем ответ с кодом 200 (OK) и данными о сотруднике
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(employee); err != nil {
@@ -2,14 +2,72 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"inva/pkg/validation"
 	"inva/services"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
+// AssignRequest необязательное тело запроса на выдачу оборудования
+type AssignRequest struct {
+	DueAt    *time.Time       `json:"due_at" validate:"future"`
+	Override *OverrideRequest `json:"override"`
+}
+
+// OverrideRequest разрешение администратора выдать оборудование вопреки политикам выдачи
+type OverrideRequest struct {
+	AdminID       int    `json:"admin_id" validate:"required,min=1"`
+	Justification string `json:"justification" validate:"required,max=500"`
+}
+
+// options преобразует тело запроса в параметры выдачи
+func (r AssignRequest) options() services.AssignOptions {
+	opts := services.AssignOptions{DueAt: r.DueAt}
+	if r.Override != nil {
+		opts.Override = &services.PolicyOverride{AdminID: r.Override.AdminID, Justification: r.Override.Justification}
+	}
+	return opts
+}
+
+// TransferRequest тело запроса на передачу оборудования другому сотруднику
+type TransferRequest struct {
+	ToUserID int        `json:"to_user_id" validate:"required,min=1"`
+	Reason   string     `json:"reason" validate:"max=500"`
+	DueAt    *time.Time `json:"due_at" validate:"future"`
+}
+
+// BulkRequest тело запроса пакетной обработки оборудования
+type BulkRequest struct {
+	Operations []services.BulkOperation `json:"operations" validate:"required,max=500"`
+}
+
+// BulkResponse ответ на запрос пакетной обработки с результатом по каждой операции
+type BulkResponse struct {
+	Applied bool                  `json:"applied"`
+	Error   string                `json:"error,omitempty"`
+	Errors  []services.FieldError `json:"errors,omitempty"`
+	Results []services.BulkResult `json:"results"`
+}
+
+// LocationRequest тело запроса на перемещение оборудования
+type LocationRequest struct {
+	Location string `json:"location" validate:"required,max=255"`
+}
+
+// EquipmentRequest тело запроса на создание и полное обновление оборудования
+type EquipmentRequest struct {
+	Model        string `json:"model" validate:"required,max=255"`
+	SerialNumber string `json:"serial_number" validate:"max=100"`
+	Status       string `json:"status" validate:"required,max=50"`
+}
+
 // EquipmentHandler представляет обработчик для работы с оборудованием
 type EquipmentHandler struct {
 	service *services.EquipmentService
@@ -20,6 +78,39 @@ func NewEquipmentHandler(service *services.EquipmentService) *EquipmentHandler {
 	return &EquipmentHandler{service: service}
 }
 
+// parseEquipmentFilter извлекает фильтр оборудования из параметров запроса
+func parseEquipmentFilter(r *http.Request) (services.EquipmentFilter, error) {
+	query := r.URL.Query()
+	filter := services.EquipmentFilter{
+		Model:  query.Get("model"),
+		Status: query.Get("status"),
+	}
+
+	if ids := query.Get("ids"); ids != "" {
+		for _, idStr := range strings.Split(ids, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(idStr))
+			if err != nil {
+				return filter, fmt.Errorf("некорректный идентификатор в ids: %s", idStr)
+			}
+			filter.IDs = append(filter.IDs, id)
+		}
+	}
+
+	if assignedTo := query.Get("assigned_to"); assignedTo != "" {
+		if assignedTo == "none" {
+			filter.Unassigned = true
+		} else {
+			userID, err := strconv.Atoi(assignedTo)
+			if err != nil {
+				return filter, fmt.Errorf("некорректный assigned_to: %s", assignedTo)
+			}
+			filter.AssignedTo = &userID
+		}
+	}
+
+	return filter, nil
+}
+
 // AssignEquipmentToUser закрепляет оборудование за пользователем
 func (h *EquipmentHandler) AssignEquipmentToUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -49,10 +140,22 @@ func (h *EquipmentHandler) AssignEquipmentToUser(w http.ResponseWriter, r *http.
 		return
 	}
 
+	// Тело запроса необязательно: без него оборудование выдаётся бессрочно
+	var request AssignRequest
+	if err := validation.DecodeOptionalJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+			"user_id":      userID,
+		}).Error("Ошибка при декодировании запроса на выдачу оборудования")
+		return
+	}
+
 	// Присваиваем оборудование пользователю
-	err = h.service.AssignEquipmentToUser(equipmentID, userID)
+	err = h.service.AssignEquipment(equipmentID, userID, request.options())
 	if err != nil {
-		http.Error(w, "Failed to assign equipment to user: "+err.Error(), http.StatusInternalServerError)
+		respondError(w, "Failed to assign equipment to user", err)
 		logrus.WithFields(logrus.Fields{
 			"error":        err,
 			"equipment_id": equipmentID,
@@ -66,9 +169,135 @@ func (h *EquipmentHandler) AssignEquipmentToUser(w http.ResponseWriter, r *http.
 	logrus.WithFields(logrus.Fields{
 		"equipment_id": equipmentID,
 		"user_id":      userID,
+		"due_at":       request.DueAt,
 	}).Info("Оборудование успешно назначено пользователю")
 }
 
+// TransferEquipmentHandler передаёт оборудование от текущего владельца другому сотруднику
+func (h *EquipmentHandler) TransferEquipmentHandler(w http.ResponseWriter, r *http.Request) {
+	equipmentIDStr := mux.Vars(r)["id"]
+	equipmentID, err := strconv.Atoi(equipmentIDStr)
+	if err != nil {
+		http.Error(w, "Invalid equipment ID", http.StatusBadRequest)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentIDStr,
+		}).Error("Ошибка при преобразовании ID оборудования")
+		return
+	}
+
+	var request TransferRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+		}).Error("Ошибка при декодировании запроса на передачу оборудования")
+		return
+	}
+
+	result, err := h.service.TransferEquipment(equipmentID, request.ToUserID, request.Reason, request.DueAt)
+	if err != nil {
+		respondError(w, "Failed to transfer equipment", err)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+			"to_user_id":   request.ToUserID,
+		}).Error("Ошибка при передаче оборудования")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+	logrus.WithFields(logrus.Fields{
+		"equipment_id": equipmentID,
+		"from_user_id": result.FromUserID,
+		"to_user_id":   result.ToUserID,
+		"reason":       result.Reason,
+	}).Info("Оборудование успешно передано другому сотруднику")
+}
+
+// BulkEquipmentHandler выполняет пакет операций над оборудованием в одной транзакции
+func (h *EquipmentHandler) BulkEquipmentHandler(w http.ResponseWriter, r *http.Request) {
+	var request BulkRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании пакетного запроса")
+		return
+	}
+
+	results, err := h.service.ExecuteBulk(request.Operations)
+	response := BulkResponse{Applied: err == nil, Results: results}
+	status := http.StatusOK
+	if err != nil {
+		response.Error = err.Error()
+		var validationErr *services.ValidationError
+		if errors.As(err, &validationErr) {
+			response.Errors = validationErr.Fields
+		}
+		// Ошибка отдельной операции означает, что пакет не может быть применён целиком
+		status = http.StatusUnprocessableEntity
+		if statusForError(err) == http.StatusInternalServerError {
+			status = http.StatusInternalServerError
+		}
+		logrus.WithFields(logrus.Fields{
+			"error":      err,
+			"operations": len(request.Operations),
+		}).Error("Пакетная операция над оборудованием отменена")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+	if response.Applied {
+		logrus.WithField("operations", len(request.Operations)).Info("Пакетная операция над оборудованием выполнена")
+	}
+}
+
+// MoveEquipmentHandler изменяет место размещения оборудования
+func (h *EquipmentHandler) MoveEquipmentHandler(w http.ResponseWriter, r *http.Request) {
+	equipmentIDStr := mux.Vars(r)["id"]
+	equipmentID, err := strconv.Atoi(equipmentIDStr)
+	if err != nil {
+		http.Error(w, "Invalid equipment ID", http.StatusBadRequest)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentIDStr,
+		}).Error("Ошибка при преобразовании ID оборудования")
+		return
+	}
+
+	var request LocationRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+		}).Error("Ошибка при декодировании запроса на перемещение оборудования")
+		return
+	}
+
+	if err := h.service.MoveEquipment(equipmentID, request.Location); err != nil {
+		respondError(w, "Error moving equipment", err)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+			"location":     request.Location,
+		}).Error("Ошибка при перемещении оборудования")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logrus.WithFields(logrus.Fields{
+		"equipment_id": equipmentID,
+		"location":     request.Location,
+	}).Info("Оборудование успешно перемещено")
+}
+
 // ReturnEquipmentHandler обрабатывает запрос на возврат оборудования
 func (h *EquipmentHandler) ReturnEquipmentHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -84,7 +313,7 @@ func (h *EquipmentHandler) ReturnEquipmentHandler(w http.ResponseWriter, r *http
 
 	// Возвращаем оборудование от пользователя
 	if err := h.service.ReturnEquipmentFromUser(equipmentID); err != nil {
-		http.Error(w, "Error returning equipment: "+err.Error(), http.StatusInternalServerError)
+		respondError(w, "Error returning equipment", err)
 		logrus.WithFields(logrus.Fields{
 			"error":        err,
 			"equipment_id": equipmentID,
@@ -96,7 +325,8 @@ func (h *EquipmentHandler) ReturnEquipmentHandler(w http.ResponseWriter, r *http
 	logrus.WithField("equipment_id", equipmentID).Info("Оборудование успешно возвращено")
 }
 
-// GetEquipmentDetailsHandler возвращает информацию о конкретном оборудовании
+// GetEquipmentDetailsHandler возвращает карточку оборудования с владельцем, местом размещения,
+// последними записями истории и ссылками на связанные действия
 func (h *EquipmentHandler) GetEquipmentDetailsHandler(w http.ResponseWriter, r *http.Request) {
 	equipmentIDStr := mux.Vars(r)["id"]
 
@@ -110,10 +340,10 @@ func (h *EquipmentHandler) GetEquipmentDetailsHandler(w http.ResponseWriter, r *
 		return
 	}
 
-	// Получаем детали оборудования
-	equipment, err := h.service.GetEquipmentDetails(equipmentID)
+	// Получаем карточку оборудования
+	details, err := h.service.GetEquipmentDetails(equipmentID)
 	if err != nil {
-		http.Error(w, "Error retrieving equipment details: "+err.Error(), http.StatusInternalServerError)
+		respondError(w, "Error retrieving equipment details", err)
 		logrus.WithFields(logrus.Fields{
 			"error":        err,
 			"equipment_id": equipmentID,
@@ -121,14 +351,18 @@ func (h *EquipmentHandler) GetEquipmentDetailsHandler(w http.ResponseWriter, r *
 		return
 	}
 
-	if equipment == nil {
+	if details == nil {
 		http.NotFound(w, r)
 		logrus.WithField("equipment_id", equipmentID).Warn("Оборудование не найдено")
 		return
 	}
 
-	// Отправляем данные в JSON формате
-	response, err := json.Marshal(equipment)
+	// Отправляем карточку со ссылками на действия в той же версии API, что и запрос
+	prefix := apiPrefix(r, "equipment")
+	response, err := json.Marshal(EquipmentDetailsResponse{
+		EquipmentDetails: details,
+		Links:            equipmentLinks(prefix, details),
+	})
 	if err != nil {
 		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
 		logrus.WithFields(logrus.Fields{
@@ -143,23 +377,101 @@ func (h *EquipmentHandler) GetEquipmentDetailsHandler(w http.ResponseWriter, r *
 	logrus.WithField("equipment_id", equipmentID).Info("Детали оборудования успешно возвращены")
 }
 
+// GetEquipmentHistoryHandler возвращает историю выдачи оборудования
+func (h *EquipmentHandler) GetEquipmentHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	equipmentIDStr := mux.Vars(r)["id"]
+
+	equipmentID, err := strconv.Atoi(equipmentIDStr)
+	if err != nil {
+		http.Error(w, "Invalid equipment ID", http.StatusBadRequest)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentIDStr,
+		}).Error("Ошибка при преобразовании ID оборудования")
+		return
+	}
+
+	history, err := h.service.GetEquipmentHistory(equipmentID)
+	if err != nil {
+		http.Error(w, "Error retrieving equipment history: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+		}).Error("Ошибка при получении истории оборудования")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(history); err != nil {
+		http.Error(w, "Error encoding response: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+		}).Error("Ошибка при кодировании ответа")
+		return
+	}
+	logrus.WithField("equipment_id", equipmentID).Info("История оборудования успешно возвращена")
+}
+
+// GetOverdueEquipmentHandler возвращает отчёт о просроченных выдачах оборудования
+func (h *EquipmentHandler) GetOverdueEquipmentHandler(w http.ResponseWriter, r *http.Request) {
+	items, err := h.service.GetOverdueEquipment(time.Now())
+	if err != nil {
+		http.Error(w, "Error retrieving overdue equipment: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithField("error", err).Error("Ошибка при получении просроченного оборудования")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(items); err != nil {
+		http.Error(w, "Error encoding response: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithField("error", err).Error("Ошибка при кодировании ответа")
+		return
+	}
+	logrus.WithField("count", len(items)).Info("Отчёт о просроченном оборудовании успешно возвращен")
+}
+
+// GetWarrantyExpiringHandler возвращает оборудование, гарантия которого истекает в ближайшие within (по умолчанию 30 дней)
+func (h *EquipmentHandler) GetWarrantyExpiringHandler(w http.ResponseWriter, r *http.Request) {
+	within := services.DefaultWarrantyNotice
+	if value := r.URL.Query().Get("within"); value != "" {
+		var err error
+		if within, err = parseWindow(value); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	items, err := h.service.GetWarrantyExpiring(time.Now(), within)
+	if err != nil {
+		http.Error(w, "Error retrieving warranty report: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithField("error", err).Error("Ошибка при получении оборудования с истекающей гарантией")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(items); err != nil {
+		http.Error(w, "Error encoding response: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithField("error", err).Error("Ошибка при кодировании ответа")
+		return
+	}
+	logrus.WithField("count", len(items)).Info("Отчёт об истекающих гарантиях успешно возвращен")
+}
+
 // CreateEquipmentHandler обрабатывает создание нового оборудования
 func (h *EquipmentHandler) CreateEquipmentHandler(w http.ResponseWriter, r *http.Request) {
-	var equipment services.Equipment
-	// Декодируем JSON данные
-	if err := json.NewDecoder(r.Body).Decode(&equipment); err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
-		logrus.WithFields(logrus.Fields{
-			"error": err,
-			"body":  r.Body,
-		}).Error("Ошибка при декодировании запроса на создание оборудования")
+	var equipment EquipmentRequest
+	// Декодируем и проверяем JSON данные
+	if err := validation.DecodeJSON(w, r, &equipment); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithField("error", err).Error("Ошибка при декодировании запроса на создание оборудования")
 		return
 	}
 
 	// Создаем новое оборудование
 	createdEquipment, err := h.service.CreateEquipment(equipment.Model, equipment.SerialNumber, equipment.Status)
 	if err != nil {
-		http.Error(w, "Error creating equipment: "+err.Error(), http.StatusInternalServerError)
+		respondError(w, "Error creating equipment", err)
 		logrus.WithFields(logrus.Fields{
 			"error":     err,
 			"equipment": equipment,
@@ -202,7 +514,7 @@ func (h *EquipmentHandler) GetEquipmentHandler(w http.ResponseWriter, r *http.Re
 	// Получаем оборудование по ID
 	equipment, err := h.service.GetEquipmentByID(id)
 	if err != nil {
-		http.Error(w, "Error retrieving equipment: "+err.Error(), http.StatusInternalServerError)
+		respondError(w, "Error retrieving equipment", err)
 		logrus.WithFields(logrus.Fields{
 			"error":        err,
 			"equipment_id": id,
@@ -215,7 +527,13 @@ func (h *EquipmentHandler) GetEquipmentHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Версия записи передаётся в ETag для последующих условных изменений
+	if notModified(w, r, equipment.Version) {
+		return
+	}
+
 	// Отправляем успешный ответ с кодом 200 OK
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(equipment); err != nil {
 		http.Error(w, "Error encoding response: "+err.Error(), http.StatusInternalServerError)
@@ -229,8 +547,15 @@ func (h *EquipmentHandler) GetEquipmentHandler(w http.ResponseWriter, r *http.Re
 
 // GetAllEquipmentHandler обрабатывает получение списка всего оборудования
 func (h *EquipmentHandler) GetAllEquipmentHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseEquipmentFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logrus.WithField("error", err).Error("Ошибка при разборе фильтра оборудования")
+		return
+	}
+
 	// Получаем список оборудования
-	equipmentList, err := h.service.GetAllEquipment()
+	equipmentList, err := h.service.FindEquipment(filter)
 	if err != nil {
 		http.Error(w, "Error retrieving equipment list: "+err.Error(), http.StatusInternalServerError)
 		logrus.WithField("error", err).Error("Ошибка при получении списка оборудования")
@@ -248,31 +573,112 @@ func (h *EquipmentHandler) GetAllEquipmentHandler(w http.ResponseWriter, r *http
 
 // UpdateEquipmentHandler обрабатывает обновление данных оборудования
 func (h *EquipmentHandler) UpdateEquipmentHandler(w http.ResponseWriter, r *http.Request) {
-	var equipment services.Equipment
-	// Декодируем JSON данные
-	if err := json.NewDecoder(r.Body).Decode(&equipment); err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid equipment ID", http.StatusBadRequest)
 		logrus.WithFields(logrus.Fields{
-			"error": err,
-			"body":  r.Body,
-		}).Error("Ошибка при декодировании запроса на обновление оборудования")
+			"error":        err,
+			"equipment_id": idStr,
+		}).Error("Ошибка при преобразовании ID оборудования")
 		return
 	}
 
-	// Обновляем оборудование
-	err := h.service.UpdateEquipment(equipment.ID, equipment.Model, equipment.SerialNumber, equipment.Status)
+	version, err := ifMatchVersion(r)
 	if err != nil {
-		http.Error(w, "Error updating equipment: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var equipment EquipmentRequest
+	// Декодируем и проверяем JSON данные
+	if err := validation.DecodeJSON(w, r, &equipment); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithField("error", err).Error("Ошибка при декодировании запроса на обновление оборудования")
+		return
+	}
+
+	// Обновляем оборудование; при заданном If-Match — только если версия не изменилась
+	updated, err := h.service.UpdateEquipmentIfMatch(id, version, equipment.Model, equipment.SerialNumber, equipment.Status)
+	if err != nil {
+		respondError(w, "Error updating equipment", err)
 		logrus.WithFields(logrus.Fields{
 			"error":     err,
 			"equipment": equipment,
+			"if_match":  version,
 		}).Error("Ошибка при обновлении оборудования")
 		return
 	}
 
-	// Возвращаем успешный статус без контента
+	// Возвращаем успешный статус без контента и новую версию в ETag
+	w.Header().Set("ETag", etag(updated))
 	w.WriteHeader(http.StatusNoContent)
-	logrus.WithField("equipment_id", equipment.ID).Info("Оборудование успешно обновлено")
+	logrus.WithField("equipment_id", id).Info("Оборудование успешно обновлено")
+}
+
+// PatchEquipmentHandler обрабатывает частичное обновление оборудования в формате JSON Merge Patch
+func (h *EquipmentHandler) PatchEquipmentHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid equipment ID", http.StatusBadRequest)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": idStr,
+		}).Error("Ошибка при преобразовании ID оборудования")
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	values, err := decodeMergePatch(w, r, "model", "serial_number", "status", "location", "category", "purchase_cost",
+		"purchase_date", "warranty_expires_at", "support_contract", "currency", "depreciation_method", "useful_life_months")
+	if err != nil {
+		respondError(w, "Invalid merge patch", err)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": id,
+		}).Error("Ошибка при разборе частичного обновления оборудования")
+		return
+	}
+
+	// Незатронутые поля остаются без изменений; при заданном If-Match — только если версия не изменилась
+	equipment, err := h.service.PatchEquipment(id, version, services.EquipmentPatch{
+		Model:              values["model"],
+		SerialNumber:       values["serial_number"],
+		Status:             values["status"],
+		Location:           values["location"],
+		Category:           values["category"],
+		PurchaseCost:       values["purchase_cost"],
+		PurchaseDate:       values["purchase_date"],
+		WarrantyExpiresAt:  values["warranty_expires_at"],
+		SupportContract:    values["support_contract"],
+		Currency:           values["currency"],
+		DepreciationMethod: values["depreciation_method"],
+		UsefulLifeMonths:   values["useful_life_months"],
+	})
+	if err != nil {
+		respondError(w, "Error updating equipment", err)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": id,
+			"if_match":     version,
+		}).Error("Ошибка при частичном обновлении оборудования")
+		return
+	}
+
+	// Возвращаем обновлённую запись с новой версией в ETag
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(equipment.Version))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(equipment); err != nil {
+		logrus.WithField("error", err).Error("Ошибка при кодировании ответа")
+	}
+	logrus.WithField("equipment_id", id).Info("Оборудование успешно обновлено частично")
 }
 
 // DeleteEquipmentHandler обрабатывает удаление оборудования по ID
@@ -295,10 +701,16 @@ func (h *EquipmentHandler) DeleteEquipmentHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	// Удаляем оборудование
-	err = h.service.DeleteEquipment(id)
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Удаляем оборудование; при заданном If-Match — только если версия не изменилась
+	err = h.service.DeleteEquipmentIfMatch(id, version)
 	if err != nil {
-		http.Error(w, "Error deleting equipment: "+err.Error(), http.StatusInternalServerError)
+		respondError(w, "Error deleting equipment", err)
 		logrus.WithFields(logrus.Fields{
 			"error":        err,
 			"equipment_id": id,
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"inva/services"
+	"inva/utils"
+	"net/http"
+)
+
+// statusForError подбирает HTTP-статус ответа по ошибке сервиса
+func statusForError(err error) int {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr), errors.Is(err, services.ErrIdempotencyKeyReused):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrEquipmentNotFound), errors.Is(err, services.ErrEmployeeNotFound),
+		errors.Is(err, services.ErrReservationNotFound), errors.Is(err, services.ErrReturnTaskNotFound),
+		errors.Is(err, services.ErrKitNotFound), errors.Is(err, services.ErrPolicyNotFound),
+		errors.Is(err, services.ErrRequestNotFound), errors.Is(err, services.ErrMaintenanceNotFound),
+		errors.Is(err, services.ErrScheduleNotFound), errors.Is(err, services.ErrMaintenanceTaskNotFound),
+		errors.Is(err, services.ErrDepreciationRuleNotFound), errors.Is(err, services.ErrVendorNotFound),
+		errors.Is(err, services.ErrPurchaseOrderNotFound), errors.Is(err, services.ErrPurchaseOrderLineNotFound),
+		errors.Is(err, services.ErrDisposalNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrEquipmentAssigned), errors.Is(err, services.ErrEquipmentNotAssigned),
+		errors.Is(err, services.ErrReservationConflict), errors.Is(err, services.ErrIdempotencyInProgress),
+		errors.Is(err, services.ErrEmployeeInactive), errors.Is(err, services.ErrReturnTaskClosed),
+		errors.Is(err, services.ErrOffboardingIncomplete), errors.Is(err, services.ErrKitShortage),
+		errors.Is(err, services.ErrRequestClosed), errors.Is(err, services.ErrRequestNotApproved),
+		errors.Is(err, services.ErrMaintenanceOpen), errors.Is(err, services.ErrMaintenanceClosed),
+		errors.Is(err, services.ErrMaintenanceTaskDone), errors.Is(err, services.ErrPurchaseOrderLineReceived),
+		errors.Is(err, services.ErrDisposalPending), errors.Is(err, services.ErrDisposalClosed),
+		errors.Is(err, services.ErrDataWipeRequired), errors.Is(err, services.ErrDisposalNotCompleted),
+		errors.Is(err, services.ErrEquipmentDisposed), errors.Is(err, services.ErrEquipmentReferenced),
+		errors.Is(err, services.ErrEquipmentWrittenOff):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrPolicyViolation), errors.Is(err, services.ErrPolicyOverrideDenied),
+		errors.Is(err, services.ErrApprovalDenied):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidReservation):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, errUnsupportedPatchType):
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusInternalServerError
+}
+
+// respondError отправляет ответ с ошибкой: ошибки проверки возвращаются со статусом 422
+// и списком полей {field, code, message} в JSON, нехватка оборудования для комплекта — со статусом 409
+// и списком недостающих позиций, нарушения политик выдачи — со статусом 403 и списком нарушений,
+// остальные — текстом message с описанием ошибки
+func respondError(w http.ResponseWriter, message string, err error) {
+	var validationErr *services.ValidationError
+	if errors.As(err, &validationErr) {
+		utils.RespondWithJSON(w, http.StatusUnprocessableEntity, validationErr)
+		return
+	}
+	var shortageErr *services.KitShortageError
+	if errors.As(err, &shortageErr) {
+		utils.RespondWithJSON(w, http.StatusConflict, shortageErr)
+		return
+	}
+	var policyErr *services.PolicyViolationError
+	if errors.As(err, &policyErr) {
+		utils.RespondWithJSON(w, http.StatusForbidden, policyErr)
+		return
+	}
+	http.Error(w, message+": "+err.Error(), statusForError(err))
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etag формирует значение заголовка ETag по версии записи
+func etag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ifMatchVersion извлекает ожидаемую версию из заголовка If-Match.
+// Возвращает 0, если заголовок отсутствует или равен "*", то есть версия не проверяется.
+func ifMatchVersion(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	// Слабые валидаторы не подходят для условного изменения (RFC 9110, раздел 13.1.1)
+	if strings.HasPrefix(value, "W/") || strings.Contains(value, ",") {
+		return 0, fmt.Errorf("If-Match must contain a single strong ETag")
+	}
+
+	version, err := strconv.Atoi(strings.Trim(value, `"`))
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("invalid ETag in If-Match: %s", value)
+	}
+	return version, nil
+}
+
+// notModified проверяет If-None-Match и отвечает 304, если у клиента актуальная версия
+func notModified(w http.ResponseWriter, r *http.Request, version int) bool {
+	tag := etag(version)
+	w.Header().Set("ETag", tag)
+
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == tag || candidate == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"fmt"
+	"inva/pkg/export"
+	"inva/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ExportHandler представляет обработчик выгрузки данных в CSV, XLSX и JSON Lines
+type ExportHandler struct {
+	service *services.ExportService
+}
+
+// NewExportHandler создаёт новый экземпляр ExportHandler
+func NewExportHandler(service *services.ExportService) *ExportHandler {
+	return &ExportHandler{service: service}
+}
+
+// ExportEquipmentHandler выгружает оборудование с теми же фильтрами, что и список оборудования
+func (h *ExportHandler) ExportEquipmentHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseEquipmentFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logrus.WithField("error", err).Error("Ошибка при разборе фильтра оборудования")
+		return
+	}
+
+	h.export(w, r, "equipment", func(writer export.Writer) error {
+		return h.service.ExportEquipment(filter, writer)
+	})
+}
+
+// ExportEmployeesHandler выгружает сотрудников
+func (h *ExportHandler) ExportEmployeesHandler(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, "employees", h.service.ExportEmployees)
+}
+
+// ExportHistoryHandler выгружает историю выдачи с фильтрами equipment_id, user_id, from и to
+func (h *ExportHandler) ExportHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var filter services.HistoryFilter
+	var err error
+
+	if value := query.Get("equipment_id"); value != "" {
+		if filter.EquipmentID, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invalid equipment_id", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("user_id"); value != "" {
+		if filter.UserID, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invalid user_id", http.StatusBadRequest)
+			return
+		}
+	}
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, "Invalid "+name+" parameter, RFC 3339 expected", http.StatusBadRequest)
+				return
+			}
+			*target = &t
+		}
+	}
+
+	h.export(w, r, "history", func(writer export.Writer) error {
+		return h.service.ExportHistory(filter, writer)
+	})
+}
+
+// export выбирает формат по параметру format и передаёт строки выгрузки клиенту
+func (h *ExportHandler) export(w http.ResponseWriter, r *http.Request, name string, run func(export.Writer) error) {
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Заголовки файла отправляются вместе с первыми данными, чтобы ошибку запроса можно было вернуть статусом 500
+	out := &exportResponse{ResponseWriter: w, start: func() {
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	}}
+	writer, err := export.NewWriter(out, format)
+	if err != nil {
+		http.Error(w, "Error preparing export: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при подготовке выгрузки")
+		return
+	}
+
+	if err := run(writer); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error":   err,
+			"export":  name,
+			"format":  format,
+			"started": out.started,
+		}).Error("Ошибка при выгрузке данных")
+		if !out.started {
+			http.Error(w, "Error exporting data: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Часть файла уже передана: обрываем соединение, чтобы клиент не сохранил неполную выгрузку как успешную
+		panic(http.ErrAbortHandler)
+	}
+	if !out.started {
+		// Пустая выгрузка без строк всё равно отдаётся файлом
+		out.start()
+	}
+	logrus.WithFields(logrus.Fields{
+		"export": name,
+		"format": format,
+	}).Info("Выгрузка данных успешно завершена")
+}
+
+// exportResponse откладывает отправку заголовков файла до первой записи данных выгрузки
+type exportResponse struct {
+	http.ResponseWriter
+	start   func()
+	started bool
+}
+
+func (e *exportResponse) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		e.start()
+	}
+	return e.ResponseWriter.Write(p)
+}
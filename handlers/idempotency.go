@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"inva/pkg/validation"
+	"inva/services"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// maxIdempotencyKeyLength максимальная длина заголовка Idempotency-Key
+const maxIdempotencyKeyLength = 255
+
+// replayedHeaders заголовки ответа, которые сохраняются и повторяются вместе с телом
+var replayedHeaders = []string{"Content-Type", "Content-Disposition", "ETag", "Location"}
+
+// Idempotency возвращает middleware, которое для POST, PUT и PATCH запросов с заголовком Idempotency-Key
+// выполняет запрос один раз и на повторы с тем же ключом возвращает сохранённый ответ. Путь с префиксом
+// versionPrefix и тот же путь без версии считаются одним запросом.
+func Idempotency(service *services.IdempotencyService, versionPrefix string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodPatch) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				http.Error(w, "Idempotency-Key is too long", http.StatusBadRequest)
+				return
+			}
+
+			route := strings.TrimPrefix(r.URL.Path, versionPrefix)
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, bodyLimit(route)))
+			if err != nil {
+				http.Error(w, "Error reading request body: "+err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			stored, err := service.Begin(key, requestHash(r.Method, route, r.URL.RawQuery, body))
+			if err != nil {
+				http.Error(w, err.Error(), statusForError(err))
+				logrus.WithFields(logrus.Fields{
+					"error":           err,
+					"idempotency_key": key,
+				}).Warn("Ошибка при проверке ключа идемпотентности")
+				return
+			}
+
+			// Повтор уже выполненного запроса получает исходный ответ
+			if stored != nil {
+				for name, value := range stored.Headers {
+					w.Header().Set(name, value)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(stored.StatusCode)
+				w.Write(stored.Body)
+				logrus.WithField("idempotency_key", key).Info("Возвращён сохранённый ответ на повторный запрос")
+				return
+			}
+
+			// Если обработчик завершился паникой, ключ освобождается, иначе повторы получали бы 409 до истечения срока
+			defer func() {
+				if p := recover(); p != nil {
+					releaseKey(service, key)
+					panic(p)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			// Ответ с ошибкой сервера не сохраняется, чтобы клиент мог повторить запрос
+			if recorder.status >= http.StatusInternalServerError {
+				releaseKey(service, key)
+				return
+			}
+
+			response := &services.IdempotentResponse{
+				StatusCode: recorder.status,
+				Headers:    make(map[string]string),
+				Body:       recorder.body.Bytes(),
+			}
+			for _, name := range replayedHeaders {
+				if value := w.Header().Get(name); value != "" {
+					response.Headers[name] = value
+				}
+			}
+			if err := service.Complete(key, response); err != nil {
+				logrus.WithField("error", err).Error("Ошибка при сохранении ответа для ключа идемпотентности")
+			}
+		})
+	}
+}
+
+// releaseKey освобождает ключ невыполненного запроса
+func releaseKey(service *services.IdempotencyService, key string) {
+	if err := service.Release(key); err != nil {
+		logrus.WithField("error", err).Error("Ошибка при освобождении ключа идемпотентности")
+	}
+}
+
+// bodyLimit возвращает допустимый размер тела запроса: CSV импортируется файлами до 10 МБ,
+// остальные маршруты принимают JSON не больше validation.MaxBodyBytes
+func bodyLimit(route string) int64 {
+	if strings.HasPrefix(route, "/import/") {
+		return maxImportSize
+	}
+	return validation.MaxBodyBytes
+}
+
+// requestHash вычисляет хеш метода, пути без версии, параметров и тела запроса для проверки повторного использования ключа
+func requestHash(method, route, rawQuery string, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, method+" "+route+"?"+rawQuery+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder передаёт ответ клиенту и одновременно запоминает статус и тело
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+// WriteHeader запоминает статус ответа
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write запоминает тело ответа
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"inva/services"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxImportSize максимальный размер загружаемого CSV
+const maxImportSize = 10 << 20
+
+// ImportHandler представляет обработчик массовой загрузки из CSV
+type ImportHandler struct {
+	service *services.ImportService
+}
+
+// NewImportHandler создаёт новый экземпляр ImportHandler
+func NewImportHandler(service *services.ImportService) *ImportHandler {
+	return &ImportHandler{service: service}
+}
+
+// importFunc сигнатура метода загрузки сервиса
+type importFunc func(r io.Reader, mapping map[string]string, dryRun bool) (*services.ImportResult, error)
+
+// ImportEquipmentHandler загружает оборудование из CSV
+func (h *ImportHandler) ImportEquipmentHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleImport(w, r, "equipment", h.service.ImportEquipment)
+}
+
+// ImportEmployeesHandler загружает сотрудников из CSV
+func (h *ImportHandler) ImportEmployeesHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleImport(w, r, "employees", h.service.ImportEmployees)
+}
+
+// handleImport разбирает параметры загрузки, вызывает сервис и возвращает построчный отчёт
+func (h *ImportHandler) handleImport(w http.ResponseWriter, r *http.Request, entity string, importer importFunc) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	mapping, err := parseImportMapping(r.URL.Query().Get("map"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	// CSV принимается как тело запроса или как файл формы multipart в поле file
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "CSV file is expected in the file field", http.StatusBadRequest)
+			logrus.WithError(err).Error("Ошибка при чтении файла загрузки")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	result, err := importer(body, mapping, dryRun)
+	if err != nil {
+		respondError(w, "Error importing "+entity, err)
+		logrus.WithFields(logrus.Fields{
+			"error":  err,
+			"entity": entity,
+		}).Error("Ошибка при загрузке CSV")
+		return
+	}
+
+	status := http.StatusOK
+	switch {
+	case len(result.Errors) > 0:
+		status = http.StatusUnprocessableEntity
+	case !result.DryRun:
+		status = http.StatusCreated
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+	logrus.WithFields(logrus.Fields{
+		"entity":   entity,
+		"dry_run":  result.DryRun,
+		"total":    result.Total,
+		"imported": result.Imported,
+		"errors":   len(result.Errors),
+	}).Info("Загрузка CSV завершена")
+}
+
+// parseImportMapping разбирает сопоставление колонок вида "Колонка:поле,Другая:поле"
+func parseImportMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	if value == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		column, field, ok := strings.Cut(pair, ":")
+		if !ok || strings.TrimSpace(column) == "" {
+			return nil, fmt.Errorf("invalid column mapping %q, expected Column:field", pair)
+		}
+		mapping[strings.TrimSpace(column)] = strings.TrimSpace(field)
+	}
+	return mapping, nil
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"inva/pkg/validation"
+	"inva/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// KitItemRequest позиция шаблона комплекта в теле запроса
+type KitItemRequest struct {
+	Category string `json:"category" validate:"max=100"`
+	Model    string `json:"model" validate:"max=255"`
+	Quantity int    `json:"quantity" validate:"required,min=1,max=50"`
+}
+
+// KitRequest тело запроса на создание шаблона комплекта
+type KitRequest struct {
+	Name  string           `json:"name" validate:"required,max=100"`
+	Items []KitItemRequest `json:"items" validate:"required,max=50"`
+}
+
+// KitHandler представляет обработчик для шаблонов комплектов и их выдачи
+type KitHandler struct {
+	service *services.KitService
+}
+
+// NewKitHandler создаёт новый экземпляр KitHandler
+func NewKitHandler(service *services.KitService) *KitHandler {
+	return &KitHandler{service: service}
+}
+
+// CreateKitHandler создаёт шаблон комплекта
+func (h *KitHandler) CreateKitHandler(w http.ResponseWriter, r *http.Request) {
+	var request KitRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на создание комплекта")
+		return
+	}
+
+	kit := &services.Kit{Name: request.Name, Items: make([]services.KitItem, len(request.Items))}
+	for i, item := range request.Items {
+		kit.Items[i] = services.KitItem{Category: item.Category, Model: item.Model, Quantity: item.Quantity}
+	}
+
+	createdKit, err := h.service.CreateKit(kit)
+	if err != nil {
+		respondError(w, "Error creating kit", err)
+		logrus.WithError(err).Error("Ошибка при создании комплекта")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(createdKit); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
+
+// GetAllKitsHandler возвращает все шаблоны комплектов
+func (h *KitHandler) GetAllKitsHandler(w http.ResponseWriter, r *http.Request) {
+	kits, err := h.service.GetAllKits()
+	if err != nil {
+		http.Error(w, "Error retrieving kits", http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при получении списка комплектов")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(kits); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
+
+// GetKitHandler возвращает шаблон комплекта по идентификатору или названию
+func (h *KitHandler) GetKitHandler(w http.ResponseWriter, r *http.Request) {
+	kit, err := h.service.GetKit(mux.Vars(r)["kit"])
+	if err != nil {
+		http.Error(w, "Error retrieving kit", statusForError(err))
+		logrus.WithError(err).Error("Ошибка при получении комплекта")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(kit); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
+
+// DeleteKitHandler удаляет шаблон комплекта
+func (h *KitHandler) DeleteKitHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteKit(mux.Vars(r)["kit"]); err != nil {
+		respondError(w, "Error deleting kit", err)
+		logrus.WithError(err).Error("Ошибка при удалении комплекта")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AssignKitHandler выдаёт сотруднику всё оборудование комплекта одной операцией
+func (h *KitHandler) AssignKitHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	employeeID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid employee ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID сотрудника")
+		return
+	}
+
+	// Тело запроса необязательно: без него оборудование выдаётся бессрочно
+	var request AssignRequest
+	if err := validation.DecodeOptionalJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на выдачу комплекта")
+		return
+	}
+
+	assignment, err := h.service.AssignKit(employeeID, vars["kit"], request.options())
+	if err != nil {
+		respondError(w, "Failed to assign kit", err)
+		logrus.WithFields(logrus.Fields{
+			"error":       err,
+			"employee_id": employeeID,
+			"kit":         vars["kit"],
+		}).Error("Ошибка при выдаче комплекта")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(assignment); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+	logrus.WithFields(logrus.Fields{
+		"employee_id": employeeID,
+		"kit":         assignment.Kit,
+		"items":       len(assignment.Items),
+	}).Info("Комплект выдан сотруднику")
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"inva/pkg/labels"
+	"inva/pkg/validation"
+	"inva/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// MaxLabelSheetItems максимальное число наклеек на одном листе; больший отбор нужно сузить фильтром
+const MaxLabelSheetItems = 500
+
+// LabelHandler представляет обработчик для печати инвентарных наклеек
+type LabelHandler struct {
+	service *services.EquipmentService
+}
+
+// NewLabelHandler создаёт новый экземпляр LabelHandler
+func NewLabelHandler(service *services.EquipmentService) *LabelHandler {
+	return &LabelHandler{service: service}
+}
+
+// GetEquipmentLabelHandler возвращает QR-код или штрихкод Code128 с инвентарным номером оборудования
+func (h *LabelHandler) GetEquipmentLabelHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid equipment ID", http.StatusBadRequest)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": idStr,
+		}).Error("Ошибка при преобразовании ID оборудования")
+		return
+	}
+
+	query := r.URL.Query()
+	symbology, err := labels.ParseSymbology(query.Get("type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := query.Get("format")
+	if format == "" {
+		format = "png"
+	}
+	if format != "png" && format != "svg" {
+		http.Error(w, "Unsupported label format: "+format, http.StatusBadRequest)
+		return
+	}
+
+	scale := 8
+	if scaleStr := query.Get("scale"); scaleStr != "" {
+		scale, err = strconv.Atoi(scaleStr)
+		if err != nil || scale < 1 || scale > 32 {
+			http.Error(w, "Invalid scale", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Проверяем, что оборудование существует
+	equipment, err := h.service.GetEquipmentByID(id)
+	if err != nil {
+		http.Error(w, "Error retrieving equipment: "+err.Error(), http.StatusNotFound)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": id,
+		}).Error("Ошибка при получении оборудования для наклейки")
+		return
+	}
+
+	code, err := labels.Encode(services.AssetTag(equipment.ID), symbology)
+	if err != nil {
+		http.Error(w, "Error encoding label: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": id,
+		}).Error("Ошибка при кодировании наклейки")
+		return
+	}
+
+	var buf bytes.Buffer
+	if format == "svg" {
+		err = labels.WriteSVG(&buf, code, scale)
+		w.Header().Set("Content-Type", "image/svg+xml")
+	} else {
+		err = labels.WritePNG(&buf, code, scale)
+		w.Header().Set("Content-Type", "image/png")
+	}
+	if err != nil {
+		http.Error(w, "Error rendering label: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": id,
+		}).Error("Ошибка при отрисовке наклейки")
+		return
+	}
+
+	w.Write(buf.Bytes())
+	logrus.WithField("equipment_id", id).Info("Наклейка оборудования успешно сформирована")
+}
+
+// GetEquipmentLabelSheetHandler возвращает PDF-лист наклеек для оборудования, отобранного фильтром;
+// если отобрано больше MaxLabelSheetItems единиц, возвращается 422
+func (h *LabelHandler) GetEquipmentLabelSheetHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseEquipmentFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logrus.WithField("error", err).Error("Ошибка при разборе фильтра оборудования")
+		return
+	}
+
+	// Одна лишняя строка показывает, что фильтр отбирает больше допустимого
+	filter.Limit = MaxLabelSheetItems + 1
+	equipmentList, err := h.service.FindEquipment(filter)
+	if err != nil {
+		http.Error(w, "Error retrieving equipment list: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithField("error", err).Error("Ошибка при получении списка оборудования для наклеек")
+		return
+	}
+	if len(equipmentList) > MaxLabelSheetItems {
+		respondError(w, "Too many labels", validation.New("filter", validation.CodeOutOfRange,
+			"фильтр отбирает больше %d единиц оборудования, уточните его", MaxLabelSheetItems))
+		logrus.WithField("limit", MaxLabelSheetItems).Warn("Лист наклеек не сформирован: отобрано слишком много оборудования")
+		return
+	}
+
+	sheet := make([]labels.Label, 0, len(equipmentList))
+	for _, equipment := range equipmentList {
+		sheet = append(sheet, labels.Label{
+			Tag:      services.AssetTag(equipment.ID),
+			Title:    equipment.Model,
+			Subtitle: equipment.SerialNumber,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := labels.WriteSheetPDF(&buf, sheet); err != nil {
+		http.Error(w, "Error rendering label sheet: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithField("error", err).Error("Ошибка при формировании листа наклеек")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="labels.pdf"`)
+	w.Write(buf.Bytes())
+	logrus.WithField("count", len(sheet)).Info("Лист наклеек успешно сформирован")
+}
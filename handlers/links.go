@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"fmt"
+	"inva/services"
+	"net/http"
+	"strings"
+)
+
+// Link ссылка на связанный ресурс или доступное действие
+type Link struct {
+	Href      string `json:"href"`
+	Method    string `json:"method,omitempty"`
+	Templated bool   `json:"templated,omitempty"`
+}
+
+// EquipmentDetailsResponse карточка оборудования со ссылками на связанные ресурсы и действия
+type EquipmentDetailsResponse struct {
+	*services.EquipmentDetails
+	Links map[string]Link `json:"_links"`
+}
+
+// apiPrefix возвращает часть пути запроса перед сегментом коллекции, например /api/v1 для
+// /api/v1/equipment/7, чтобы ссылки вели в ту же версию API, что и запрос
+func apiPrefix(r *http.Request, collection string) string {
+	if i := strings.Index(r.URL.Path, "/"+collection+"/"); i >= 0 {
+		return r.URL.Path[:i]
+	}
+	return ""
+}
+
+// equipmentLinks формирует ссылки карточки оборудования; набор действий зависит от того, выдано ли оборудование
+func equipmentLinks(prefix string, details *services.EquipmentDetails) map[string]Link {
+	base := fmt.Sprintf("%s/equipment/%d", prefix, details.ID)
+	links := map[string]Link{
+		"self":         {Href: base + "/details"},
+		"equipment":    {Href: base},
+		"history":      {Href: base + "/history"},
+		"label":        {Href: base + "/label"},
+		"reservations": {Href: base + "/reservations"},
+		"move":         {Href: base + "/location", Method: http.MethodPut},
+	}
+
+	if details.AssignedTo == nil {
+		links["assign"] = Link{Href: base + "/assign/user/{user_id}", Method: http.MethodPost, Templated: true}
+	} else {
+		links["assignee"] = Link{Href: fmt.Sprintf("%s/employees/%d", prefix, *details.AssignedTo)}
+		links["return"] = Link{Href: base + "/return", Method: http.MethodPut}
+		links["transfer"] = Link{Href: base + "/transfer", Method: http.MethodPost}
+	}
+	return links
+}
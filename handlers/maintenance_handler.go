@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"inva/pkg/validation"
+	"inva/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// OpenMaintenanceRequest тело запроса на передачу оборудования в ремонт
+type OpenMaintenanceRequest struct {
+	Issue    string     `json:"issue" validate:"required,max=1000"`
+	Vendor   string     `json:"vendor" validate:"max=255"`
+	Cost     *float64   `json:"cost"`
+	OpenedAt *time.Time `json:"opened_at"`
+}
+
+// CloseMaintenanceRequest тело запроса на завершение ремонта
+type CloseMaintenanceRequest struct {
+	Outcome  string     `json:"outcome" validate:"required,oneof=repaired not_repaired"`
+	Cost     *float64   `json:"cost"`
+	Note     string     `json:"note" validate:"max=500"`
+	ClosedAt *time.Time `json:"closed_at"`
+}
+
+// MaintenanceHandler представляет обработчик для учёта ремонтов оборудования
+type MaintenanceHandler struct {
+	service *services.MaintenanceService
+}
+
+// NewMaintenanceHandler создаёт новый экземпляр MaintenanceHandler
+func NewMaintenanceHandler(service *services.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{service: service}
+}
+
+// OpenMaintenanceHandler передаёт оборудование в ремонт
+func (h *MaintenanceHandler) OpenMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	equipmentID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid equipment ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID оборудования")
+		return
+	}
+
+	var request OpenMaintenanceRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на ремонт")
+		return
+	}
+
+	record, err := h.service.OpenMaintenance(equipmentID, services.MaintenanceOpening{
+		Issue:    request.Issue,
+		Vendor:   request.Vendor,
+		Cost:     request.Cost,
+		OpenedAt: request.OpenedAt,
+	})
+	if err != nil {
+		respondError(w, "Error opening maintenance", err)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+		}).Error("Ошибка при передаче оборудования в ремонт")
+		return
+	}
+
+	writeMaintenance(w, http.StatusCreated, record)
+	logrus.WithFields(logrus.Fields{
+		"maintenance_id": record.ID,
+		"equipment_id":   equipmentID,
+		"vendor":         record.Vendor,
+	}).Info("Оборудование передано в ремонт")
+}
+
+// CloseMaintenanceHandler завершает ремонт и возвращает оборудованию статус
+func (h *MaintenanceHandler) CloseMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid maintenance ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID записи о ремонте")
+		return
+	}
+
+	var request CloseMaintenanceRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на завершение ремонта")
+		return
+	}
+
+	record, err := h.service.CloseMaintenance(id, services.MaintenanceClosing{
+		Outcome:  request.Outcome,
+		Cost:     request.Cost,
+		Note:     request.Note,
+		ClosedAt: request.ClosedAt,
+	})
+	if err != nil {
+		respondError(w, "Error closing maintenance", err)
+		logrus.WithFields(logrus.Fields{
+			"error":          err,
+			"maintenance_id": id,
+		}).Error("Ошибка при завершении ремонта")
+		return
+	}
+
+	writeMaintenance(w, http.StatusOK, record)
+	logrus.WithFields(logrus.Fields{
+		"maintenance_id": id,
+		"equipment_id":   record.EquipmentID,
+		"outcome":        record.Outcome,
+	}).Info("Ремонт завершён")
+}
+
+// GetEquipmentMaintenanceHandler возвращает историю ремонтов оборудования
+func (h *MaintenanceHandler) GetEquipmentMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	equipmentID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid equipment ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID оборудования")
+		return
+	}
+
+	records, err := h.service.GetEquipmentMaintenance(equipmentID)
+	if err != nil {
+		http.Error(w, "Error retrieving maintenance", statusForError(err))
+		logrus.WithError(err).Error("Ошибка при получении истории ремонтов")
+		return
+	}
+
+	writeMaintenanceList(w, records)
+}
+
+// GetOpenMaintenanceHandler возвращает оборудование, находящееся в ремонте
+func (h *MaintenanceHandler) GetOpenMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	records, err := h.service.GetOpenMaintenance()
+	if err != nil {
+		http.Error(w, "Error retrieving maintenance", http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при получении оборудования в ремонте")
+		return
+	}
+
+	writeMaintenanceList(w, records)
+}
+
+// writeMaintenance отправляет запись о ремонте в формате JSON
+func writeMaintenance(w http.ResponseWriter, status int, record *services.MaintenanceRecord) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(record); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
+
+// writeMaintenanceList отправляет список записей о ремонте в формате JSON
+func writeMaintenanceList(w http.ResponseWriter, records []services.MaintenanceRecord) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(records); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"inva/pkg/validation"
+	"inva/services"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultTaskWindow горизонт предстоящих задач обслуживания, если параметр within не задан
+const defaultTaskWindow = 30 * 24 * time.Hour
+
+// MaintenanceScheduleRequest тело запроса на создание расписания обслуживания
+type MaintenanceScheduleRequest struct {
+	EquipmentID *int   `json:"equipment_id" validate:"min=1"`
+	Category    string `json:"category" validate:"max=100"`
+	Name        string `json:"name" validate:"required,max=255"`
+	Spec        string `json:"spec" validate:"required,max=100"`
+}
+
+// CompleteTaskRequest необязательное тело запроса на выполнение задачи обслуживания
+type CompleteTaskRequest struct {
+	Note        string     `json:"note" validate:"max=500"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// MaintenanceScheduleHandler представляет обработчик для планового обслуживания оборудования
+type MaintenanceScheduleHandler struct {
+	service *services.MaintenanceScheduleService
+}
+
+// NewMaintenanceScheduleHandler создаёт новый экземпляр MaintenanceScheduleHandler
+func NewMaintenanceScheduleHandler(service *services.MaintenanceScheduleService) *MaintenanceScheduleHandler {
+	return &MaintenanceScheduleHandler{service: service}
+}
+
+// CreateScheduleHandler создаёт расписание обслуживания
+func (h *MaintenanceScheduleHandler) CreateScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	var request MaintenanceScheduleRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на создание расписания обслуживания")
+		return
+	}
+
+	created, err := h.service.CreateSchedule(&services.MaintenanceSchedule{
+		EquipmentID: request.EquipmentID,
+		Category:    request.Category,
+		Name:        request.Name,
+		Spec:        request.Spec,
+	})
+	if err != nil {
+		respondError(w, "Error creating schedule", err)
+		logrus.WithError(err).Error("Ошибка при создании расписания обслуживания")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(created); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+	logrus.WithFields(logrus.Fields{
+		"schedule_id": created.ID,
+		"spec":        created.Spec,
+	}).Info("Создано расписание обслуживания")
+}
+
+// GetAllSchedulesHandler возвращает все расписания обслуживания
+func (h *MaintenanceScheduleHandler) GetAllSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	schedules, err := h.service.GetAllSchedules()
+	if err != nil {
+		http.Error(w, "Error retrieving schedules", http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при получении расписаний обслуживания")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(schedules); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
+
+// DeleteScheduleHandler удаляет расписание обслуживания вместе с его задачами
+func (h *MaintenanceScheduleHandler) DeleteScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid schedule ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID расписания")
+		return
+	}
+
+	if err := h.service.DeleteSchedule(id); err != nil {
+		respondError(w, "Error deleting schedule", err)
+		logrus.WithError(err).Error("Ошибка при удалении расписания обслуживания")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTasksHandler возвращает открытые задачи обслуживания: предстоящие (due=upcoming, горизонт within),
+// просроченные (due=overdue) или все
+func (h *MaintenanceScheduleHandler) GetTasksHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := services.TaskFilter{Due: query.Get("due"), Within: defaultTaskWindow, Now: time.Now()}
+	if filter.Due != "" && filter.Due != services.TaskDueUpcoming && filter.Due != services.TaskDueOverdue {
+		http.Error(w, "Invalid due parameter, upcoming or overdue expected", http.StatusBadRequest)
+		return
+	}
+	if value := query.Get("within"); value != "" {
+		within, err := parseWindow(value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.Within = within
+	}
+	if value := query.Get("equipment_id"); value != "" {
+		equipmentID, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid equipment_id", http.StatusBadRequest)
+			return
+		}
+		filter.EquipmentID = equipmentID
+	}
+
+	tasks, err := h.service.GetTasks(filter)
+	if err != nil {
+		http.Error(w, "Error retrieving maintenance tasks", http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при получении задач обслуживания")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(tasks); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
+
+// CompleteTaskHandler отмечает задачу обслуживания выполненной и назначает следующий срок
+func (h *MaintenanceScheduleHandler) CompleteTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID задачи обслуживания")
+		return
+	}
+
+	// Тело запроса необязательно: без него задача считается выполненной сейчас
+	var request CompleteTaskRequest
+	if err := validation.DecodeOptionalJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на выполнение задачи обслуживания")
+		return
+	}
+	completedAt := time.Now()
+	if request.CompletedAt != nil {
+		completedAt = *request.CompletedAt
+	}
+
+	task, err := h.service.CompleteTask(id, request.Note, completedAt)
+	if err != nil {
+		respondError(w, "Error completing task", err)
+		logrus.WithFields(logrus.Fields{
+			"error":   err,
+			"task_id": id,
+		}).Error("Ошибка при выполнении задачи обслуживания")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(task); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+	logrus.WithFields(logrus.Fields{
+		"task_id":      id,
+		"equipment_id": task.EquipmentID,
+		"next_due_at":  task.NextDueAt,
+	}).Info("Задача обслуживания выполнена")
+}
+
+// parseWindow разбирает горизонт вида "30d" или длительность Go вида "12h"
+func parseWindow(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("Invalid within parameter %q, e.g. 30d or 12h expected", value)
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"inva/pkg/validation"
+	"inva/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// OffboardRequest необязательное тело запроса на увольнение сотрудника
+type OffboardRequest struct {
+	DueAt *time.Time `json:"due_at" validate:"future"`
+}
+
+// WriteOffRequest тело запроса на списание оборудования по задаче на возврат
+type WriteOffRequest struct {
+	Note string `json:"note" validate:"required,max=255"`
+}
+
+// OffboardingHandler представляет обработчик для увольнения сотрудников и возврата их оборудования
+type OffboardingHandler struct {
+	service *services.OffboardingService
+}
+
+// NewOffboardingHandler создаёт новый экземпляр OffboardingHandler
+func NewOffboardingHandler(service *services.OffboardingService) *OffboardingHandler {
+	return &OffboardingHandler{service: service}
+}
+
+// OffboardEmployeeHandler начинает увольнение сотрудника и создаёт задачи на возврат его оборудования
+func (h *OffboardingHandler) OffboardEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid employee ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID сотрудника")
+		return
+	}
+
+	// Тело запроса необязательно: без него срок возврата составляет DefaultReturnDeadline
+	var request OffboardRequest
+	if err := validation.DecodeOptionalJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на увольнение")
+		return
+	}
+	dueAt := time.Now().Add(services.DefaultReturnDeadline)
+	if request.DueAt != nil {
+		dueAt = *request.DueAt
+	}
+
+	offboarding, err := h.service.Offboard(employeeID, dueAt)
+	if err != nil {
+		respondError(w, "Error offboarding employee", err)
+		logrus.WithFields(logrus.Fields{
+			"error":       err,
+			"employee_id": employeeID,
+		}).Error("Ошибка при увольнении сотрудника")
+		return
+	}
+
+	writeOffboarding(w, offboarding)
+	logrus.WithFields(logrus.Fields{
+		"employee_id": employeeID,
+		"open_tasks":  offboarding.OpenTasks,
+		"due_at":      dueAt,
+	}).Info("Начато увольнение сотрудника")
+}
+
+// GetOffboardingHandler возвращает статус сотрудника и его задачи на возврат оборудования
+func (h *OffboardingHandler) GetOffboardingHandler(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid employee ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID сотрудника")
+		return
+	}
+
+	offboarding, err := h.service.GetOffboarding(employeeID)
+	if err != nil {
+		http.Error(w, "Error retrieving offboarding", statusForError(err))
+		logrus.WithError(err).Error("Ошибка при получении задач на возврат")
+		return
+	}
+
+	writeOffboarding(w, offboarding)
+}
+
+// DeactivateEmployeeHandler окончательно деактивирует сотрудника после возврата или списания всего оборудования
+func (h *OffboardingHandler) DeactivateEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid employee ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID сотрудника")
+		return
+	}
+
+	offboarding, err := h.service.Deactivate(employeeID)
+	if err != nil {
+		respondError(w, "Error deactivating employee", err)
+		logrus.WithFields(logrus.Fields{
+			"error":       err,
+			"employee_id": employeeID,
+		}).Error("Ошибка при деактивации сотрудника")
+		return
+	}
+
+	writeOffboarding(w, offboarding)
+	logrus.WithField("employee_id", employeeID).Info("Сотрудник деактивирован")
+}
+
+// WriteOffHandler закрывает задачу на возврат списанием оборудования
+func (h *OffboardingHandler) WriteOffHandler(w http.ResponseWriter, r *http.Request) {
+	taskID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid return task ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID задачи на возврат")
+		return
+	}
+
+	var request WriteOffRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на списание")
+		return
+	}
+
+	task, err := h.service.WriteOff(taskID, request.Note)
+	if err != nil {
+		respondError(w, "Error writing off equipment", err)
+		logrus.WithFields(logrus.Fields{
+			"error":          err,
+			"return_task_id": taskID,
+		}).Error("Ошибка при списании оборудования")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(task); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+	logrus.WithFields(logrus.Fields{
+		"return_task_id": taskID,
+		"equipment_id":   task.EquipmentID,
+	}).Info("Оборудование списано по задаче на возврат")
+}
+
+// writeOffboarding отправляет сводку увольнения в формате JSON
+func writeOffboarding(w http.ResponseWriter, offboarding *services.Offboarding) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(offboarding); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
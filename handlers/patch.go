@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"inva/pkg/validation"
+	"inva/services"
+	"mime"
+	"net/http"
+)
+
+// mergePatchContentType тип содержимого JSON Merge Patch (RFC 7396)
+const mergePatchContentType = "application/merge-patch+json"
+
+// numericPatchFields поля, принимающие число или null; значение передаётся сервису десятичной строкой
+var numericPatchFields = map[string]bool{"purchase_cost": true, "useful_life_months": true}
+
+// errUnsupportedPatchType возвращается, если тело PATCH-запроса не является JSON Merge Patch
+var errUnsupportedPatchType = errors.New("PATCH body must be " + mergePatchContentType)
+
+// decodeMergePatch читает документ JSON Merge Patch со строковыми полями из списка fields.
+// Отсутствующие поля не попадают в результат, а значение null заменяется пустой строкой, то есть очищает поле.
+// Поля из numericPatchFields принимают число и передаются его десятичной записью.
+func decodeMergePatch(w http.ResponseWriter, r *http.Request, fields ...string) (map[string]*string, error) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+			return nil, errUnsupportedPatchType
+		}
+	}
+
+	// Документ, не являющийся объектом, по RFC 7396 заменил бы запись целиком, что здесь не допускается
+	var document map[string]json.RawMessage
+	if err := validation.DecodeJSON(w, r, &document); err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, validation.New("body", validation.CodeInvalidType, "документ merge patch должен быть JSON-объектом")
+	}
+
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field] = true
+	}
+
+	v := &services.ValidationError{}
+	values := make(map[string]*string, len(document))
+	for field, raw := range document {
+		if !known[field] {
+			v.Add(field, validation.CodeUnknownField, "поле не может быть изменено")
+			continue
+		}
+
+		var value *string
+		if numericPatchFields[field] {
+			var number *json.Number
+			if err := json.Unmarshal(raw, &number); err != nil || (number != nil && raw[0] == '"') {
+				v.Add(field, validation.CodeInvalidType, "ожидается число или null")
+				continue
+			}
+			if number != nil {
+				value = new(string)
+				*value = number.String()
+			}
+		} else if err := json.Unmarshal(raw, &value); err != nil {
+			v.Add(field, validation.CodeInvalidType, "ожидается строка или null")
+			continue
+		}
+		if value == nil {
+			value = new(string)
+		}
+		values[field] = value
+	}
+
+	if err := v.ErrOrNil(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"inva/pkg/validation"
+	"inva/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// PolicyRequest тело запроса на создание правила выдачи
+type PolicyRequest struct {
+	Rule           string   `json:"rule" validate:"required,oneof=max_per_category restricted_category approval_threshold"`
+	Category       string   `json:"category" validate:"max=100"`
+	MaxItems       int      `json:"max_items" validate:"min=0"`
+	Departments    []string `json:"departments" validate:"max=50"`
+	Positions      []string `json:"positions" validate:"max=50"`
+	ValueThreshold float64  `json:"value_threshold"`
+}
+
+// PolicyHandler представляет обработчик для политик выдачи оборудования
+type PolicyHandler struct {
+	service *services.PolicyService
+}
+
+// NewPolicyHandler создаёт новый экземпляр PolicyHandler
+func NewPolicyHandler(service *services.PolicyService) *PolicyHandler {
+	return &PolicyHandler{service: service}
+}
+
+// CreatePolicyHandler создаёт правило выдачи
+func (h *PolicyHandler) CreatePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	var request PolicyRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на создание политики выдачи")
+		return
+	}
+
+	policy, err := h.service.CreatePolicy(&services.Policy{
+		Rule:           request.Rule,
+		Category:       request.Category,
+		MaxItems:       request.MaxItems,
+		Departments:    request.Departments,
+		Positions:      request.Positions,
+		ValueThreshold: request.ValueThreshold,
+	})
+	if err != nil {
+		respondError(w, "Error creating policy", err)
+		logrus.WithError(err).Error("Ошибка при создании политики выдачи")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(policy); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+	logrus.WithFields(logrus.Fields{
+		"policy_id": policy.ID,
+		"rule":      policy.Rule,
+	}).Info("Создана политика выдачи")
+}
+
+// GetAllPoliciesHandler возвращает все правила выдачи
+func (h *PolicyHandler) GetAllPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.service.GetAllPolicies()
+	if err != nil {
+		http.Error(w, "Error retrieving policies", http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при получении политик выдачи")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(policies); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
+
+// DeletePolicyHandler удаляет правило выдачи
+func (h *PolicyHandler) DeletePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid policy ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID политики выдачи")
+		return
+	}
+
+	if err := h.service.DeletePolicy(id); err != nil {
+		respondError(w, "Error deleting policy", err)
+		logrus.WithError(err).Error("Ошибка при удалении политики выдачи")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetOverridesHandler возвращает журнал выдач вопреки политикам
+func (h *PolicyHandler) GetOverridesHandler(w http.ResponseWriter, r *http.Request) {
+	records, err := h.service.GetOverrides()
+	if err != nil {
+		http.Error(w, "Error retrieving policy overrides", http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при получении журнала исключений из политик")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(records); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"encoding/json"
+	"inva/pkg/validation"
+	"inva/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// VendorRequest тело запроса на создание поставщика
+type VendorRequest struct {
+	Name         string `json:"name" validate:"required,max=255"`
+	ContactEmail string `json:"contact_email" validate:"max=255"`
+	Phone        string `json:"phone" validate:"max=50"`
+}
+
+// PurchaseOrderLineRequest позиция заказа в теле запроса
+type PurchaseOrderLineRequest struct {
+	Model          string  `json:"model" validate:"required,max=255"`
+	Category       string  `json:"category" validate:"max=100"`
+	Quantity       int     `json:"quantity" validate:"required,min=1,max=1000"`
+	UnitCost       float64 `json:"unit_cost" validate:"min=0"`
+	WarrantyMonths int     `json:"warranty_months" validate:"min=0,max=600"`
+}
+
+// PurchaseOrderRequest тело запроса на создание заказа поставщику
+type PurchaseOrderRequest struct {
+	VendorID  int                        `json:"vendor_id" validate:"required,min=1"`
+	Number    string                     `json:"number" validate:"required,max=50"`
+	Currency  string                     `json:"currency" validate:"max=3"`
+	OrderedAt *time.Time                 `json:"ordered_at"`
+	Lines     []PurchaseOrderLineRequest `json:"lines" validate:"required,max=100"`
+}
+
+// ReceiveRequest тело запроса на приёмку оборудования по позиции заказа
+type ReceiveRequest struct {
+	SerialNumbers []string   `json:"serial_numbers" validate:"required,max=1000"`
+	Location      string     `json:"location" validate:"max=255"`
+	ReceivedAt    *time.Time `json:"received_at"`
+}
+
+// PurchaseHandler представляет обработчик для поставщиков, заказов и приёмки оборудования
+type PurchaseHandler struct {
+	vendors *services.VendorService
+	orders  *services.PurchaseOrderService
+}
+
+// NewPurchaseHandler создаёт новый экземпляр PurchaseHandler
+func NewPurchaseHandler(vendors *services.VendorService, orders *services.PurchaseOrderService) *PurchaseHandler {
+	return &PurchaseHandler{vendors: vendors, orders: orders}
+}
+
+// CreateVendorHandler создаёт поставщика
+func (h *PurchaseHandler) CreateVendorHandler(w http.ResponseWriter, r *http.Request) {
+	var request VendorRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на создание поставщика")
+		return
+	}
+
+	vendor, err := h.vendors.CreateVendor(&services.Vendor{
+		Name:         request.Name,
+		ContactEmail: request.ContactEmail,
+		Phone:        request.Phone,
+	})
+	if err != nil {
+		respondError(w, "Error creating vendor", err)
+		logrus.WithError(err).Error("Ошибка при создании поставщика")
+		return
+	}
+
+	writePurchaseJSON(w, http.StatusCreated, vendor)
+	logrus.WithFields(logrus.Fields{
+		"vendor_id": vendor.ID,
+		"name":      vendor.Name,
+	}).Info("Создан поставщик")
+}
+
+// GetAllVendorsHandler возвращает всех поставщиков
+func (h *PurchaseHandler) GetAllVendorsHandler(w http.ResponseWriter, r *http.Request) {
+	vendors, err := h.vendors.GetAllVendors()
+	if err != nil {
+		http.Error(w, "Error retrieving vendors", http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при получении поставщиков")
+		return
+	}
+
+	writePurchaseJSON(w, http.StatusOK, vendors)
+}
+
+// GetVendorHandler возвращает поставщика по идентификатору
+func (h *PurchaseHandler) GetVendorHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid vendor ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID поставщика")
+		return
+	}
+
+	vendor, err := h.vendors.GetVendor(id)
+	if err != nil {
+		respondError(w, "Error retrieving vendor", err)
+		logrus.WithError(err).Error("Ошибка при получении поставщика")
+		return
+	}
+
+	writePurchaseJSON(w, http.StatusOK, vendor)
+}
+
+// CreatePurchaseOrderHandler создаёт заказ поставщику с позициями
+func (h *PurchaseHandler) CreatePurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	var request PurchaseOrderRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на создание заказа")
+		return
+	}
+
+	order := &services.PurchaseOrder{
+		VendorID: request.VendorID,
+		Number:   request.Number,
+		Currency: request.Currency,
+		Lines:    make([]services.PurchaseOrderLine, len(request.Lines)),
+	}
+	if request.OrderedAt != nil {
+		order.OrderedAt = *request.OrderedAt
+	}
+	for i, line := range request.Lines {
+		order.Lines[i] = services.PurchaseOrderLine{
+			Model:          line.Model,
+			Category:       line.Category,
+			Quantity:       line.Quantity,
+			UnitCost:       line.UnitCost,
+			WarrantyMonths: line.WarrantyMonths,
+		}
+	}
+
+	created, err := h.orders.CreatePurchaseOrder(order)
+	if err != nil {
+		respondError(w, "Error creating purchase order", err)
+		logrus.WithError(err).Error("Ошибка при создании заказа поставщику")
+		return
+	}
+
+	writePurchaseJSON(w, http.StatusCreated, created)
+	logrus.WithFields(logrus.Fields{
+		"order_id":  created.ID,
+		"number":    created.Number,
+		"vendor_id": created.VendorID,
+	}).Info("Создан заказ поставщику")
+}
+
+// GetPurchaseOrdersHandler возвращает заказы с фильтрами vendor_id и status
+func (h *PurchaseHandler) GetPurchaseOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := services.PurchaseOrderFilter{Status: query.Get("status")}
+	if value := query.Get("vendor_id"); value != "" {
+		vendorID, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid vendor_id", http.StatusBadRequest)
+			return
+		}
+		filter.VendorID = vendorID
+	}
+
+	orders, err := h.orders.GetPurchaseOrders(filter)
+	if err != nil {
+		http.Error(w, "Error retrieving purchase orders", http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при получении заказов поставщикам")
+		return
+	}
+
+	writePurchaseJSON(w, http.StatusOK, orders)
+}
+
+// GetPurchaseOrderHandler возвращает заказ с позициями и принятым количеством
+func (h *PurchaseHandler) GetPurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid purchase order ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID заказа")
+		return
+	}
+
+	order, err := h.orders.GetPurchaseOrder(id)
+	if err != nil {
+		respondError(w, "Error retrieving purchase order", err)
+		logrus.WithError(err).Error("Ошибка при получении заказа поставщику")
+		return
+	}
+
+	writePurchaseJSON(w, http.StatusOK, order)
+}
+
+// ReceiveLineHandler принимает оборудование по позиции заказа и создаёт его записи
+func (h *PurchaseHandler) ReceiveLineHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid purchase order ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID заказа")
+		return
+	}
+	lineID, err := strconv.Atoi(vars["line_id"])
+	if err != nil {
+		http.Error(w, "Invalid line ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID позиции заказа")
+		return
+	}
+
+	var request ReceiveRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на приёмку")
+		return
+	}
+
+	result, err := h.orders.ReceiveLine(orderID, lineID, services.Receipt{
+		SerialNumbers: request.SerialNumbers,
+		Location:      request.Location,
+		ReceivedAt:    request.ReceivedAt,
+	})
+	if err != nil {
+		respondError(w, "Error receiving equipment", err)
+		logrus.WithFields(logrus.Fields{
+			"error":    err,
+			"order_id": orderID,
+			"line_id":  lineID,
+		}).Error("Ошибка при приёмке оборудования по заказу")
+		return
+	}
+
+	writePurchaseJSON(w, http.StatusCreated, result)
+	logrus.WithFields(logrus.Fields{
+		"order_id":     orderID,
+		"line_id":      lineID,
+		"received":     result.Received,
+		"order_status": result.OrderStatus,
+	}).Info("Оборудование принято по заказу")
+}
+
+// writePurchaseJSON отправляет ответ в формате JSON
+func writePurchaseJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
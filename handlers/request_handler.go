@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"encoding/json"
+	"inva/pkg/validation"
+	"inva/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// EquipmentRequestRequest тело заявки сотрудника на оборудование
+type EquipmentRequestRequest struct {
+	EmployeeID    int    `json:"employee_id" validate:"required,min=1"`
+	Category      string `json:"category" validate:"required,max=100"`
+	Justification string `json:"justification" validate:"required,max=500"`
+}
+
+// RequestDecisionRequest тело решения руководителя по заявке
+type RequestDecisionRequest struct {
+	ManagerID int    `json:"manager_id" validate:"required,min=1"`
+	Note      string `json:"note" validate:"max=500"`
+}
+
+// FulfillRequest тело запроса на выдачу оборудования по одобренной заявке
+type FulfillRequest struct {
+	EquipmentID int              `json:"equipment_id" validate:"required,min=1"`
+	DueAt       *time.Time       `json:"due_at" validate:"future"`
+	Override    *OverrideRequest `json:"override"`
+}
+
+// RequestHandler представляет обработчик для заявок сотрудников на оборудование
+type RequestHandler struct {
+	service *services.RequestService
+}
+
+// NewRequestHandler создаёт новый экземпляр RequestHandler
+func NewRequestHandler(service *services.RequestService) *RequestHandler {
+	return &RequestHandler{service: service}
+}
+
+// CreateRequestHandler регистрирует заявку сотрудника на оборудование
+func (h *RequestHandler) CreateRequestHandler(w http.ResponseWriter, r *http.Request) {
+	var request EquipmentRequestRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании заявки на оборудование")
+		return
+	}
+
+	created, err := h.service.CreateRequest(request.EmployeeID, request.Category, request.Justification)
+	if err != nil {
+		respondError(w, "Error creating request", err)
+		logrus.WithFields(logrus.Fields{
+			"error":       err,
+			"employee_id": request.EmployeeID,
+		}).Error("Ошибка при создании заявки на оборудование")
+		return
+	}
+
+	writeRequest(w, http.StatusCreated, created)
+	logrus.WithFields(logrus.Fields{
+		"request_id":  created.ID,
+		"employee_id": created.EmployeeID,
+		"category":    created.Category,
+	}).Info("Создана заявка на оборудование")
+}
+
+// GetRequestsHandler возвращает заявки с отбором по статусу и сотруднику
+func (h *RequestHandler) GetRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := services.RequestFilter{Status: query.Get("status")}
+	if value := query.Get("employee_id"); value != "" {
+		employeeID, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid employee_id", http.StatusBadRequest)
+			return
+		}
+		filter.EmployeeID = employeeID
+	}
+
+	requests, err := h.service.GetRequests(filter)
+	if err != nil {
+		http.Error(w, "Error retrieving requests", http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при получении заявок на оборудование")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(requests); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
+
+// GetRequestHandler возвращает заявку по идентификатору
+func (h *RequestHandler) GetRequestHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := requestID(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.service.GetRequest(id)
+	if err != nil {
+		http.Error(w, "Error retrieving request", statusForError(err))
+		logrus.WithError(err).Error("Ошибка при получении заявки на оборудование")
+		return
+	}
+
+	writeRequest(w, http.StatusOK, request)
+}
+
+// ApproveRequestHandler одобряет заявку
+func (h *RequestHandler) ApproveRequestHandler(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.Approve, "Заявка на оборудование одобрена")
+}
+
+// RejectRequestHandler отклоняет заявку
+func (h *RequestHandler) RejectRequestHandler(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.Reject, "Заявка на оборудование отклонена")
+}
+
+// CancelRequestHandler отзывает заявку, по которой ещё не выдано оборудование
+func (h *RequestHandler) CancelRequestHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := requestID(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.service.Cancel(id)
+	if err != nil {
+		respondError(w, "Error cancelling request", err)
+		logrus.WithFields(logrus.Fields{
+			"error":      err,
+			"request_id": id,
+		}).Error("Ошибка при отзыве заявки на оборудование")
+		return
+	}
+
+	writeRequest(w, http.StatusOK, request)
+	logrus.WithField("request_id", id).Info("Заявка на оборудование отозвана")
+}
+
+// FulfillRequestHandler выдаёт автору одобренной заявки указанную единицу оборудования
+func (h *RequestHandler) FulfillRequestHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := requestID(w, r)
+	if !ok {
+		return
+	}
+
+	var body FulfillRequest
+	if err := validation.DecodeJSON(w, r, &body); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на выполнение заявки")
+		return
+	}
+
+	request, err := h.service.Fulfill(id, body.EquipmentID, AssignRequest{DueAt: body.DueAt, Override: body.Override}.options())
+	if err != nil {
+		respondError(w, "Failed to fulfill request", err)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"request_id":   id,
+			"equipment_id": body.EquipmentID,
+		}).Error("Ошибка при выполнении заявки на оборудование")
+		return
+	}
+
+	writeRequest(w, http.StatusOK, request)
+	logrus.WithFields(logrus.Fields{
+		"request_id":   id,
+		"employee_id":  request.EmployeeID,
+		"equipment_id": body.EquipmentID,
+	}).Info("Заявка на оборудование выполнена")
+}
+
+// decide записывает решение руководителя по заявке
+func (h *RequestHandler) decide(w http.ResponseWriter, r *http.Request,
+	decide func(id, managerID int, note string) (*services.EquipmentRequest, error), message string) {
+	id, ok := requestID(w, r)
+	if !ok {
+		return
+	}
+
+	var body RequestDecisionRequest
+	if err := validation.DecodeJSON(w, r, &body); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании решения по заявке")
+		return
+	}
+
+	request, err := decide(id, body.ManagerID, body.Note)
+	if err != nil {
+		respondError(w, "Error deciding request", err)
+		logrus.WithFields(logrus.Fields{
+			"error":      err,
+			"request_id": id,
+			"manager_id": body.ManagerID,
+		}).Error("Ошибка при принятии решения по заявке")
+		return
+	}
+
+	writeRequest(w, http.StatusOK, request)
+	logrus.WithFields(logrus.Fields{
+		"request_id": id,
+		"manager_id": body.ManagerID,
+	}).Info(message)
+}
+
+// requestID читает идентификатор заявки из пути запроса
+func requestID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid request ID", http.StatusBadRequest)
+		logrus.WithError(err).Error("Ошибка при преобразовании ID заявки")
+		return 0, false
+	}
+	return id, true
+}
+
+// writeRequest отправляет заявку в формате JSON
+func writeRequest(w http.ResponseWriter, status int, request *services.EquipmentRequest) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(request); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"inva/pkg/ical"
+	"inva/pkg/validation"
+	"inva/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// ReservationRequest тело запроса на бронирование оборудования
+type ReservationRequest struct {
+	UserID   int       `json:"user_id" validate:"required,min=1"`
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+	EndsAt   time.Time `json:"ends_at" validate:"required"`
+	Note     string    `json:"note" validate:"max=500"`
+}
+
+// ReservationHandler представляет обработчик для бронирования оборудования
+type ReservationHandler struct {
+	service   *services.ReservationService
+	equipment *services.EquipmentService
+}
+
+// NewReservationHandler создаёт новый экземпляр ReservationHandler
+func NewReservationHandler(service *services.ReservationService, equipment *services.EquipmentService) *ReservationHandler {
+	return &ReservationHandler{service: service, equipment: equipment}
+}
+
+// CreateReservationHandler бронирует оборудование на интервал времени
+func (h *ReservationHandler) CreateReservationHandler(w http.ResponseWriter, r *http.Request) {
+	equipmentIDStr := mux.Vars(r)["id"]
+	equipmentID, err := strconv.Atoi(equipmentIDStr)
+	if err != nil {
+		http.Error(w, "Invalid equipment ID", http.StatusBadRequest)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentIDStr,
+		}).Error("Ошибка при преобразовании ID оборудования")
+		return
+	}
+
+	var request ReservationRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+		}).Error("Ошибка при декодировании запроса на бронирование")
+		return
+	}
+
+	reservation, err := h.service.CreateReservation(&services.Reservation{
+		EquipmentID: equipmentID,
+		UserID:      request.UserID,
+		StartsAt:    request.StartsAt,
+		EndsAt:      request.EndsAt,
+		Note:        request.Note,
+	})
+	if err != nil {
+		respondError(w, "Error creating reservation", err)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+			"user_id":      request.UserID,
+		}).Error("Ошибка при создании бронирования")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(reservation); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+	logrus.WithFields(logrus.Fields{
+		"reservation_id": reservation.ID,
+		"equipment_id":   equipmentID,
+	}).Info("Оборудование успешно забронировано")
+}
+
+// ListReservationsHandler возвращает актуальные бронирования оборудования
+func (h *ReservationHandler) ListReservationsHandler(w http.ResponseWriter, r *http.Request) {
+	equipmentID, from, includeCancelled, ok := h.parseListParams(w, r)
+	if !ok {
+		return
+	}
+
+	reservations, err := h.service.ListReservations(equipmentID, from, includeCancelled)
+	if err != nil {
+		http.Error(w, "Error retrieving reservations: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+		}).Error("Ошибка при получении бронирований")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(reservations); err != nil {
+		http.Error(w, "Error encoding response: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
+
+// ReservationsCalendarHandler возвращает бронирования оборудования в формате iCalendar
+func (h *ReservationHandler) ReservationsCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	equipmentID, from, _, ok := h.parseListParams(w, r)
+	if !ok {
+		return
+	}
+
+	equipment, err := h.equipment.GetEquipmentByID(equipmentID)
+	if err != nil {
+		respondError(w, "Error retrieving equipment", err)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+		}).Error("Ошибка при получении оборудования для календаря")
+		return
+	}
+
+	reservations, err := h.service.ListReservations(equipmentID, from, false)
+	if err != nil {
+		http.Error(w, "Error retrieving reservations: "+err.Error(), http.StatusInternalServerError)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+		}).Error("Ошибка при получении бронирований")
+		return
+	}
+
+	tag := services.AssetTag(equipment.ID)
+	calendar := ical.Calendar{
+		ProductID: "-//inva//reservations//EN",
+		Name:      fmt.Sprintf("%s (%s)", equipment.Model, tag),
+	}
+	for _, reservation := range reservations {
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:         fmt.Sprintf("reservation-%d@inva", reservation.ID),
+			Start:       reservation.StartsAt,
+			End:         reservation.EndsAt,
+			Created:     reservation.CreatedAt,
+			Summary:     fmt.Sprintf("%s reserved by %s", tag, services.BadgeID(reservation.UserID)),
+			Description: reservation.Note,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s.ics"`, tag))
+	if err := calendar.Write(w); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentID,
+		}).Error("Ошибка при записи календаря бронирований")
+	}
+}
+
+// CancelReservationHandler отменяет бронирование
+func (h *ReservationHandler) CancelReservationHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid reservation ID", http.StatusBadRequest)
+		logrus.WithFields(logrus.Fields{
+			"error":          err,
+			"reservation_id": idStr,
+		}).Error("Ошибка при преобразовании ID бронирования")
+		return
+	}
+
+	if err := h.service.CancelReservation(id); err != nil {
+		respondError(w, "Error cancelling reservation", err)
+		logrus.WithFields(logrus.Fields{
+			"error":          err,
+			"reservation_id": id,
+		}).Error("Ошибка при отмене бронирования")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logrus.WithField("reservation_id", id).Info("Бронирование успешно отменено")
+}
+
+// parseListParams извлекает ID оборудования и параметры выборки бронирований
+func (h *ReservationHandler) parseListParams(w http.ResponseWriter, r *http.Request) (int, time.Time, bool, bool) {
+	equipmentIDStr := mux.Vars(r)["id"]
+	equipmentID, err := strconv.Atoi(equipmentIDStr)
+	if err != nil {
+		http.Error(w, "Invalid equipment ID", http.StatusBadRequest)
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"equipment_id": equipmentIDStr,
+		}).Error("Ошибка при преобразовании ID оборудования")
+		return 0, time.Time{}, false, false
+	}
+
+	// По умолчанию показываем бронирования, которые ещё не закончились
+	from := time.Now()
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		from, err = time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			http.Error(w, "Invalid from parameter, RFC 3339 expected", http.StatusBadRequest)
+			return 0, time.Time{}, false, false
+		}
+	}
+
+	includeCancelled := r.URL.Query().Get("include_cancelled") == "true"
+	return equipmentID, from, includeCancelled, true
+}
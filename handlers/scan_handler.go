@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"inva/pkg/validation"
+	"inva/services"
+	"inva/utils"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ScanRequest тело запроса от сканера на стойке выдачи
+type ScanRequest struct {
+	Asset string `json:"asset" validate:"required,max=100"`
+	Badge string `json:"badge" validate:"required,max=255"`
+}
+
+// CheckinRequest тело запроса от сканера при возврате оборудования
+type CheckinRequest struct {
+	Asset string `json:"asset" validate:"required,max=100"`
+}
+
+// ScanHandler представляет обработчик операций по сканированию
+type ScanHandler struct {
+	service *services.ScanService
+}
+
+// NewScanHandler создаёт новый экземпляр ScanHandler
+func NewScanHandler(service *services.ScanService) *ScanHandler {
+	return &ScanHandler{service: service}
+}
+
+// CheckoutHandler выдаёт оборудование по отсканированным инвентарному номеру и пропуску
+func (h *ScanHandler) CheckoutHandler(w http.ResponseWriter, r *http.Request) {
+	var request ScanRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на выдачу по сканированию")
+		return
+	}
+
+	result, err := h.service.Checkout(request.Asset, request.Badge)
+	if err != nil {
+		respondError(w, "Error checking out equipment", err)
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+			"asset": request.Asset,
+			"badge": request.Badge,
+		}).Error("Ошибка при выдаче оборудования по сканированию")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, result)
+	logrus.WithFields(logrus.Fields{
+		"equipment_id": result.Equipment.ID,
+		"user_id":      result.Employee.ID,
+	}).Info("Оборудование выдано по сканированию")
+}
+
+// CheckinHandler принимает оборудование по отсканированному инвентарному номеру
+func (h *ScanHandler) CheckinHandler(w http.ResponseWriter, r *http.Request) {
+	var request CheckinRequest
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		respondError(w, "Invalid request payload", err)
+		logrus.WithError(err).Error("Ошибка при декодировании запроса на возврат по сканированию")
+		return
+	}
+
+	result, err := h.service.Checkin(request.Asset)
+	if err != nil {
+		respondError(w, "Error checking in equipment", err)
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+			"asset": request.Asset,
+		}).Error("Ошибка при возврате оборудования по сканированию")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, result)
+	logrus.WithField("equipment_id", result.Equipment.ID).Info("Оборудование возвращено по сканированию")
+}
@@ -0,0 +1,109 @@
+package certificate
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// dateLayout формат дат в акте
+const dateLayout = "2006-01-02"
+
+// fontFamily семейство шрифтов Go с кириллицей; шрифты встраиваются в документ в UTF-8
+const fontFamily = "Go"
+
+// Disposal данные акта об утилизации оборудования
+type Disposal struct {
+	Number         string
+	AssetTag       string
+	Model          string
+	SerialNumber   string
+	Category       string
+	Reason         string
+	Method         string
+	Recipient      string
+	DataWipeMethod string
+	DataWipedBy    string
+	DataWipedAt    time.Time
+	RequestedBy    string
+	ApprovedBy     string
+	DisposedAt     time.Time
+}
+
+// WriteDisposalPDF формирует акт об утилизации оборудования на одной странице A4.
+// Имена, модели и причины выводятся как есть, в том числе кириллицей.
+func WriteDisposalPDF(w io.Writer, d Disposal) error {
+	const (
+		margin     = 20.0
+		labelWidth = 55.0
+		lineHeight = 8.0
+	)
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetTitle("Disposal certificate "+d.Number, false)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", gobold.TTF)
+	pdf.AddUTF8FontFromBytes(fontFamily, "I", goitalic.TTF)
+	pdf.AddPage()
+
+	pdf.SetFont(fontFamily, "B", 16)
+	pdf.CellFormat(0, 10, "Equipment Disposal Certificate", "", 1, "C", false, 0, "")
+	pdf.SetFont(fontFamily, "", 11)
+	pdf.CellFormat(0, 7, "No. "+d.Number, "", 1, "C", false, 0, "")
+	pdf.Ln(6)
+
+	section := func(title string) {
+		pdf.Ln(2)
+		pdf.SetFont(fontFamily, "B", 12)
+		pdf.CellFormat(0, lineHeight, title, "B", 1, "L", false, 0, "")
+		pdf.Ln(1)
+	}
+	row := func(label, value string) {
+		if value == "" {
+			value = "-"
+		}
+		pdf.SetFont(fontFamily, "B", 10)
+		pdf.CellFormat(labelWidth, lineHeight, label, "", 0, "L", false, 0, "")
+		pdf.SetFont(fontFamily, "", 10)
+		pdf.MultiCell(0, lineHeight, value, "", "L", false)
+	}
+
+	section("Equipment")
+	row("Asset tag", d.AssetTag)
+	row("Model", d.Model)
+	row("Serial number", d.SerialNumber)
+	row("Category", d.Category)
+
+	section("Disposal")
+	row("Method", d.Method)
+	row("Reason", d.Reason)
+	row("Recipient", d.Recipient)
+	row("Disposal date", d.DisposedAt.Format(dateLayout))
+
+	section("Data sanitization")
+	row("Method", d.DataWipeMethod)
+	row("Performed by", d.DataWipedBy)
+	row("Date", d.DataWipedAt.Format(dateLayout))
+
+	section("Authorization")
+	row("Requested by", d.RequestedBy)
+	row("Approved by", d.ApprovedBy)
+
+	pdf.Ln(16)
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.CellFormat(80, lineHeight, "Signature: ______________________", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, lineHeight, "Date: ______________", "", 1, "R", false, 0, "")
+
+	pdf.SetY(-25)
+	pdf.SetFont(fontFamily, "I", 8)
+	pdf.CellFormat(0, 5, fmt.Sprintf("Generated %s. The equipment record is kept in the inventory history.",
+		time.Now().UTC().Format(time.RFC3339)), "", 0, "C", false, 0, "")
+
+	return pdf.Output(w)
+}
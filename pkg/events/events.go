@@ -0,0 +1,59 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Event представляет доменное событие, публикуемое фоновыми задачами и сервисами
+type Event struct {
+	Type    string                 `json:"type"`
+	At      time.Time              `json:"at"`
+	Payload map[string]interface{} `json:"payload"`
+}
+
+// Handler обрабатывает опубликованное событие
+type Handler func(Event)
+
+// Bus синхронная шина событий с произвольным числом подписчиков
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+// NewBus создаёт новую шину событий
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe добавляет подписчика на все события шины
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish передаёт событие всем подписчикам
+func (b *Bus) Publish(event Event) {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := make([]Handler, len(b.handlers))
+	copy(handlers, b.handlers)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// LogHandler записывает события в лог
+func LogHandler(event Event) {
+	logrus.WithFields(logrus.Fields(event.Payload)).
+		WithField("event", event.Type).
+		Warn("Событие инвентаризации")
+}
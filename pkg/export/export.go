@@ -0,0 +1,200 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Format формат выгрузки
+type Format string
+
+const (
+	CSV    Format = "csv"
+	XLSX   Format = "xlsx"
+	NDJSON Format = "ndjson"
+)
+
+// sheetName имя листа в книге XLSX
+const sheetName = "Sheet1"
+
+// Writer построчно записывает выгрузку в выбранном формате
+type Writer interface {
+	// WriteHeader записывает имена колонок; вызывается один раз перед строками
+	WriteHeader(columns []string) error
+	// WriteRow записывает строку значений в порядке колонок
+	WriteRow(values []interface{}) error
+	// Close завершает выгрузку и сбрасывает буферы
+	Close() error
+}
+
+// ParseFormat преобразует строку из запроса в формат выгрузки
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", CSV:
+		return CSV, nil
+	case XLSX, NDJSON:
+		return Format(s), nil
+	}
+	return "", fmt.Errorf("неизвестный формат выгрузки: %s", s)
+}
+
+// ContentType возвращает MIME-тип формата
+func (f Format) ContentType() string {
+	switch f {
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case NDJSON:
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// NewWriter создаёт Writer для формата
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case CSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case NDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case XLSX:
+		file := excelize.NewFile()
+		stream, err := file.NewStreamWriter(sheetName)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при создании книги XLSX: %v", err)
+		}
+		return &xlsxWriter{w: w, file: file, stream: stream}, nil
+	}
+	return nil, fmt.Errorf("неизвестный формат выгрузки: %s", format)
+}
+
+// csvWriter записывает выгрузку в CSV
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) WriteHeader(columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatValue(value)
+	}
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+	// Сбрасываем буфер, чтобы строки уходили клиенту по мере чтения из базы
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonWriter записывает выгрузку в JSON Lines, по объекту на строку
+type ndjsonWriter struct {
+	enc     *json.Encoder
+	columns []string
+}
+
+func (n *ndjsonWriter) WriteHeader(columns []string) error {
+	n.columns = columns
+	return nil
+}
+
+func (n *ndjsonWriter) WriteRow(values []interface{}) error {
+	object := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		if i < len(n.columns) {
+			object[n.columns[i]] = value
+		}
+	}
+	return n.enc.Encode(object)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+// xlsxWriter записывает выгрузку в книгу XLSX через потоковый writer excelize
+type xlsxWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func (x *xlsxWriter) WriteHeader(columns []string) error {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		values[i] = column
+	}
+	return x.WriteRow(values)
+}
+
+func (x *xlsxWriter) WriteRow(values []interface{}) error {
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+
+	row := make([]interface{}, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case *int:
+			if v != nil {
+				row[i] = *v
+			}
+		case *time.Time:
+			if v != nil {
+				row[i] = *v
+			}
+		default:
+			row[i] = v
+		}
+	}
+	return x.stream.SetRow(cell, row)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	return x.file.Write(x.w)
+}
+
+// formatValue преобразует значение ячейки в строку для CSV
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case *int:
+		if v == nil {
+			return ""
+		}
+		return strconv.Itoa(*v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
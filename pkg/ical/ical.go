@@ -0,0 +1,95 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// timeFormat формат даты и времени в UTC по RFC 5545
+const timeFormat = "20060102T150405Z"
+
+// maxLineLength максимальная длина строки календаря в октетах без учёта CRLF
+const maxLineLength = 75
+
+// Event событие календаря
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Created     time.Time
+	Summary     string
+	Description string
+}
+
+// Calendar календарь iCalendar с набором событий
+type Calendar struct {
+	ProductID string
+	Name      string
+	Events    []Event
+}
+
+// Write записывает календарь в формате iCalendar (RFC 5545)
+func (c *Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	now := time.Now()
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+escape(c.ProductID))
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	writeLine(bw, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(bw, "X-WR-CALNAME:"+escape(c.Name))
+	}
+
+	for _, event := range c.Events {
+		created := event.Created
+		if created.IsZero() {
+			created = now
+		}
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+escape(event.UID))
+		writeLine(bw, "DTSTAMP:"+now.UTC().Format(timeFormat))
+		writeLine(bw, "CREATED:"+created.UTC().Format(timeFormat))
+		writeLine(bw, "DTSTART:"+event.Start.UTC().Format(timeFormat))
+		writeLine(bw, "DTEND:"+event.End.UTC().Format(timeFormat))
+		writeLine(bw, "SUMMARY:"+escape(event.Summary))
+		if event.Description != "" {
+			writeLine(bw, "DESCRIPTION:"+escape(event.Description))
+		}
+		writeLine(bw, "END:VEVENT")
+	}
+
+	writeLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+// writeLine записывает строку календаря, перенося её по 75 октетов без разрыва символов UTF-8
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		fmt.Fprintf(w, "%s\r\n ", line[:cut])
+		line = line[cut:]
+		// Строки продолжения начинаются с пробела, который занимает один октет
+		limit = maxLineLength - 1
+	}
+	fmt.Fprintf(w, "%s\r\n", line)
+}
+
+// isRuneStart проверяет, что байт не является продолжением многобайтового символа UTF-8
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// escape экранирует спецсимволы текстовых значений
+func escape(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,182 @@
+package labels
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// Symbology тип штрихкода, которым кодируется инвентарный номер
+type Symbology string
+
+const (
+	QR      Symbology = "qr"
+	Code128 Symbology = "code128"
+)
+
+// fontFamily семейство шрифтов Go с кириллицей для подписей наклеек
+const fontFamily = "Go"
+
+// Label описывает одну наклейку для листа печати
+type Label struct {
+	Tag      string
+	Title    string
+	Subtitle string
+}
+
+// ParseSymbology преобразует строку из запроса в тип штрихкода
+func ParseSymbology(s string) (Symbology, error) {
+	switch Symbology(strings.ToLower(s)) {
+	case "", QR:
+		return QR, nil
+	case Code128:
+		return Code128, nil
+	}
+	return "", fmt.Errorf("неизвестный тип штрихкода: %s", s)
+}
+
+// Encode кодирует содержимое в штрихкод указанного типа
+func Encode(content string, symbology Symbology) (barcode.Barcode, error) {
+	switch symbology {
+	case QR:
+		return qr.Encode(content, qr.M, qr.Auto)
+	case Code128:
+		return code128.Encode(content)
+	}
+	return nil, fmt.Errorf("неизвестный тип штрихкода: %s", symbology)
+}
+
+// WritePNG записывает штрихкод в формате PNG, масштабируя каждый модуль до scale пикселей
+func WritePNG(w io.Writer, code barcode.Barcode, scale int) error {
+	width, height := moduleSize(code)
+	scaled, err := barcode.Scale(code, width*scale, height*scale)
+	if err != nil {
+		return fmt.Errorf("ошибка при масштабировании штрихкода: %v", err)
+	}
+	return png.Encode(w, scaled)
+}
+
+// WriteSVG записывает штрихкод в формате SVG, по одному прямоугольнику на серию тёмных модулей
+func WriteSVG(w io.Writer, code barcode.Barcode, scale int) error {
+	width, height := moduleSize(code)
+	bounds := code.Bounds()
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		width*scale, height*scale, width, height)
+	buf.WriteString(`<rect width="100%" height="100%" fill="#fff"/>`)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; {
+			if !isDark(code, bounds.Min.X+x, bounds.Min.Y+y) {
+				x++
+				continue
+			}
+			start := x
+			for x < width && isDark(code, bounds.Min.X+x, bounds.Min.Y+y) {
+				x++
+			}
+			rectHeight := 1
+			// Одномерный штрихкод имеет высоту в один модуль, растягиваем его на всю высоту
+			if code.Metadata().Dimensions == 1 {
+				rectHeight = height
+			}
+			fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d"/>`, start, y, x-start, rectHeight)
+		}
+		if code.Metadata().Dimensions == 1 {
+			break
+		}
+	}
+
+	buf.WriteString(`</svg>`)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// WriteSheetPDF формирует лист наклеек формата A4 с QR-кодами и подписями;
+// подписи выводятся шрифтом UTF-8, поэтому модели и серийные номера могут быть кириллицей
+func WriteSheetPDF(w io.Writer, labels []Label) error {
+	const (
+		columns   = 3
+		rows      = 8
+		marginX   = 7.0
+		marginY   = 10.0
+		cellW     = 65.0
+		cellH     = 34.0
+		codeSize  = 28.0
+		textShift = codeSize + 4
+	)
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", gobold.TTF)
+	pdf.SetFont(fontFamily, "", 8)
+
+	for i, label := range labels {
+		if i%(columns*rows) == 0 {
+			pdf.AddPage()
+		}
+		pos := i % (columns * rows)
+		x := marginX + float64(pos%columns)*cellW
+		y := marginY + float64(pos/columns)*cellH
+
+		code, err := Encode(label.Tag, QR)
+		if err != nil {
+			return fmt.Errorf("ошибка при кодировании наклейки %s: %v", label.Tag, err)
+		}
+		var img bytes.Buffer
+		if err := WritePNG(&img, code, 4); err != nil {
+			return err
+		}
+
+		name := fmt.Sprintf("label-%d", i)
+		options := fpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader(name, options, &img)
+		pdf.ImageOptions(name, x+2, y+3, codeSize, codeSize, false, options, 0, "")
+
+		pdf.SetXY(x+textShift, y+6)
+		pdf.SetFont(fontFamily, "B", 10)
+		pdf.CellFormat(cellW-textShift-2, 5, label.Tag, "", 2, "L", false, 0, "")
+		pdf.SetFont(fontFamily, "", 8)
+		pdf.SetX(x + textShift)
+		pdf.MultiCell(cellW-textShift-2, 4, label.Title, "", "L", false)
+		pdf.SetX(x + textShift)
+		pdf.MultiCell(cellW-textShift-2, 4, label.Subtitle, "", "L", false)
+	}
+
+	if len(labels) == 0 {
+		pdf.AddPage()
+	}
+
+	return pdf.Output(w)
+}
+
+// moduleSize возвращает размер штрихкода в модулях
+func moduleSize(code barcode.Barcode) (int, int) {
+	bounds := code.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	// Code128 кодируется в полосу высотой 1 модуль, задаём ей разумную высоту
+	if code.Metadata().Dimensions == 1 {
+		height = width / 4
+		if height < 10 {
+			height = 10
+		}
+	}
+	return width, height
+}
+
+// isDark проверяет, является ли модуль штрихкода тёмным
+func isDark(code barcode.Barcode, x, y int) bool {
+	r, _, _, _ := code.At(x, y).RGBA()
+	return r < 0x8000
+}
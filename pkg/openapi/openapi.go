@@ -0,0 +1,33 @@
+// Package openapi содержит описание API в формате OpenAPI 3 и страницу интерактивной документации.
+// Описание ведётся вручную в openapi.json; тест в tests/openapi_test.go сверяет его с маршрутами.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+)
+
+// Spec документ OpenAPI 3 в формате JSON
+//
+//go:embed openapi.json
+var Spec []byte
+
+// DocsPage самодостаточная HTML-страница документации, которая загружает Spec с сервера
+//
+//go:embed docs.html
+var DocsPage []byte
+
+// Document минимальная структура документа OpenAPI, достаточная для сверки с маршрутами
+type Document struct {
+	OpenAPI string                                `json:"openapi"`
+	Paths   map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+// Parse разбирает встроенный документ
+func Parse() (*Document, error) {
+	var document Document
+	if err := json.Unmarshal(Spec, &document); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
@@ -0,0 +1,220 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchLimit горизонт поиска следующего срока по выражению cron; выражения вроде "0 0 30 2 *"
+// не срабатывают никогда, и для них Next возвращает нулевое время
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+// Schedule расписание обслуживания, вычисляющее следующий срок после заданного момента
+type Schedule interface {
+	// Next возвращает первый срок строго после after или нулевое время, если срока нет
+	Next(after time.Time) time.Time
+}
+
+// aliases сокращения для распространённых выражений cron
+var aliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+// Parse разбирает расписание: интервал вида "@every 6mo" (единицы h, d, w, mo, y),
+// одно из сокращений @hourly, @daily, @weekly, @monthly, @yearly или выражение cron
+// из пяти полей "минута час день месяц день_недели" со списками, диапазонами и шагами
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		return parseInterval(strings.TrimSpace(rest))
+	}
+	if expr, ok := aliases[strings.ToLower(spec)]; ok {
+		spec = expr
+	}
+	return parseCron(spec)
+}
+
+// Interval расписание с постоянным интервалом; месяцы и годы отсчитываются по календарю
+type Interval struct {
+	Hours, Days, Months int
+}
+
+// Next возвращает момент через один интервал после after
+func (i Interval) Next(after time.Time) time.Time {
+	return after.AddDate(0, i.Months, i.Days).Add(time.Duration(i.Hours) * time.Hour)
+}
+
+// parseInterval разбирает интервал вида "6mo", "180d", "2w", "12h" или "1y"
+func parseInterval(value string) (Schedule, error) {
+	end := 0
+	for end < len(value) && value[end] >= '0' && value[end] <= '9' {
+		end++
+	}
+	n, err := strconv.Atoi(value[:end])
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("некорректный интервал %q: ожидается положительное число с единицей h, d, w, mo или y", value)
+	}
+
+	switch value[end:] {
+	case "h":
+		return Interval{Hours: n}, nil
+	case "d":
+		return Interval{Days: n}, nil
+	case "w":
+		return Interval{Days: 7 * n}, nil
+	case "mo":
+		return Interval{Months: n}, nil
+	case "y":
+		return Interval{Months: 12 * n}, nil
+	}
+	return nil, fmt.Errorf("некорректная единица интервала %q: ожидается h, d, w, mo или y", value[end:])
+}
+
+// field описание поля выражения cron
+type field struct {
+	name     string
+	min, max int
+}
+
+// cronFields поля выражения cron по порядку
+var cronFields = []field{
+	{"минута", 0, 59},
+	{"час", 0, 23},
+	{"день месяца", 1, 31},
+	{"месяц", 1, 12},
+	{"день недели", 0, 7},
+}
+
+// Cron расписание по выражению cron; каждое поле хранится как битовая маска допустимых значений
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// domAny и dowAny отмечают поля дня, заданные звёздочкой: если ограничены оба,
+	// срок наступает при совпадении любого из них, как в классическом cron
+	domAny, dowAny bool
+}
+
+// parseCron разбирает выражение cron из пяти полей
+func parseCron(spec string) (Schedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("некорректное расписание %q: ожидается @every <интервал> или выражение cron из 5 полей", spec)
+	}
+
+	masks := make([]uint64, len(parts))
+	for i, part := range parts {
+		mask, err := parseField(part, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		masks[i] = mask
+	}
+
+	// Воскресенье допускается как 0 и как 7
+	dow := masks[4]
+	if dow&(1<<7) != 0 {
+		dow |= 1
+	}
+	return &Cron{
+		minute: masks[0],
+		hour:   masks[1],
+		dom:    masks[2],
+		month:  masks[3],
+		dow:    dow,
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}, nil
+}
+
+// parseField разбирает поле cron: "*", число, диапазон "a-b", шаг "*/s" или "a-b/s" и их списки через запятую
+func parseField(value string, f field) (uint64, error) {
+	var mask uint64
+	for _, item := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("некорректный шаг %q в поле «%s»", stepPart, f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseValue(lowPart, f); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = parseValue(highPart, f); err != nil {
+					return 0, err
+				}
+				if high < low {
+					return 0, fmt.Errorf("некорректный диапазон %q в поле «%s»", rangePart, f.name)
+				}
+			} else if hasStep {
+				// "a/s" означает значения от a до конца поля с шагом s
+				high = f.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
+
+// parseValue разбирает число поля cron с проверкой допустимого диапазона
+func parseValue(value string, f field) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("некорректное значение %q в поле «%s»: ожидается число от %d до %d", value, f.name, f.min, f.max)
+	}
+	return n, nil
+}
+
+// Next возвращает первую минуту после after, подходящую под выражение, в часовом поясе after
+func (c *Cron) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchDay проверяет день месяца и день недели
+func (c *Cron) matchDay(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,69 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// MaxBodyBytes максимальный размер тела JSON-запроса
+const MaxBodyBytes = 1 << 20
+
+// DecodeJSON читает тело запроса в dst и проверяет его по правилам validate.
+// Неизвестные поля, данные после JSON-значения, пустое тело и тело больше MaxBodyBytes отклоняются.
+// Ошибки разбора и проверки возвращаются как *Error.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decodeJSON(w, r, dst, false)
+}
+
+// DecodeOptionalJSON работает как DecodeJSON, но допускает пустое тело запроса
+func DecodeOptionalJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decodeJSON(w, r, dst, true)
+}
+
+// decodeJSON разбирает и проверяет тело запроса
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}, optional bool) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		if !(optional && errors.Is(err, io.EOF)) {
+			return decodeError(err)
+		}
+	} else if err := decoder.Decode(&json.RawMessage{}); !errors.Is(err, io.EOF) {
+		return New("body", CodeMalformed, "после JSON-значения не должно быть других данных")
+	}
+
+	return Struct(dst)
+}
+
+// decodeError преобразует ошибку разбора JSON в ошибку проверки
+func decodeError(err error) error {
+	var (
+		maxBytesErr *http.MaxBytesError
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return New("body", CodeTooLarge, "размер тела запроса не должен превышать %d байт", maxBytesErr.Limit)
+	case errors.Is(err, io.EOF):
+		return New("body", CodeRequired, "тело запроса пустое")
+	case errors.As(err, &syntaxErr):
+		return New("body", CodeMalformed, "некорректный JSON (позиция %d)", syntaxErr.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return New("body", CodeMalformed, "JSON оборван")
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		return New(field, CodeInvalidType, "ожидается значение типа %s", typeErr.Type)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return New(field, CodeUnknownField, "неизвестное поле")
+	}
+	return New("body", CodeInvalid, "%v", err)
+}
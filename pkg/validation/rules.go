@@ -0,0 +1,193 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// timeType тип time.Time, который проверяется как значение, а не как вложенная структура
+var timeType = reflect.TypeOf(time.Time{})
+
+// Struct проверяет поля структуры по правилам тега validate и возвращает *Error или nil.
+// Поддерживаются правила required, min=N, max=N, oneof=a b c и future (для времени).
+// Имена полей в ошибках берутся из тега json; вложенные структуры и срезы структур проверяются рекурсивно.
+func Struct(v interface{}) error {
+	e := &Error{}
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() == reflect.Struct {
+		checkStruct(e, "", value)
+	}
+	return e.ErrOrNil()
+}
+
+// checkStruct проверяет экспортируемые поля структуры
+func checkStruct(e *Error, prefix string, value reflect.Value) {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		fieldValue := value.Field(i)
+		if rules := field.Tag.Get("validate"); rules != "" && !checkRules(e, name, fieldValue, rules) {
+			continue
+		}
+		dive(e, name, fieldValue)
+	}
+}
+
+// dive проверяет вложенные структуры и элементы срезов структур
+func dive(e *Error, path string, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			dive(e, path, value.Elem())
+		}
+	case reflect.Struct:
+		if value.Type() != timeType {
+			checkStruct(e, path, value)
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			dive(e, fmt.Sprintf("%s[%d]", path, i), value.Index(i))
+		}
+	}
+}
+
+// checkRules применяет правила поля по порядку и останавливается на первой ошибке.
+// Возвращает false, если поле не прошло проверку.
+func checkRules(e *Error, path string, value reflect.Value, rules string) bool {
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		if name == "required" {
+			if isEmpty(value) {
+				e.Add(path, CodeRequired, "обязательное поле")
+				return false
+			}
+			continue
+		}
+
+		// Необязательное незаданное поле остальными правилами не проверяется
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return true
+			}
+			value = value.Elem()
+		}
+
+		var ok bool
+		switch name {
+		case "max":
+			ok = checkBound(e, path, value, mustInt(param), false)
+		case "min":
+			ok = checkBound(e, path, value, mustInt(param), true)
+		case "oneof":
+			allowed := strings.Fields(param)
+			ok = value.Kind() != reflect.String || value.String() == "" || contains(allowed, value.String())
+			if !ok {
+				e.Add(path, CodeNotAllowed, "допустимые значения: %s", strings.Join(allowed, ", "))
+			}
+		case "future":
+			t, isTime := value.Interface().(time.Time)
+			ok = !isTime || t.IsZero() || t.After(time.Now())
+			if !ok {
+				e.Add(path, CodeOutOfRange, "должно быть в будущем")
+			}
+		default:
+			panic(fmt.Sprintf("validation: неизвестное правило %q", name))
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// checkBound проверяет длину строки или среза либо значение числа относительно границы
+func checkBound(e *Error, path string, value reflect.Value, bound int, lower bool) bool {
+	var n int64
+	switch value.Kind() {
+	case reflect.String:
+		n = int64(utf8.RuneCountInString(value.String()))
+		if lower && n < int64(bound) {
+			e.Add(path, CodeTooShort, "длина должна быть не меньше %d символов", bound)
+			return false
+		}
+		if !lower && n > int64(bound) {
+			e.Add(path, CodeTooLong, "длина не должна превышать %d символов", bound)
+			return false
+		}
+	case reflect.Slice:
+		n = int64(value.Len())
+		if lower && n < int64(bound) {
+			e.Add(path, CodeTooShort, "должно содержать не меньше %d элементов", bound)
+			return false
+		}
+		if !lower && n > int64(bound) {
+			e.Add(path, CodeTooLong, "должно содержать не больше %d элементов", bound)
+			return false
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = value.Int()
+		if (lower && n < int64(bound)) || (!lower && n > int64(bound)) {
+			if lower {
+				e.Add(path, CodeOutOfRange, "должно быть не меньше %d", bound)
+			} else {
+				e.Add(path, CodeOutOfRange, "должно быть не больше %d", bound)
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// isEmpty сообщает, что значение не задано: пустая строка из пробелов, nil, пустой срез или ноль
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
+
+// jsonName возвращает имя поля в JSON
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// mustInt разбирает числовой параметр правила; ошибка в теге — ошибка программиста
+func mustInt(param string) int {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		panic(fmt.Sprintf("validation: некорректный параметр правила %q", param))
+	}
+	return n
+}
+
+// contains сообщает, входит ли значение в список
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+// Package validation проверяет входные данные запросов и описывает ошибки на уровне отдельных полей.
+// Правила задаются декларативно тегом validate у полей структуры, например `validate:"required,max=255"`.
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Коды ошибок проверки полей
+const (
+	CodeRequired     = "required"
+	CodeTooLong      = "too_long"
+	CodeTooShort     = "too_short"
+	CodeOutOfRange   = "out_of_range"
+	CodeNotAllowed   = "not_allowed"
+	CodeInvalid      = "invalid"
+	CodeInvalidType  = "invalid_type"
+	CodeUnknownField = "unknown_field"
+	CodeDuplicate    = "duplicate"
+	CodeMalformed    = "malformed"
+	CodeTooLarge     = "too_large"
+)
+
+// FieldError описывает ошибку проверки одного поля
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error набор ошибок проверки входных данных
+type Error struct {
+	Fields []FieldError `json:"errors"`
+}
+
+// Error возвращает текст ошибки со всеми полями
+func (e *Error) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return "некорректные данные: " + strings.Join(messages, "; ")
+}
+
+// Add добавляет ошибку поля
+func (e *Error) Add(field, code, format string, args ...interface{}) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// Merge добавляет ошибки другого набора, если err является ошибкой проверки
+func (e *Error) Merge(err error) {
+	if other, ok := err.(*Error); ok {
+		e.Fields = append(e.Fields, other.Fields...)
+	}
+}
+
+// ErrOrNil возвращает nil, если ошибок нет
+func (e *Error) ErrOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// RequireString проверяет, что строка не пустая и не длиннее max символов
+func (e *Error) RequireString(field, value string, max int) {
+	if strings.TrimSpace(value) == "" {
+		e.Add(field, CodeRequired, "обязательное поле")
+		return
+	}
+	e.MaxLength(field, value, max)
+}
+
+// MaxLength проверяет, что строка не длиннее max символов
+func (e *Error) MaxLength(field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		e.Add(field, CodeTooLong, "длина не должна превышать %d символов", max)
+	}
+}
+
+// New возвращает ошибку проверки с одним полем
+func New(field, code, format string, args ...interface{}) *Error {
+	e := &Error{}
+	e.Add(field, code, format, args...)
+	return e
+}
@@ -4,40 +4,230 @@ import (
 	"database/sql"
 	"inva/handlers"
 	"inva/services"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
+// APIv1Prefix префикс маршрутов первой версии API
+const APIv1Prefix = "/api/v1"
+
+// Сроки вывода из эксплуатации путей без версии
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunset       = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// v1Handlers обработчики первой версии API
+type v1Handlers struct {
+	employee     *handlers.EmployeeHandler
+	equipment    *handlers.EquipmentHandler
+	label        *handlers.LabelHandler
+	scan         *handlers.ScanHandler
+	reservation  *handlers.ReservationHandler
+	imports      *handlers.ImportHandler
+	export       *handlers.ExportHandler
+	offboarding  *handlers.OffboardingHandler
+	kit          *handlers.KitHandler
+	policy       *handlers.PolicyHandler
+	request      *handlers.RequestHandler
+	maintenance  *handlers.MaintenanceHandler
+	schedule     *handlers.MaintenanceScheduleHandler
+	depreciation *handlers.DepreciationHandler
+	purchase     *handlers.PurchaseHandler
+	disposal     *handlers.DisposalHandler
+	docs         *handlers.DocsHandler
+}
+
 // SetupRoutes конфигурирует маршруты и обработчики
 func SetupRoutes(r *mux.Router, db *sql.DB) {
 	// Создание сервисов
 	employeeService := services.NewEmployeeService(db)
 	equipmentService := services.NewEquipmentService(db)
+	scanService := services.NewScanService(equipmentService, employeeService)
+	reservationService := services.NewReservationService(db)
+	importService := services.NewImportService(db)
+	exportService := services.NewExportService(db)
+	idempotencyService := services.NewIdempotencyService(db)
+	offboardingService := services.NewOffboardingService(db)
+	kitService := services.NewKitService(db)
+	policyService := services.NewPolicyService(db)
+	requestService := services.NewRequestService(db)
+	maintenanceService := services.NewMaintenanceService(db)
+	scheduleService := services.NewMaintenanceScheduleService(db)
+	depreciationService := services.NewDepreciationService(db)
+	vendorService := services.NewVendorService(db)
+	purchaseOrderService := services.NewPurchaseOrderService(db)
+	disposalService := services.NewDisposalService(db)
 
 	// Создание обработчиков с передачей сервисов
-	employeeHandler := handlers.NewEmployeeHandler(employeeService)
-	equipmentHandler := handlers.NewEquipmentHandler(equipmentService)
+	v1 := &v1Handlers{
+		employee:     handlers.NewEmployeeHandler(employeeService),
+		equipment:    handlers.NewEquipmentHandler(equipmentService),
+		label:        handlers.NewLabelHandler(equipmentService),
+		scan:         handlers.NewScanHandler(scanService),
+		reservation:  handlers.NewReservationHandler(reservationService, equipmentService),
+		imports:      handlers.NewImportHandler(importService),
+		export:       handlers.NewExportHandler(exportService),
+		offboarding:  handlers.NewOffboardingHandler(offboardingService),
+		kit:          handlers.NewKitHandler(kitService),
+		policy:       handlers.NewPolicyHandler(policyService),
+		request:      handlers.NewRequestHandler(requestService),
+		maintenance:  handlers.NewMaintenanceHandler(maintenanceService),
+		schedule:     handlers.NewMaintenanceScheduleHandler(scheduleService),
+		depreciation: handlers.NewDepreciationHandler(depreciationService),
+		purchase:     handlers.NewPurchaseHandler(vendorService, purchaseOrderService),
+		disposal:     handlers.NewDisposalHandler(disposalService),
+		docs:         handlers.NewDocsHandler(),
+	}
+
+	// Повторные запросы с заголовком Idempotency-Key получают исходный ответ
+	r.Use(handlers.Idempotency(idempotencyService, APIv1Prefix))
 
+	// Каждая версия API монтируется своим подмаршрутизатором, поэтому следующая версия
+	// регистрируется рядом с префиксом /api/v2 и собственным набором обработчиков
+	registerV1(r.PathPrefix(APIv1Prefix).Subrouter(), v1)
+
+	// Пути без версии остаются псевдонимами v1 до даты Sunset
+	legacy := r.NewRoute().Subrouter()
+	legacy.Use(handlers.Deprecated(legacyDeprecatedAt, legacySunset, APIv1Prefix))
+	registerV1(legacy, v1)
+}
+
+// registerV1 регистрирует маршруты первой версии API
+func registerV1(r *mux.Router, h *v1Handlers) {
 	// Маршруты для сотрудников
-	r.HandleFunc("/employees", employeeHandler.GetAllEmployeesHandler).Methods("GET")
-	r.HandleFunc("/employees", employeeHandler.CreateEmployeeHandler).Methods("POST")
-	r.HandleFunc("/employees/{id:[0-9]+}", employeeHandler.GetEmployeeHandler).Methods("GET")
-	r.HandleFunc("/employees/{id:[0-9]+}", employeeHandler.UpdateEmployeeHandler).Methods("PUT")
-	r.HandleFunc("/employees/{id:[0-9]+}", employeeHandler.DeleteEmployeeHandler).Methods("DELETE")
+	r.HandleFunc("/employees", h.employee.GetAllEmployeesHandler).Methods("GET")
+	r.HandleFunc("/employees", h.employee.CreateEmployeeHandler).Methods("POST")
+	r.HandleFunc("/employees/{id:[0-9]+}", h.employee.GetEmployeeHandler).Methods("GET")
+	r.HandleFunc("/employees/{id:[0-9]+}", h.employee.UpdateEmployeeHandler).Methods("PUT")
+	r.HandleFunc("/employees/{id:[0-9]+}", h.employee.PatchEmployeeHandler).Methods("PATCH")
+	r.HandleFunc("/employees/{id:[0-9]+}", h.employee.DeleteEmployeeHandler).Methods("DELETE")
+
+	// Увольнение сотрудника и возврат его оборудования
+	r.HandleFunc("/employees/{id:[0-9]+}/offboard", h.offboarding.OffboardEmployeeHandler).Methods("POST")
+	r.HandleFunc("/employees/{id:[0-9]+}/offboarding", h.offboarding.GetOffboardingHandler).Methods("GET")
+	r.HandleFunc("/employees/{id:[0-9]+}/deactivate", h.offboarding.DeactivateEmployeeHandler).Methods("POST")
+	r.HandleFunc("/return-tasks/{id:[0-9]+}/write-off", h.offboarding.WriteOffHandler).Methods("POST")
+
+	// Шаблоны комплектов и их выдача сотруднику
+	r.HandleFunc("/kits", h.kit.GetAllKitsHandler).Methods("GET")
+	r.HandleFunc("/kits", h.kit.CreateKitHandler).Methods("POST")
+	r.HandleFunc("/kits/{kit}", h.kit.GetKitHandler).Methods("GET")
+	r.HandleFunc("/kits/{kit}", h.kit.DeleteKitHandler).Methods("DELETE")
+	r.HandleFunc("/employees/{id:[0-9]+}/kits/{kit}/assign", h.kit.AssignKitHandler).Methods("POST")
+
+	// Политики выдачи и журнал исключений из них
+	r.HandleFunc("/policies", h.policy.GetAllPoliciesHandler).Methods("GET")
+	r.HandleFunc("/policies", h.policy.CreatePolicyHandler).Methods("POST")
+	r.HandleFunc("/policies/{id:[0-9]+}", h.policy.DeletePolicyHandler).Methods("DELETE")
+	r.HandleFunc("/policies/overrides", h.policy.GetOverridesHandler).Methods("GET")
+
+	// Заявки сотрудников на оборудование: подача, решение руководителя и выдача
+	r.HandleFunc("/requests", h.request.GetRequestsHandler).Methods("GET")
+	r.HandleFunc("/requests", h.request.CreateRequestHandler).Methods("POST")
+	r.HandleFunc("/requests/{id:[0-9]+}", h.request.GetRequestHandler).Methods("GET")
+	r.HandleFunc("/requests/{id:[0-9]+}/approve", h.request.ApproveRequestHandler).Methods("POST")
+	r.HandleFunc("/requests/{id:[0-9]+}/reject", h.request.RejectRequestHandler).Methods("POST")
+	r.HandleFunc("/requests/{id:[0-9]+}/cancel", h.request.CancelRequestHandler).Methods("POST")
+	r.HandleFunc("/requests/{id:[0-9]+}/fulfill", h.request.FulfillRequestHandler).Methods("POST")
 
 	// Маршруты для оборудования
-	r.HandleFunc("/equipment", equipmentHandler.GetAllEquipmentHandler).Methods("GET")
-	r.HandleFunc("/equipment", equipmentHandler.CreateEquipmentHandler).Methods("POST")
-	r.HandleFunc("/equipment/{id:[0-9]+}", equipmentHandler.GetEquipmentHandler).Methods("GET")
-	r.HandleFunc("/equipment/{id:[0-9]+}", equipmentHandler.UpdateEquipmentHandler).Methods("PUT")
-	r.HandleFunc("/equipment/{id:[0-9]+}", equipmentHandler.DeleteEquipmentHandler).Methods("DELETE")
+	r.HandleFunc("/equipment", h.equipment.GetAllEquipmentHandler).Methods("GET")
+	r.HandleFunc("/equipment", h.equipment.CreateEquipmentHandler).Methods("POST")
+	r.HandleFunc("/equipment/{id:[0-9]+}", h.equipment.GetEquipmentHandler).Methods("GET")
+	r.HandleFunc("/equipment/{id:[0-9]+}", h.equipment.UpdateEquipmentHandler).Methods("PUT")
+	r.HandleFunc("/equipment/{id:[0-9]+}", h.equipment.PatchEquipmentHandler).Methods("PATCH")
+	r.HandleFunc("/equipment/{id:[0-9]+}", h.equipment.DeleteEquipmentHandler).Methods("DELETE")
 
 	// Назначение оборудования пользователю
-	r.HandleFunc("/equipment/{equipment_id:[0-9]+}/assign/user/{user_id:[0-9]+}", equipmentHandler.AssignEquipmentToUser).Methods("POST")
+	r.HandleFunc("/equipment/{equipment_id:[0-9]+}/assign/user/{user_id:[0-9]+}", h.equipment.AssignEquipmentToUser).Methods("POST")
 
 	// Возврат оборудования
-	r.HandleFunc("/equipment/{id:[0-9]+}/return", equipmentHandler.ReturnEquipmentHandler).Methods("PUT")
+	r.HandleFunc("/equipment/{id:[0-9]+}/return", h.equipment.ReturnEquipmentHandler).Methods("PUT")
+
+	// Передача оборудования другому сотруднику
+	r.HandleFunc("/equipment/{id:[0-9]+}/transfer", h.equipment.TransferEquipmentHandler).Methods("POST")
+
+	// Перемещение оборудования
+	r.HandleFunc("/equipment/{id:[0-9]+}/location", h.equipment.MoveEquipmentHandler).Methods("PUT")
+
+	// Пакетные операции над оборудованием
+	r.HandleFunc("/equipment/bulk", h.equipment.BulkEquipmentHandler).Methods("POST")
 
 	// Детали оборудования
-	r.HandleFunc("/equipment/{id:[0-9]+}/details", equipmentHandler.GetEquipmentDetailsHandler).Methods("GET")
+	r.HandleFunc("/equipment/{id:[0-9]+}/details", h.equipment.GetEquipmentDetailsHandler).Methods("GET")
+
+	// История выдачи оборудования
+	r.HandleFunc("/equipment/{id:[0-9]+}/history", h.equipment.GetEquipmentHistoryHandler).Methods("GET")
+
+	// Просроченные выдачи
+	r.HandleFunc("/equipment/overdue", h.equipment.GetOverdueEquipmentHandler).Methods("GET")
+
+	// Оборудование с истекающей гарантией
+	r.HandleFunc("/equipment/warranty-expiring", h.equipment.GetWarrantyExpiringHandler).Methods("GET")
+
+	// Ремонт и обслуживание оборудования
+	r.HandleFunc("/equipment/{id:[0-9]+}/maintenance", h.maintenance.GetEquipmentMaintenanceHandler).Methods("GET")
+	r.HandleFunc("/equipment/{id:[0-9]+}/maintenance", h.maintenance.OpenMaintenanceHandler).Methods("POST")
+	r.HandleFunc("/maintenance/open", h.maintenance.GetOpenMaintenanceHandler).Methods("GET")
+	r.HandleFunc("/maintenance/{id:[0-9]+}/close", h.maintenance.CloseMaintenanceHandler).Methods("POST")
+
+	// Плановое обслуживание по расписаниям
+	r.HandleFunc("/maintenance/schedules", h.schedule.GetAllSchedulesHandler).Methods("GET")
+	r.HandleFunc("/maintenance/schedules", h.schedule.CreateScheduleHandler).Methods("POST")
+	r.HandleFunc("/maintenance/schedules/{id:[0-9]+}", h.schedule.DeleteScheduleHandler).Methods("DELETE")
+	r.HandleFunc("/maintenance/tasks", h.schedule.GetTasksHandler).Methods("GET")
+	r.HandleFunc("/maintenance/tasks/{id:[0-9]+}/complete", h.schedule.CompleteTaskHandler).Methods("POST")
+
+	// Поставщики, заказы и приёмка оборудования по заказу
+	r.HandleFunc("/vendors", h.purchase.GetAllVendorsHandler).Methods("GET")
+	r.HandleFunc("/vendors", h.purchase.CreateVendorHandler).Methods("POST")
+	r.HandleFunc("/vendors/{id:[0-9]+}", h.purchase.GetVendorHandler).Methods("GET")
+	r.HandleFunc("/purchase-orders", h.purchase.GetPurchaseOrdersHandler).Methods("GET")
+	r.HandleFunc("/purchase-orders", h.purchase.CreatePurchaseOrderHandler).Methods("POST")
+	r.HandleFunc("/purchase-orders/{id:[0-9]+}", h.purchase.GetPurchaseOrderHandler).Methods("GET")
+	r.HandleFunc("/purchase-orders/{id:[0-9]+}/lines/{line_id:[0-9]+}/receive", h.purchase.ReceiveLineHandler).Methods("POST")
+
+	// Утилизация оборудования: заявка, подтверждение очистки данных, решение и акт
+	r.HandleFunc("/equipment/{id:[0-9]+}/disposal", h.disposal.RequestDisposalHandler).Methods("POST")
+	r.HandleFunc("/disposals", h.disposal.GetDisposalsHandler).Methods("GET")
+	r.HandleFunc("/disposals/{id:[0-9]+}", h.disposal.GetDisposalHandler).Methods("GET")
+	r.HandleFunc("/disposals/{id:[0-9]+}/wipe", h.disposal.ConfirmDataWipeHandler).Methods("POST")
+	r.HandleFunc("/disposals/{id:[0-9]+}/approve", h.disposal.ApproveDisposalHandler).Methods("POST")
+	r.HandleFunc("/disposals/{id:[0-9]+}/reject", h.disposal.RejectDisposalHandler).Methods("POST")
+	r.HandleFunc("/disposals/{id:[0-9]+}/certificate", h.disposal.GetCertificateHandler).Methods("GET")
+
+	// Амортизация и оценка балансовой стоимости
+	r.HandleFunc("/depreciation/rules", h.depreciation.GetAllRulesHandler).Methods("GET")
+	r.HandleFunc("/depreciation/rules/{category}", h.depreciation.SetRuleHandler).Methods("PUT")
+	r.HandleFunc("/depreciation/rules/{category}", h.depreciation.DeleteRuleHandler).Methods("DELETE")
+	r.HandleFunc("/reports/valuation", h.depreciation.GetValuationReportHandler).Methods("GET")
+
+	// Инвентарные наклейки
+	r.HandleFunc("/equipment/{id:[0-9]+}/label", h.label.GetEquipmentLabelHandler).Methods("GET")
+	r.HandleFunc("/equipment/labels", h.label.GetEquipmentLabelSheetHandler).Methods("GET")
+
+	// Выдача и возврат по сканированию
+	r.HandleFunc("/scan/checkout", h.scan.CheckoutHandler).Methods("POST")
+	r.HandleFunc("/scan/checkin", h.scan.CheckinHandler).Methods("POST")
+
+	// Бронирование оборудования
+	r.HandleFunc("/equipment/{id:[0-9]+}/reservations", h.reservation.ListReservationsHandler).Methods("GET")
+	r.HandleFunc("/equipment/{id:[0-9]+}/reservations", h.reservation.CreateReservationHandler).Methods("POST")
+	r.HandleFunc("/equipment/{id:[0-9]+}/reservations.ics", h.reservation.ReservationsCalendarHandler).Methods("GET")
+	r.HandleFunc("/reservations/{id:[0-9]+}", h.reservation.CancelReservationHandler).Methods("DELETE")
+
+	// Массовая загрузка из CSV
+	r.HandleFunc("/import/equipment", h.imports.ImportEquipmentHandler).Methods("POST")
+	r.HandleFunc("/import/employees", h.imports.ImportEmployeesHandler).Methods("POST")
+
+	// Выгрузка в CSV, XLSX и JSON Lines
+	r.HandleFunc("/export/equipment", h.export.ExportEquipmentHandler).Methods("GET")
+	r.HandleFunc("/export/employees", h.export.ExportEmployeesHandler).Methods("GET")
+	r.HandleFunc("/export/history", h.export.ExportHistoryHandler).Methods("GET")
+
+	// Описание API и документация
+	r.HandleFunc("/openapi.json", h.docs.OpenAPIHandler).Methods("GET")
+	r.HandleFunc("/docs", h.docs.DocsPageHandler).Methods("GET")
 }
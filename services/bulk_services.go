@@ -0,0 +1,154 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"inva/pkg/validation"
+	"time"
+)
+
+// Операции пакетной обработки оборудования
+const (
+	BulkUpdateStatus = "update_status"
+	BulkAssign       = "assign"
+	BulkReturn       = "return"
+	BulkDelete       = "delete"
+	BulkMoveLocation = "move_location"
+)
+
+// Статусы результата операции пакетной обработки
+const (
+	BulkResultApplied    = "applied"
+	BulkResultFailed     = "failed"
+	BulkResultRolledBack = "rolled_back"
+	BulkResultSkipped    = "skipped"
+)
+
+// MaxBulkOperations максимальное число операций в одном пакете
+const MaxBulkOperations = 500
+
+// BulkOperation описывает одну операцию пакета
+type BulkOperation struct {
+	Op       string     `json:"op"`
+	ID       int        `json:"id"`
+	Status   string     `json:"status,omitempty"`
+	UserID   int        `json:"user_id,omitempty"`
+	DueAt    *time.Time `json:"due_at,omitempty"`
+	Location string     `json:"location,omitempty"`
+}
+
+// BulkResult представляет результат операции пакета
+type BulkResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	ID     int    `json:"id"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ExecuteBulk выполняет операции пакета в одной транзакции теми же функциями, что и одиночные запросы.
+// При ошибке любой операции транзакция откатывается: уже выполненные операции помечаются rolled_back,
+// оставшиеся — skipped, а возвращаемая ошибка соответствует первой неудачной операции.
+func (s *EquipmentService) ExecuteBulk(operations []BulkOperation) ([]BulkResult, error) {
+	results := make([]BulkResult, len(operations))
+	for i, operation := range operations {
+		results[i] = BulkResult{Index: i, Op: operation.Op, ID: operation.ID}
+	}
+
+	if len(operations) == 0 || len(operations) > MaxBulkOperations {
+		return results, validation.New("operations", validation.CodeOutOfRange,
+			"пакет должен содержать от 1 до %d операций", MaxBulkOperations)
+	}
+
+	// Проверяем состав пакета до начала транзакции
+	v := &ValidationError{}
+	for i, operation := range operations {
+		if fieldErr := validateBulkOperation(operation); fieldErr != nil {
+			results[i].Result = BulkResultFailed
+			results[i].Error = fieldErr.Message
+			v.Add(fmt.Sprintf("operations[%d].%s", i, fieldErr.Field), fieldErr.Code, "%s", fieldErr.Message)
+		}
+	}
+	if err := v.ErrOrNil(); err != nil {
+		for i := range results {
+			if results[i].Result == "" {
+				results[i].Result = BulkResultSkipped
+			}
+		}
+		return results, err
+	}
+
+	failed := -1
+	var opErr error
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		for i, operation := range operations {
+			if err := applyBulkOperation(tx, operation); err != nil {
+				failed, opErr = i, err
+				return err
+			}
+			results[i].Result = BulkResultApplied
+		}
+		return nil
+	})
+
+	if failed >= 0 {
+		for i := range results {
+			switch {
+			case i < failed:
+				results[i].Result = BulkResultRolledBack
+			case i == failed:
+				results[i].Result = BulkResultFailed
+				results[i].Error = opErr.Error()
+			default:
+				results[i].Result = BulkResultSkipped
+			}
+		}
+		return results, fmt.Errorf("операция %d (%s, id %d): %w", failed, operations[failed].Op, operations[failed].ID, opErr)
+	}
+	if err != nil {
+		for i := range results {
+			results[i].Result = BulkResultRolledBack
+		}
+		return results, err
+	}
+
+	return results, nil
+}
+
+// validateBulkOperation проверяет, что операция известна и содержит нужные параметры
+func validateBulkOperation(operation BulkOperation) *FieldError {
+	if operation.ID <= 0 {
+		return &FieldError{Field: "id", Code: validation.CodeRequired, Message: "не указан id оборудования"}
+	}
+
+	switch operation.Op {
+	case BulkUpdateStatus, BulkReturn, BulkDelete, BulkMoveLocation:
+	case BulkAssign:
+		if operation.UserID <= 0 {
+			return &FieldError{Field: "user_id", Code: validation.CodeRequired, Message: "не указан user_id"}
+		}
+		if operation.DueAt != nil && !operation.DueAt.After(time.Now()) {
+			return &FieldError{Field: "due_at", Code: validation.CodeOutOfRange, Message: "due_at должен быть в будущем"}
+		}
+	default:
+		return &FieldError{Field: "op", Code: validation.CodeNotAllowed, Message: fmt.Sprintf("неизвестная операция %q", operation.Op)}
+	}
+	return nil
+}
+
+// applyBulkOperation выполняет операцию пакета в транзакции
+func applyBulkOperation(tx *sql.Tx, operation BulkOperation) error {
+	switch operation.Op {
+	case BulkUpdateStatus:
+		return updateEquipmentStatus(tx, operation.ID, operation.Status)
+	case BulkAssign:
+		return assignEquipment(tx, operation.ID, operation.UserID, AssignOptions{DueAt: operation.DueAt})
+	case BulkReturn:
+		return returnEquipment(tx, operation.ID)
+	case BulkDelete:
+		return deleteEquipment(tx, operation.ID)
+	case BulkMoveLocation:
+		return moveEquipment(tx, operation.ID, operation.Location)
+	}
+	return fmt.Errorf("неизвестная операция %q", operation.Op)
+}
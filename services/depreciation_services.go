@@ -0,0 +1,333 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"inva/pkg/validation"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Методы амортизации
+const (
+	// DepreciationStraightLine равномерное списание стоимости за срок полезного использования
+	DepreciationStraightLine = "straight_line"
+	// DepreciationDecliningBalance уменьшаемый остаток с удвоенной нормой: каждый месяц списывается
+	// 2/срок от остаточной стоимости, а в конце срока — равномерно до ликвидационной стоимости
+	DepreciationDecliningBalance = "declining_balance"
+)
+
+// Группировки отчёта об оценке оборудования
+const (
+	ValuationByItem       = "item"
+	ValuationByDepartment = "department"
+	ValuationByCategory   = "category"
+)
+
+// DefaultCurrency валюта стоимости оборудования, если она не указана
+const DefaultCurrency = "USD"
+
+// maxUsefulLifeMonths ограничивает срок полезного использования пятьюдесятью годами
+const maxUsefulLifeMonths = 600
+
+// DepreciationRule метод и срок амортизации для всего оборудования категории.
+// Метод и срок, заданные у самого оборудования, имеют приоритет над правилом категории.
+type DepreciationRule struct {
+	Category         string    `json:"category"`
+	Method           string    `json:"method"`
+	UsefulLifeMonths int       `json:"useful_life_months"`
+	SalvagePercent   float64   `json:"salvage_percent"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// ValuationItem балансовая стоимость единицы оборудования на дату отчёта
+type ValuationItem struct {
+	EquipmentID             int       `json:"equipment_id"`
+	AssetTag                string    `json:"asset_tag"`
+	Model                   string    `json:"model"`
+	Category                string    `json:"category"`
+	Department              string    `json:"department"`
+	Currency                string    `json:"currency"`
+	Method                  string    `json:"method"`
+	UsefulLifeMonths        int       `json:"useful_life_months"`
+	PurchaseDate            time.Time `json:"purchase_date"`
+	AcquisitionCost         float64   `json:"acquisition_cost"`
+	AccumulatedDepreciation float64   `json:"accumulated_depreciation"`
+	BookValue               float64   `json:"book_value"`
+}
+
+// ValuationGroup итоги по отделу или категории; суммы разных валют не складываются
+type ValuationGroup struct {
+	Key                     string  `json:"key"`
+	Currency                string  `json:"currency"`
+	Items                   int     `json:"items"`
+	AcquisitionCost         float64 `json:"acquisition_cost"`
+	AccumulatedDepreciation float64 `json:"accumulated_depreciation"`
+	BookValue               float64 `json:"book_value"`
+}
+
+// ValuationReport отчёт о балансовой стоимости оборудования на дату.
+// Unvalued перечисляет оборудование без стоимости, даты покупки, метода или срока амортизации.
+type ValuationReport struct {
+	AsOf     time.Time        `json:"as_of"`
+	GroupBy  string           `json:"group_by"`
+	Items    []ValuationItem  `json:"items,omitempty"`
+	Groups   []ValuationGroup `json:"groups,omitempty"`
+	Unvalued []int            `json:"unvalued"`
+}
+
+// DepreciationService предоставляет методы для амортизации и оценки оборудования
+type DepreciationService struct {
+	db *sql.DB
+}
+
+// NewDepreciationService создаёт новый экземпляр DepreciationService
+func NewDepreciationService(db *sql.DB) *DepreciationService {
+	return &DepreciationService{db: db}
+}
+
+// ValidateDepreciationRule проверяет правило амортизации перед сохранением
+func ValidateDepreciationRule(rule *DepreciationRule) error {
+	v := &ValidationError{}
+	v.RequireString("category", rule.Category, maxCategoryLength)
+	checkDepreciationMethod(v, "method", rule.Method)
+	checkUsefulLife(v, "useful_life_months", rule.UsefulLifeMonths)
+	if rule.SalvagePercent < 0 || rule.SalvagePercent >= 100 {
+		v.Add("salvage_percent", validation.CodeOutOfRange, "должно быть от 0 до 100, не включая 100")
+	}
+	return v.ErrOrNil()
+}
+
+// checkDepreciationMethod проверяет название метода амортизации
+func checkDepreciationMethod(v *ValidationError, field, method string) {
+	if method != DepreciationStraightLine && method != DepreciationDecliningBalance {
+		v.Add(field, validation.CodeNotAllowed, "допустимые значения: %s, %s", DepreciationStraightLine, DepreciationDecliningBalance)
+	}
+}
+
+// checkUsefulLife проверяет срок полезного использования в месяцах
+func checkUsefulLife(v *ValidationError, field string, months int) {
+	if months < 1 || months > maxUsefulLifeMonths {
+		v.Add(field, validation.CodeOutOfRange, "должно быть от 1 до %d месяцев", maxUsefulLifeMonths)
+	}
+}
+
+// SetRule создаёт или заменяет правило амортизации категории
+func (s *DepreciationService) SetRule(rule *DepreciationRule) (*DepreciationRule, error) {
+	if err := ValidateDepreciationRule(rule); err != nil {
+		return nil, err
+	}
+
+	err := s.db.QueryRow(
+		`INSERT INTO depreciation_rules (category, method, useful_life_months, salvage_percent, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (category) DO UPDATE SET method = EXCLUDED.method, useful_life_months = EXCLUDED.useful_life_months,
+		salvage_percent = EXCLUDED.salvage_percent, updated_at = EXCLUDED.updated_at
+		RETURNING updated_at`,
+		rule.Category, rule.Method, rule.UsefulLifeMonths, rule.SalvagePercent,
+	).Scan(&rule.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при сохранении правила амортизации: %v", err)
+	}
+
+	return rule, nil
+}
+
+// GetAllRules возвращает правила амортизации, упорядоченные по категории
+func (s *DepreciationService) GetAllRules() ([]DepreciationRule, error) {
+	rows, err := s.db.Query("SELECT category, method, useful_life_months, salvage_percent, updated_at FROM depreciation_rules ORDER BY category")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении правил амортизации: %v", err)
+	}
+	defer rows.Close()
+
+	rules := []DepreciationRule{}
+	for rows.Next() {
+		var rule DepreciationRule
+		if err := rows.Scan(&rule.Category, &rule.Method, &rule.UsefulLifeMonths, &rule.SalvagePercent, &rule.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при переборе строк: %v", err)
+	}
+
+	return rules, nil
+}
+
+// DeleteRule удаляет правило амортизации категории
+func (s *DepreciationService) DeleteRule(category string) error {
+	result, err := s.db.Exec("DELETE FROM depreciation_rules WHERE category = $1", category)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении правила амортизации: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке удаления правила амортизации: %v", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w (категория %q)", ErrDepreciationRuleNotFound, category)
+	}
+	return nil
+}
+
+// Depreciate вычисляет накопленную амортизацию и балансовую стоимость через months полных месяцев
+// после покупки. Стоимость не опускается ниже ликвидационной salvage, суммы округляются до копеек.
+// Уменьшаемый остаток переходит на линейное списание, как только оно даёт большую сумму за месяц,
+// чтобы остаток равномерно дошёл до ликвидационной стоимости к концу срока.
+func Depreciate(method string, cost, salvage float64, lifeMonths, months int) (accumulated, bookValue float64) {
+	switch {
+	case months <= 0:
+		bookValue = cost
+	case months >= lifeMonths:
+		bookValue = salvage
+	case method == DepreciationDecliningBalance:
+		bookValue = cost
+		for month := 0; month < months; month++ {
+			declining := bookValue * 2 / float64(lifeMonths)
+			straight := (bookValue - salvage) / float64(lifeMonths-month)
+			bookValue = math.Max(bookValue-math.Max(declining, straight), salvage)
+		}
+	default:
+		bookValue = cost - (cost-salvage)*float64(months)/float64(lifeMonths)
+	}
+	bookValue = roundCents(bookValue)
+	return roundCents(cost - bookValue), bookValue
+}
+
+// roundCents округляет сумму до сотых
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// monthsBetween возвращает число полных месяцев от from до to
+func monthsBetween(from, to time.Time) int {
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if to.Day() < from.Day() {
+		months--
+	}
+	return months
+}
+
+// GetValuationReport рассчитывает балансовую стоимость оборудования, купленного не позже asOf,
+// по отдельным единицам или с итогами по отделу владельца на эту дату либо категории.
+// Оборудование, списанное или утилизированное не позже asOf, не учитывается.
+func (s *DepreciationService) GetValuationReport(asOf time.Time, groupBy string) (*ValuationReport, error) {
+	if groupBy == "" {
+		groupBy = ValuationByItem
+	}
+	if groupBy != ValuationByItem && groupBy != ValuationByDepartment && groupBy != ValuationByCategory {
+		return nil, validation.New("group_by", validation.CodeNotAllowed, "допустимые значения: %s, %s, %s",
+			ValuationByItem, ValuationByDepartment, ValuationByCategory)
+	}
+
+	// Отдел берётся у сотрудника, за которым оборудование числилось на конец дня asOf, а списанное
+	// и утилизированное оборудование остаётся в отчёте, если его вывели из оборота позже этого дня
+	dayAfter := asOf.AddDate(0, 0, 1)
+	rows, err := s.db.Query(
+		`SELECT e.id, e.model, COALESCE(e.category, ''), COALESCE(emp.department, ''), e.currency,
+		e.purchase_cost, e.purchase_date, COALESCE(e.depreciation_method, r.method, ''),
+		COALESCE(e.useful_life_months, r.useful_life_months, 0), COALESCE(r.salvage_percent, 0)
+		FROM equipment e
+		LEFT JOIN LATERAL (
+			SELECT l.user_id FROM equipment_logs l
+			WHERE l.equipment_id = e.id AND l.issued_at < $5 AND (l.returned_at IS NULL OR l.returned_at >= $5)
+			ORDER BY l.issued_at DESC LIMIT 1
+		) holder ON true
+		LEFT JOIN employees emp ON emp.id = holder.user_id
+		LEFT JOIN depreciation_rules r ON r.category = e.category
+		WHERE (e.status NOT IN ($1, $2)
+			OR EXISTS (SELECT 1 FROM equipment_logs l WHERE l.equipment_id = e.id AND l.status = $1 AND l.returned_at >= $5)
+			OR EXISTS (SELECT 1 FROM disposals d WHERE d.equipment_id = e.id AND d.status = $4 AND d.decided_at >= $5))
+		AND (e.purchase_date IS NULL OR e.purchase_date <= $3)
+		ORDER BY e.id`,
+		HistoryStatusWrittenOff, EquipmentStatusDisposed, asOf, DisposalCompleted, dayAfter,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении оборудования для оценки: %v", err)
+	}
+	defer rows.Close()
+
+	report := &ValuationReport{AsOf: asOf, GroupBy: groupBy, Unvalued: []int{}}
+	items := []ValuationItem{}
+	for rows.Next() {
+		var item ValuationItem
+		var cost *float64
+		var purchaseDate *time.Time
+		var salvagePercent float64
+		if err := rows.Scan(&item.EquipmentID, &item.Model, &item.Category, &item.Department, &item.Currency,
+			&cost, &purchaseDate, &item.Method, &item.UsefulLifeMonths, &salvagePercent); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		if cost == nil || purchaseDate == nil || item.Method == "" || item.UsefulLifeMonths == 0 {
+			report.Unvalued = append(report.Unvalued, item.EquipmentID)
+			continue
+		}
+
+		item.AssetTag = AssetTag(item.EquipmentID)
+		item.PurchaseDate = *purchaseDate
+		item.AcquisitionCost = *cost
+		item.AccumulatedDepreciation, item.BookValue = Depreciate(item.Method, *cost, *cost*salvagePercent/100,
+			item.UsefulLifeMonths, monthsBetween(*purchaseDate, asOf))
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при переборе строк: %v", err)
+	}
+
+	if groupBy == ValuationByItem {
+		report.Items = items
+	} else {
+		report.Groups = groupValuation(items, groupBy)
+	}
+	return report, nil
+}
+
+// groupValuation суммирует стоимость по отделу или категории отдельно для каждой валюты
+func groupValuation(items []ValuationItem, groupBy string) []ValuationGroup {
+	index := make(map[[2]string]int)
+	groups := []ValuationGroup{}
+	for _, item := range items {
+		key := [2]string{item.Category, item.Currency}
+		if groupBy == ValuationByDepartment {
+			key[0] = item.Department
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, ValuationGroup{Key: key[0], Currency: key[1]})
+		}
+		groups[i].Items++
+		groups[i].AcquisitionCost = roundCents(groups[i].AcquisitionCost + item.AcquisitionCost)
+		groups[i].AccumulatedDepreciation = roundCents(groups[i].AccumulatedDepreciation + item.AccumulatedDepreciation)
+		groups[i].BookValue = roundCents(groups[i].BookValue + item.BookValue)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Key != groups[j].Key {
+			return groups[i].Key < groups[j].Key
+		}
+		return groups[i].Currency < groups[j].Currency
+	})
+	return groups
+}
+
+// parseUsefulLife разбирает срок полезного использования из частичного обновления; пустая строка очищает значение
+func parseUsefulLife(v *ValidationError, field, value string) *int {
+	if value == "" {
+		return nil
+	}
+	months, err := strconv.Atoi(value)
+	if err != nil {
+		v.Add(field, validation.CodeInvalid, "ожидается целое число месяцев")
+		return nil
+	}
+	checkUsefulLife(v, field, months)
+	return &months
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"inva/pkg/validation"
+	"strings"
+	"time"
+)
+
+// EquipmentStatusDisposed конечный статус утилизированного оборудования; запись о нём остаётся в инвентаре
+const EquipmentStatusDisposed = "disposed"
+
+// Способы утилизации оборудования
+const (
+	DisposalRecycle = "recycle"
+	DisposalSell    = "sell"
+	DisposalDestroy = "destroy"
+)
+
+// Статусы заявок на утилизацию
+const (
+	DisposalPending   = "pending"
+	DisposalCompleted = "completed"
+	DisposalRejected  = "rejected"
+)
+
+// Ограничения на длину полей заявки на утилизацию
+const (
+	maxDisposalReason   = 500
+	maxRecipientLength  = 255
+	maxWipeMethodLength = 255
+)
+
+// Disposal заявка на утилизацию оборудования: причина, способ, подтверждение очистки данных и решение
+type Disposal struct {
+	ID                int        `json:"id"`
+	EquipmentID       int        `json:"equipment_id"`
+	AssetTag          string     `json:"asset_tag"`
+	Model             string     `json:"model"`
+	SerialNumber      string     `json:"serial_number"`
+	Category          string     `json:"category,omitempty"`
+	Status            string     `json:"status"`
+	Reason            string     `json:"reason"`
+	Method            string     `json:"method"`
+	Recipient         string     `json:"recipient,omitempty"`
+	RequestedBy       int        `json:"requested_by"`
+	RequestedByName   string     `json:"requested_by_name,omitempty"`
+	DataWipeMethod    string     `json:"data_wipe_method,omitempty"`
+	DataWipedBy       *int       `json:"data_wiped_by,omitempty"`
+	DataWipedByName   string     `json:"data_wiped_by_name,omitempty"`
+	DataWipedAt       *time.Time `json:"data_wiped_at,omitempty"`
+	ApprovedBy        *int       `json:"approved_by,omitempty"`
+	ApprovedByName    string     `json:"approved_by_name,omitempty"`
+	DecisionNote      string     `json:"decision_note,omitempty"`
+	CertificateNumber string     `json:"certificate_number,omitempty"`
+	RequestedAt       time.Time  `json:"requested_at"`
+	DecidedAt         *time.Time `json:"decided_at,omitempty"`
+}
+
+// DataWiped сообщает, подтверждена ли очистка данных
+func (d *Disposal) DataWiped() bool {
+	return d.DataWipedAt != nil
+}
+
+// DisposalRequest данные новой заявки на утилизацию
+type DisposalRequest struct {
+	Reason      string
+	Method      string
+	Recipient   string
+	RequestedBy int
+}
+
+// DisposalFilter условия выборки заявок на утилизацию; нулевые поля не ограничивают выборку
+type DisposalFilter struct {
+	Status      string
+	EquipmentID int
+}
+
+// DisposalService предоставляет методы для утилизации и списания оборудования
+type DisposalService struct {
+	db *sql.DB
+}
+
+// NewDisposalService создаёт новый экземпляр DisposalService
+func NewDisposalService(db *sql.DB) *DisposalService {
+	return &DisposalService{db: db}
+}
+
+// DisposalCertificateNumber возвращает номер акта об утилизации по идентификатору заявки
+func DisposalCertificateNumber(id int) string {
+	return fmt.Sprintf("DSP-%06d", id)
+}
+
+// ValidateDisposalRequest проверяет заявку на утилизацию перед сохранением
+func ValidateDisposalRequest(request DisposalRequest) error {
+	v := &ValidationError{}
+	v.RequireString("reason", request.Reason, maxDisposalReason)
+	switch request.Method {
+	case DisposalRecycle, DisposalSell, DisposalDestroy:
+	default:
+		v.Add("method", validation.CodeNotAllowed, "допустимые значения: %s, %s, %s", DisposalRecycle, DisposalSell, DisposalDestroy)
+	}
+	v.MaxLength("recipient", request.Recipient, maxRecipientLength)
+	if request.RequestedBy < 1 {
+		v.Add("requested_by", validation.CodeRequired, "обязательное поле")
+	}
+	return v.ErrOrNil()
+}
+
+// RequestDisposal создаёт заявку на утилизацию оборудования. Для одного оборудования допускается
+// только одна незавершённая заявка, утилизированное оборудование повторно не утилизируется.
+func (s *DisposalService) RequestDisposal(equipmentID int, request DisposalRequest) (*Disposal, error) {
+	if err := ValidateDisposalRequest(request); err != nil {
+		return nil, err
+	}
+
+	var id int
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		var status string
+		if err := tx.QueryRow("SELECT status FROM equipment WHERE id = $1 FOR UPDATE", equipmentID).Scan(&status); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w (id %d)", ErrEquipmentNotFound, equipmentID)
+			}
+			return fmt.Errorf("ошибка при получении оборудования: %v", err)
+		}
+		if status == EquipmentStatusDisposed {
+			return fmt.Errorf("%w (id %d)", ErrEquipmentDisposed, equipmentID)
+		}
+
+		var pending bool
+		if err := tx.QueryRow(
+			"SELECT EXISTS (SELECT 1 FROM disposals WHERE equipment_id = $1 AND status = $2)", equipmentID, DisposalPending,
+		).Scan(&pending); err != nil {
+			return fmt.Errorf("ошибка при проверке заявок на утилизацию: %v", err)
+		}
+		if pending {
+			return fmt.Errorf("%w (оборудование %d)", ErrDisposalPending, equipmentID)
+		}
+
+		if err := requireActiveEmployee(tx, request.RequestedBy); err != nil {
+			return err
+		}
+
+		if err := tx.QueryRow(
+			`INSERT INTO disposals (equipment_id, status, reason, method, recipient, requested_by)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6) RETURNING id`,
+			equipmentID, DisposalPending, request.Reason, request.Method, request.Recipient, request.RequestedBy,
+		).Scan(&id); err != nil {
+			return fmt.Errorf("ошибка при создании заявки на утилизацию: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetDisposal(id)
+}
+
+// ConfirmDataWipe подтверждает очистку данных на оборудовании: кто, когда и каким способом её выполнил.
+// Без подтверждения заявку нельзя одобрить.
+func (s *DisposalService) ConfirmDataWipe(id, employeeID int, method string, wipedAt *time.Time) (*Disposal, error) {
+	v := &ValidationError{}
+	v.RequireString("method", method, maxWipeMethodLength)
+	if err := v.ErrOrNil(); err != nil {
+		return nil, err
+	}
+	if wipedAt == nil {
+		now := time.Now()
+		wipedAt = &now
+	}
+
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		disposal, err := lockDisposal(tx, id)
+		if err != nil {
+			return err
+		}
+		if disposal.Status != DisposalPending {
+			return fmt.Errorf("%w (id %d, статус %s)", ErrDisposalClosed, id, disposal.Status)
+		}
+		if err := requireActiveEmployee(tx, employeeID); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"UPDATE disposals SET data_wipe_method = $1, data_wiped_by = $2, data_wiped_at = $3 WHERE id = $4",
+			method, employeeID, *wipedAt, id,
+		)
+		if err != nil {
+			return fmt.Errorf("ошибка при подтверждении очистки данных: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetDisposal(id)
+}
+
+// Approve одобряет заявку и утилизирует оборудование: оно получает конечный статус disposed и остаётся
+// в инвентаре вместе с историей. Одобрить может руководитель или администратор, но не автор заявки;
+// очистка данных должна быть подтверждена, а оборудование возвращено и не находиться в ремонте.
+func (s *DisposalService) Approve(id, approverID int, note string) (*Disposal, error) {
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		disposal, err := s.checkDecision(tx, id, approverID)
+		if err != nil {
+			return err
+		}
+		if approverID == disposal.RequestedBy {
+			return fmt.Errorf("%w (заявка %d: автор заявки не может её одобрить)", ErrApprovalDenied, id)
+		}
+		if !disposal.DataWiped() {
+			return fmt.Errorf("%w (заявка %d)", ErrDataWipeRequired, id)
+		}
+
+		assignedTo, err := lockAssignee(tx, disposal.EquipmentID)
+		if err != nil {
+			return err
+		}
+		if assignedTo != nil {
+			return fmt.Errorf("%w (id %d, сотрудник %d)", ErrEquipmentAssigned, disposal.EquipmentID, *assignedTo)
+		}
+		// Закрытие ремонта восстановило бы прежний статус поверх disposed
+		open, err := hasOpenMaintenance(tx, disposal.EquipmentID)
+		if err != nil {
+			return err
+		}
+		if open {
+			return fmt.Errorf("%w (id %d)", ErrMaintenanceOpen, disposal.EquipmentID)
+		}
+		if err := changeWorkflowStatus(tx, disposal.EquipmentID, EquipmentStatusDisposed); err != nil {
+			return err
+		}
+		return decideDisposal(tx, id, DisposalCompleted, approverID, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetDisposal(id)
+}
+
+// Reject отклоняет заявку на утилизацию с обязательным комментарием; оборудование не изменяется
+func (s *DisposalService) Reject(id, approverID int, note string) (*Disposal, error) {
+	if strings.TrimSpace(note) == "" {
+		return nil, validation.New("note", validation.CodeRequired, "укажите причину отказа")
+	}
+
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		if _, err := s.checkDecision(tx, id, approverID); err != nil {
+			return err
+		}
+		return decideDisposal(tx, id, DisposalRejected, approverID, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetDisposal(id)
+}
+
+// checkDecision блокирует заявку и проверяет, что решение по ней ещё не принято и принимает его руководитель
+func (s *DisposalService) checkDecision(q queryer, id, approverID int) (*Disposal, error) {
+	disposal, err := lockDisposal(q, id)
+	if err != nil {
+		return nil, err
+	}
+	if disposal.Status != DisposalPending {
+		return nil, fmt.Errorf("%w (id %d, статус %s)", ErrDisposalClosed, id, disposal.Status)
+	}
+	if err := requireApprover(q, approverID); err != nil {
+		return nil, err
+	}
+	return disposal, nil
+}
+
+// decideDisposal записывает решение по заявке на утилизацию
+func decideDisposal(q queryer, id int, status string, approverID int, note string) error {
+	_, err := q.Exec(
+		"UPDATE disposals SET status = $1, approved_by = $2, decision_note = NULLIF($3, ''), decided_at = NOW() WHERE id = $4",
+		status, approverID, note, id,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при записи решения по заявке на утилизацию: %v", err)
+	}
+	return nil
+}
+
+// lockDisposal блокирует заявку на утилизацию до конца транзакции и возвращает её статус, оборудование,
+// автора и время очистки данных
+func lockDisposal(q queryer, id int) (*Disposal, error) {
+	disposal := &Disposal{ID: id}
+	err := q.QueryRow(
+		"SELECT status, equipment_id, requested_by, data_wiped_at FROM disposals WHERE id = $1 FOR UPDATE", id,
+	).Scan(&disposal.Status, &disposal.EquipmentID, &disposal.RequestedBy, &disposal.DataWipedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w (id %d)", ErrDisposalNotFound, id)
+		}
+		return nil, fmt.Errorf("ошибка при получении заявки на утилизацию: %v", err)
+	}
+	return disposal, nil
+}
+
+// disposalColumns выборка заявки вместе с данными оборудования и именами участников
+const disposalColumns = `SELECT d.id, d.equipment_id, e.model, COALESCE(e.serial_number, ''), COALESCE(e.category, ''),
+	d.status, d.reason, d.method, COALESCE(d.recipient, ''), d.requested_by, COALESCE(req.name, ''),
+	COALESCE(d.data_wipe_method, ''), d.data_wiped_by, COALESCE(wip.name, ''), d.data_wiped_at,
+	d.approved_by, COALESCE(app.name, ''), COALESCE(d.decision_note, ''), d.requested_at, d.decided_at
+	FROM disposals d
+	JOIN equipment e ON e.id = d.equipment_id
+	LEFT JOIN employees req ON req.id = d.requested_by
+	LEFT JOIN employees wip ON wip.id = d.data_wiped_by
+	LEFT JOIN employees app ON app.id = d.approved_by`
+
+// scanDisposal читает заявку на утилизацию из строки, выбранной по disposalColumns
+func scanDisposal(row interface{ Scan(...interface{}) error }) (*Disposal, error) {
+	var d Disposal
+	err := row.Scan(&d.ID, &d.EquipmentID, &d.Model, &d.SerialNumber, &d.Category,
+		&d.Status, &d.Reason, &d.Method, &d.Recipient, &d.RequestedBy, &d.RequestedByName,
+		&d.DataWipeMethod, &d.DataWipedBy, &d.DataWipedByName, &d.DataWipedAt,
+		&d.ApprovedBy, &d.ApprovedByName, &d.DecisionNote, &d.RequestedAt, &d.DecidedAt)
+	if err != nil {
+		return nil, err
+	}
+	d.AssetTag = AssetTag(d.EquipmentID)
+	if d.Status == DisposalCompleted {
+		d.CertificateNumber = DisposalCertificateNumber(d.ID)
+	}
+	return &d, nil
+}
+
+// GetDisposal возвращает заявку на утилизацию по идентификатору
+func (s *DisposalService) GetDisposal(id int) (*Disposal, error) {
+	disposal, err := scanDisposal(s.db.QueryRow(disposalColumns+" WHERE d.id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w (id %d)", ErrDisposalNotFound, id)
+		}
+		return nil, fmt.Errorf("ошибка при получении заявки на утилизацию: %v", err)
+	}
+	return disposal, nil
+}
+
+// GetDisposals возвращает заявки на утилизацию, подходящие под фильтр, начиная с самых старых
+func (s *DisposalService) GetDisposals(filter DisposalFilter) ([]Disposal, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("d.status = $%d", len(args)))
+	}
+	if filter.EquipmentID > 0 {
+		args = append(args, filter.EquipmentID)
+		conditions = append(conditions, fmt.Sprintf("d.equipment_id = $%d", len(args)))
+	}
+
+	query := disposalColumns
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	rows, err := s.db.Query(query+" ORDER BY d.id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении заявок на утилизацию: %v", err)
+	}
+	defer rows.Close()
+
+	disposals := []Disposal{}
+	for rows.Next() {
+		disposal, err := scanDisposal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		disposals = append(disposals, *disposal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при переборе строк: %v", err)
+	}
+
+	return disposals, nil
+}
+
+// GetCertificate возвращает завершённую утилизацию для формирования акта
+func (s *DisposalService) GetCertificate(id int) (*Disposal, error) {
+	disposal, err := s.GetDisposal(id)
+	if err != nil {
+		return nil, err
+	}
+	if disposal.Status != DisposalCompleted {
+		return nil, fmt.Errorf("%w (id %d, статус %s)", ErrDisposalNotCompleted, id, disposal.Status)
+	}
+	return disposal, nil
+}
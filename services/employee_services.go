@@ -4,13 +4,51 @@ import (
 	"database/sql"
 	"fmt"
 	"inva/models"
+	"inva/pkg/validation"
 	"log"
+	"strconv"
+	"strings"
 )
 
+// badgePrefix префикс номера пропуска сотрудника
+const badgePrefix = "EMP-"
+
 // Employee представляет модель сотрудника
 type Employee struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Email      string `json:"email,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Department string `json:"department,omitempty"`
+	Position   string `json:"position,omitempty"`
+	Role       string `json:"role,omitempty"`
+	Version    int    `json:"version,omitempty"`
+}
+
+// Роли сотрудника: руководитель принимает решения по заявкам на оборудование,
+// администратор также может выдавать оборудование вопреки политикам выдачи
+const (
+	EmployeeRoleUser    = "user"
+	EmployeeRoleManager = "manager"
+	EmployeeRoleAdmin   = "admin"
+)
+
+// BadgeID возвращает номер пропуска сотрудника
+func BadgeID(id int) string {
+	return fmt.Sprintf("%s%06d", badgePrefix, id)
+}
+
+// ParseBadgeID извлекает идентификатор сотрудника из номера пропуска
+func ParseBadgeID(badge string) (int, bool) {
+	badge = strings.ToUpper(strings.TrimSpace(badge))
+	if !strings.HasPrefix(badge, badgePrefix) {
+		return 0, false
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(badge, badgePrefix))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
 }
 
 // Интерфейс для EmployeeRepository
@@ -32,12 +70,33 @@ func NewEmployeeService(db *sql.DB) *EmployeeService {
 	return &EmployeeService{db: db}
 }
 
-// CreateEmployee создает нового сотрудника в базе данных
+// CreateEmployee создает нового сотрудника в базе данных; email уникален без учёта регистра
 func (s *EmployeeService) CreateEmployee(employee *Employee) (*Employee, error) {
+	if err := ValidateEmployee(employee.Name, employee.Email); err != nil {
+		return nil, err
+	}
+
+	var created *Employee
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		if err := checkEmailTaken(tx, employee.Email, 0); err != nil {
+			return err
+		}
+		var err error
+		created, err = createEmployee(tx, employee)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// createEmployee создает сотрудника в рамках переданной транзакции без проверки данных
+func createEmployee(q queryer, employee *Employee) (*Employee, error) {
 	var id int
-	err := s.db.QueryRow(
-		"INSERT INTO employees (name) VALUES ($1) RETURNING id",
-		employee.Name,
+	err := q.QueryRow(
+		"INSERT INTO employees (name, email) VALUES ($1, NULLIF($2, '')) RETURNING id",
+		employee.Name, employee.Email,
 	).Scan(&id)
 	if err != nil {
 		log.Printf("Ошибка при создании сотрудника: %v", err)
@@ -48,16 +107,34 @@ func (s *EmployeeService) CreateEmployee(employee *Employee) (*Employee, error)
 	return employee, nil
 }
 
+// checkEmailTaken возвращает ошибку проверки, если адрес электронной почты уже указан
+// у другого сотрудника без учёта регистра; exceptID исключает самого изменяемого сотрудника
+func checkEmailTaken(q queryer, email string, exceptID int) error {
+	if email == "" {
+		return nil
+	}
+	var exists bool
+	if err := q.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM employees WHERE lower(email) = lower($1) AND id <> $2)", email, exceptID,
+	).Scan(&exists); err != nil {
+		return fmt.Errorf("ошибка при проверке email сотрудника: %v", err)
+	}
+	if exists {
+		return validation.New("email", validation.CodeDuplicate, "адрес %q уже указан у другого сотрудника", email)
+	}
+	return nil
+}
+
 // GetEmployeeByID возвращает сотрудника по его идентификатору
 func (s *EmployeeService) GetEmployeeByID(id int) (*Employee, error) {
 	var employee Employee
 	err := s.db.QueryRow(
-		"SELECT id, name FROM employees WHERE id = $1",
+		"SELECT id, name, COALESCE(email, ''), status, COALESCE(department, ''), COALESCE(position, ''), role, version FROM employees WHERE id = $1",
 		id,
-	).Scan(&employee.ID, &employee.Name)
+	).Scan(&employee.ID, &employee.Name, &employee.Email, &employee.Status, &employee.Department, &employee.Position, &employee.Role, &employee.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("сотрудник с id %d не найден", id)
+			return nil, fmt.Errorf("%w (id %d)", ErrEmployeeNotFound, id)
 		}
 		log.Printf("Ошибка при получении сотрудника: %v", err)
 		return nil, err
@@ -66,9 +143,27 @@ func (s *EmployeeService) GetEmployeeByID(id int) (*Employee, error) {
 	return &employee, nil
 }
 
+// GetEmployeeByEmail возвращает сотрудника по адресу электронной почты
+func (s *EmployeeService) GetEmployeeByEmail(email string) (*Employee, error) {
+	var employee Employee
+	err := s.db.QueryRow(
+		"SELECT id, name FROM employees WHERE lower(email) = lower($1)",
+		email,
+	).Scan(&employee.ID, &employee.Name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w (email %s)", ErrEmployeeNotFound, email)
+		}
+		log.Printf("Ошибка при получении сотрудника по email: %v", err)
+		return nil, err
+	}
+
+	return &employee, nil
+}
+
 // GetAllEmployees возвращает всех сотрудников из базы данных
 func (s *EmployeeService) GetAllEmployees() ([]Employee, error) {
-	rows, err := s.db.Query("SELECT id, name FROM employees")
+	rows, err := s.db.Query("SELECT id, name, status FROM employees")
 	if err != nil {
 		log.Printf("Ошибка при получении всех сотрудников: %v", err)
 		return nil, err
@@ -78,7 +173,7 @@ func (s *EmployeeService) GetAllEmployees() ([]Employee, error) {
 	var employees []Employee
 	for rows.Next() {
 		var employee Employee
-		if err := rows.Scan(&employee.ID, &employee.Name); err != nil {
+		if err := rows.Scan(&employee.ID, &employee.Name, &employee.Status); err != nil {
 			log.Printf("Ошибка при сканировании строки: %v", err)
 			return nil, err
 		}
@@ -93,31 +188,162 @@ func (s *EmployeeService) GetAllEmployees() ([]Employee, error) {
 	return employees, nil
 }
 
-// UpdateEmployee обновляет данные сотрудника
+// UpdateEmployee обновляет имя сотрудника и сбрасывает его email
 func (s *EmployeeService) UpdateEmployee(id int, name string) error {
-	_, err := s.db.Exec(
-		"UPDATE employees SET name = $1 WHERE id = $2",
-		name,
-		id,
-	)
+	_, err := s.UpdateEmployeeIfMatch(id, 0, name, "")
+	return err
+}
+
+// UpdateEmployeeIfMatch заменяет имя и email сотрудника, если его текущая версия равна version,
+// и возвращает новую версию; version 0 означает обновление без проверки версии
+func (s *EmployeeService) UpdateEmployeeIfMatch(id, version int, name, email string) (int, error) {
+	if err := ValidateEmployee(name, email); err != nil {
+		return 0, err
+	}
+
+	query := "UPDATE employees SET name = $1, email = NULLIF($2, ''), " + bumpVersion + " WHERE id = $3"
+	args := []interface{}{name, email, id}
+	if version > 0 {
+		query += " AND version = $4"
+		args = append(args, version)
+	}
+	query += " RETURNING version"
+
+	var updated int
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		if err := checkEmailTaken(tx, email, id); err != nil {
+			return err
+		}
+		err := tx.QueryRow(query, args...).Scan(&updated)
+		if err == sql.ErrNoRows {
+			return s.missingOrStale(id, version)
+		}
+		if err != nil {
+			log.Printf("Ошибка при обновлении сотрудника: %v", err)
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		log.Printf("Ошибка при обновлении сотрудника: %v", err)
-		return err
+		return 0, err
 	}
+	return updated, nil
+}
 
-	return nil
+// EmployeePatch частичное обновление сотрудника; nil означает, что поле не изменяется
+type EmployeePatch struct {
+	Name       *string
+	Email      *string
+	Department *string
+	Position   *string
+	Role       *string
+}
+
+// PatchEmployee изменяет только переданные поля сотрудника и возвращает обновлённую запись;
+// version 0 означает обновление без проверки версии
+func (s *EmployeeService) PatchEmployee(id, version int, patch EmployeePatch) (*Employee, error) {
+	var employee Employee
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		err := tx.QueryRow(
+			"SELECT id, name, COALESCE(email, ''), status, COALESCE(department, ''), COALESCE(position, ''), role, version FROM employees WHERE id = $1 FOR UPDATE", id,
+		).Scan(&employee.ID, &employee.Name, &employee.Email, &employee.Status, &employee.Department, &employee.Position, &employee.Role, &employee.Version)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w (id %d)", ErrEmployeeNotFound, id)
+			}
+			return fmt.Errorf("ошибка при получении сотрудника: %v", err)
+		}
+		if version > 0 && employee.Version != version {
+			return fmt.Errorf("%w (id %d, ожидалась версия %d, текущая %d)", ErrVersionMismatch, id, version, employee.Version)
+		}
+
+		applyPatch(&employee.Name, patch.Name)
+		applyPatch(&employee.Email, patch.Email)
+		applyPatch(&employee.Department, patch.Department)
+		applyPatch(&employee.Position, patch.Position)
+		applyPatch(&employee.Role, patch.Role)
+
+		v := &ValidationError{}
+		v.RequireString("name", employee.Name, maxNameLength)
+		checkEmail(v, employee.Email)
+		v.MaxLength("department", employee.Department, maxDepartmentLength)
+		v.MaxLength("position", employee.Position, maxPositionLength)
+		switch employee.Role {
+		case EmployeeRoleUser, EmployeeRoleManager, EmployeeRoleAdmin:
+		default:
+			v.Add("role", validation.CodeNotAllowed, "допустимые значения: %s, %s, %s", EmployeeRoleUser, EmployeeRoleManager, EmployeeRoleAdmin)
+		}
+		if err := v.ErrOrNil(); err != nil {
+			return err
+		}
+		if patch.Email != nil {
+			if err := checkEmailTaken(tx, employee.Email, id); err != nil {
+				return err
+			}
+		}
+
+		return tx.QueryRow(
+			"UPDATE employees SET name = $1, email = NULLIF($2, ''), department = NULLIF($3, ''), position = NULLIF($4, ''), role = $5, "+bumpVersion+
+				" WHERE id = $6 RETURNING version",
+			employee.Name, employee.Email, employee.Department, employee.Position, employee.Role, id,
+		).Scan(&employee.Version)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &employee, nil
 }
 
 // DeleteEmployee удаляет сотрудника из базы данных
 func (s *EmployeeService) DeleteEmployee(id int) error {
-	_, err := s.db.Exec(
-		"DELETE FROM employees WHERE id = $1",
-		id,
-	)
+	return s.DeleteEmployeeIfMatch(id, 0)
+}
+
+// DeleteEmployeeIfMatch удаляет сотрудника, если его текущая версия равна version;
+// version 0 означает удаление без проверки версии
+func (s *EmployeeService) DeleteEmployeeIfMatch(id, version int) error {
+	query := "DELETE FROM employees WHERE id = $1"
+	args := []interface{}{id}
+	if version > 0 {
+		query += " AND version = $2"
+		args = append(args, version)
+	}
+
+	result, err := s.db.Exec(query, args...)
 	if err != nil {
 		log.Printf("Ошибка при удалении сотрудника: %v", err)
 		return err
 	}
 
-	return nil
+	return s.checkVersionedChange(result, id, version)
+}
+
+// checkVersionedChange отличает отсутствие сотрудника от несовпадения версии, если запрос не затронул строк
+func (s *EmployeeService) checkVersionedChange(result sql.Result, id, version int) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении числа изменённых строк: %v", err)
+	}
+	if affected > 0 {
+		return nil
+	}
+	return s.missingOrStale(id, version)
+}
+
+// missingOrStale возвращает ErrEmployeeNotFound, если сотрудника нет, и ErrVersionMismatch,
+// если его версия не совпадает с ожидаемой
+func (s *EmployeeService) missingOrStale(id, version int) error {
+	if version == 0 {
+		return fmt.Errorf("%w (id %d)", ErrEmployeeNotFound, id)
+	}
+
+	var current int
+	if err := s.db.QueryRow("SELECT version FROM employees WHERE id = $1", id).Scan(&current); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w (id %d)", ErrEmployeeNotFound, id)
+		}
+		return fmt.Errorf("ошибка при получении версии сотрудника: %v", err)
+	}
+	return fmt.Errorf("%w (id %d, ожидалась версия %d, текущая %d)", ErrVersionMismatch, id, version, current)
 }
@@ -2,19 +2,206 @@ package services
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"inva/pkg/validation"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+// foreignKeyViolation код ошибки PostgreSQL при нарушении внешнего ключа
+const foreignKeyViolation = "23503"
+
+// assetTagPrefix префикс инвентарного номера, печатаемого на наклейках
+const assetTagPrefix = "INV-"
+
+// dateLayout формат дат без времени: покупки, окончания гарантии
+const dateLayout = "2006-01-02"
+
+// bumpVersion выражение SET, которым каждое изменение строки увеличивает версию и время обновления
+const bumpVersion = "version = version + 1, updated_at = NOW()"
+
 // Equipment представляет модель оборудования
 type Equipment struct {
-	ID           int    `json:"id"`
-	Model        string `json:"model"`
-	SerialNumber string `json:"serial_number"`
-	Status       string `json:"status"`
-	CreatedAt    string `json:"created_at"`
-	AssignedTo   *int   `json:"assigned_to"`
-	UpdatedAt    string `json:"updated_at"`
+	ID           int      `json:"id"`
+	Model        string   `json:"model"`
+	SerialNumber string   `json:"serial_number"`
+	Status       string   `json:"status"`
+	CreatedAt    string   `json:"created_at"`
+	AssignedTo   *int     `json:"assigned_to"`
+	UpdatedAt    string   `json:"updated_at"`
+	Version      int      `json:"version,omitempty"`
+	Location     string   `json:"location,omitempty"`
+	Category     string   `json:"category,omitempty"`
+	PurchaseCost *float64 `json:"purchase_cost,omitempty"`
+	// PurchaseDate и WarrantyExpiresAt хранятся как даты без времени
+	PurchaseDate      *time.Time `json:"purchase_date,omitempty"`
+	WarrantyExpiresAt *time.Time `json:"warranty_expires_at,omitempty"`
+	SupportContract   string     `json:"support_contract,omitempty"`
+	// Currency, DepreciationMethod и UsefulLifeMonths задают оценку стоимости; пустые метод и срок берутся из правила категории
+	Currency           string `json:"currency,omitempty"`
+	DepreciationMethod string `json:"depreciation_method,omitempty"`
+	UsefulLifeMonths   *int   `json:"useful_life_months,omitempty"`
+	// PurchaseOrderLineID позиция заказа, по которой оборудование принято; nil для созданного вручную
+	PurchaseOrderLineID *int       `json:"purchase_order_line_id,omitempty"`
+	DueAt               *time.Time `json:"due_at,omitempty"`
+	Overdue             bool       `json:"overdue,omitempty"`
+}
+
+// DetailsHistoryLimit число последних записей истории в карточке оборудования
+const DetailsHistoryLimit = 5
+
+// EquipmentDetails карточка оборудования со сведениями о владельце и последними записями истории
+type EquipmentDetails struct {
+	Equipment
+	AssetTag      string           `json:"asset_tag"`
+	Assignee      *Employee        `json:"assignee"`
+	Source        *EquipmentSource `json:"source,omitempty"`
+	RecentHistory []HistoryEntry   `json:"recent_history"`
+}
+
+// EquipmentSource заказ и поставщик, от которых поступило оборудование
+type EquipmentSource struct {
+	PurchaseOrderID     int    `json:"purchase_order_id"`
+	PurchaseOrderNumber string `json:"purchase_order_number"`
+	VendorID            int    `json:"vendor_id"`
+	VendorName          string `json:"vendor_name"`
+}
+
+// AssignOptions дополнительные параметры выдачи оборудования
+type AssignOptions struct {
+	// DueAt срок возврата; nil означает бессрочную выдачу
+	DueAt *time.Time
+	// Note комментарий к записи истории выдачи
+	Note string
+	// Override разрешение администратора выдать оборудование вопреки политикам выдачи
+	Override *PolicyOverride
+}
+
+// TransferResult представляет результат передачи оборудования между сотрудниками
+type TransferResult struct {
+	EquipmentID   int        `json:"equipment_id"`
+	FromUserID    int        `json:"from_user_id"`
+	ToUserID      int        `json:"to_user_id"`
+	Reason        string     `json:"reason"`
+	DueAt         *time.Time `json:"due_at"`
+	TransferredAt time.Time  `json:"transferred_at"`
+}
+
+// OverdueItem представляет просроченную выдачу оборудования
+type OverdueItem struct {
+	EquipmentID  int       `json:"equipment_id"`
+	AssetTag     string    `json:"asset_tag"`
+	Model        string    `json:"model"`
+	SerialNumber string    `json:"serial_number"`
+	UserID       int       `json:"user_id"`
+	UserName     string    `json:"user_name"`
+	IssuedAt     time.Time `json:"issued_at"`
+	DueAt        time.Time `json:"due_at"`
+	OverdueDays  int       `json:"overdue_days"`
+}
+
+// WarrantyItem строка отчёта об оборудовании, гарантия которого скоро истекает
+type WarrantyItem struct {
+	EquipmentID       int        `json:"equipment_id"`
+	AssetTag          string     `json:"asset_tag"`
+	Model             string     `json:"model"`
+	SerialNumber      string     `json:"serial_number"`
+	Category          string     `json:"category,omitempty"`
+	AssignedTo        *int       `json:"assigned_to"`
+	PurchaseDate      *time.Time `json:"purchase_date"`
+	WarrantyExpiresAt time.Time  `json:"warranty_expires_at"`
+	SupportContract   string     `json:"support_contract,omitempty"`
+	DaysLeft          int        `json:"days_left"`
+}
+
+// Статусы записей истории выдачи оборудования
+const (
+	HistoryStatusIssued      = "issued"
+	HistoryStatusReturned    = "returned"
+	HistoryStatusTransferred = "transferred"
+	HistoryStatusWrittenOff  = "written_off"
+)
+
+// HistoryEntry представляет запись истории выдачи оборудования
+type HistoryEntry struct {
+	ID          int        `json:"id"`
+	EquipmentID int        `json:"equipment_id"`
+	UserID      int        `json:"user_id"`
+	UserName    string     `json:"user_name"`
+	IssuedAt    time.Time  `json:"issued_at"`
+	DueAt       *time.Time `json:"due_at"`
+	ReturnedAt  *time.Time `json:"returned_at"`
+	Status      string     `json:"status"`
+	Note        string     `json:"note"`
+}
+
+// EquipmentFilter описывает условия отбора оборудования для списков и пакетных операций
+type EquipmentFilter struct {
+	IDs        []int
+	Model      string
+	Status     string
+	AssignedTo *int
+	Unassigned bool
+	// Limit ограничивает число строк FindEquipment; 0 — без ограничения
+	Limit int
+}
+
+// where формирует условие WHERE для фильтра; prefix задаёт псевдоним таблицы оборудования, например "e."
+func (f EquipmentFilter) where(prefix string) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if len(f.IDs) > 0 {
+		placeholders := make([]string, len(f.IDs))
+		for i, id := range f.IDs {
+			args = append(args, id)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		conditions = append(conditions, prefix+"id IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if f.Model != "" {
+		args = append(args, f.Model)
+		conditions = append(conditions, fmt.Sprintf("%smodel = $%d", prefix, len(args)))
+	}
+	if f.Status != "" {
+		args = append(args, f.Status)
+		conditions = append(conditions, fmt.Sprintf("%sstatus = $%d", prefix, len(args)))
+	}
+	if f.AssignedTo != nil {
+		args = append(args, *f.AssignedTo)
+		conditions = append(conditions, fmt.Sprintf("%sassigned_to = $%d", prefix, len(args)))
+	}
+	if f.Unassigned {
+		conditions = append(conditions, prefix+"assigned_to IS NULL")
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// AssetTag возвращает инвентарный номер оборудования
+func AssetTag(id int) string {
+	return fmt.Sprintf("%s%06d", assetTagPrefix, id)
+}
+
+// ParseAssetTag извлекает идентификатор оборудования из инвентарного номера
+func ParseAssetTag(tag string) (int, bool) {
+	tag = strings.ToUpper(strings.TrimSpace(tag))
+	if !strings.HasPrefix(tag, assetTagPrefix) {
+		return 0, false
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(tag, assetTagPrefix))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
 }
 
 // EquipmentService представляет сервис для работы с оборудованием
@@ -27,59 +214,419 @@ func NewEquipmentService(db *sql.DB) *EquipmentService {
 	return &EquipmentService{db: db}
 }
 
-// AssignEquipmentToUser закрепляет оборудование за пользователем
+// AssignEquipmentToUser закрепляет оборудование за пользователем бессрочно и записывает выдачу в историю
 func (s *EquipmentService) AssignEquipmentToUser(equipmentID, userID int) error {
-	tx, err := s.db.Begin()
+	return s.AssignEquipment(equipmentID, userID, AssignOptions{})
+}
+
+// AssignEquipment закрепляет оборудование за пользователем с дополнительными параметрами выдачи
+func (s *EquipmentService) AssignEquipment(equipmentID, userID int, opts AssignOptions) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		return assignEquipment(tx, equipmentID, userID, opts)
+	})
+}
+
+// ReturnEquipmentFromUser возвращает оборудование обратно и закрывает запись о выдаче в истории
+func (s *EquipmentService) ReturnEquipmentFromUser(equipmentID int) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		return returnEquipment(tx, equipmentID)
+	})
+}
+
+// GetEquipmentHistory возвращает историю выдачи оборудования, начиная с последней записи
+func (s *EquipmentService) GetEquipmentHistory(equipmentID int) ([]HistoryEntry, error) {
+	return queryHistory(s.db, equipmentID, 0)
+}
+
+// queryHistory возвращает не более limit последних записей истории выдачи; limit 0 снимает ограничение
+func queryHistory(q queryer, equipmentID, limit int) ([]HistoryEntry, error) {
+	query := `SELECT l.id, l.equipment_id, l.user_id, COALESCE(e.name, ''), l.issued_at, l.due_at, l.returned_at, l.status, COALESCE(l.note, '')
+		FROM equipment_logs l LEFT JOIN employees e ON e.id = l.user_id
+		WHERE l.equipment_id = $1 ORDER BY l.issued_at DESC, l.id DESC`
+	args := []interface{}{equipmentID}
+	if limit > 0 {
+		query += " LIMIT $2"
+		args = append(args, limit)
+	}
+
+	rows, err := q.Query(query, args...)
 	if err != nil {
-		return fmt.Errorf("ошибка при начале транзакции: %v", err)
+		return nil, fmt.Errorf("ошибка при получении истории оборудования: %v", err)
+	}
+	defer rows.Close()
+
+	history := []HistoryEntry{}
+	for rows.Next() {
+		var entry HistoryEntry
+		if err := rows.Scan(&entry.ID, &entry.EquipmentID, &entry.UserID, &entry.UserName, &entry.IssuedAt, &entry.DueAt, &entry.ReturnedAt, &entry.Status, &entry.Note); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		history = append(history, entry)
 	}
 
-	_, err = tx.Exec("UPDATE equipment SET assigned_to = $1 WHERE id = $2", userID, equipmentID)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при переборе строк: %v", err)
+	}
+
+	return history, nil
+}
+
+// lockAssignee блокирует строку оборудования до конца транзакции и возвращает текущего владельца
+func lockAssignee(q queryer, equipmentID int) (*int, error) {
+	var assignedTo *int
+	err := q.QueryRow("SELECT assigned_to FROM equipment WHERE id = $1 FOR UPDATE", equipmentID).Scan(&assignedTo)
 	if err != nil {
-		tx.Rollback() // откат в случае ошибки
-		return fmt.Errorf("ошибка при закреплении оборудования: %v", err)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w (id %d)", ErrEquipmentNotFound, equipmentID)
+		}
+		return nil, fmt.Errorf("ошибка при получении оборудования: %v", err)
 	}
+	return assignedTo, nil
+}
 
-	err = tx.Commit()
+// lockedEquipment состояние строки оборудования, заблокированной до конца транзакции
+type lockedEquipment struct {
+	AssignedTo *int
+	Status     string
+	Version    int
+}
+
+// lockEquipment блокирует строку оборудования до конца транзакции. Утилизированное оборудование
+// больше не изменяется, поэтому для него возвращается ErrEquipmentDisposed.
+func lockEquipment(q queryer, id int) (*lockedEquipment, error) {
+	var locked lockedEquipment
+	err := q.QueryRow(
+		"SELECT assigned_to, status, version FROM equipment WHERE id = $1 FOR UPDATE", id,
+	).Scan(&locked.AssignedTo, &locked.Status, &locked.Version)
 	if err != nil {
-		return fmt.Errorf("ошибка при подтверждении транзакции: %v", err)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w (id %d)", ErrEquipmentNotFound, id)
+		}
+		return nil, fmt.Errorf("ошибка при получении оборудования: %v", err)
 	}
+	if locked.Status == EquipmentStatusDisposed {
+		return nil, fmt.Errorf("%w (id %d)", ErrEquipmentDisposed, id)
+	}
+	return &locked, nil
+}
 
+// checkVersion сравнивает версию заблокированного оборудования с ожидаемой; version 0 не проверяется
+func (e *lockedEquipment) checkVersion(id, version int) error {
+	if version > 0 && e.Version != version {
+		return fmt.Errorf("%w (id %d, ожидалась версия %d, текущая %d)", ErrVersionMismatch, id, version, e.Version)
+	}
 	return nil
 }
 
-// ReturnEquipmentFromUser возвращает оборудование обратно
-func (s *EquipmentService) ReturnEquipmentFromUser(equipmentID int) error {
-	_, err := s.db.Exec("UPDATE equipment SET assigned_to = NULL WHERE id = $1", equipmentID)
+// requireInService проверяет, что заблокированное оборудование можно выдавать и бронировать:
+// оборудование в ремонте ждёт закрытия ремонта, списанное больше не используется
+func (e *lockedEquipment) requireInService(id int) error {
+	switch e.Status {
+	case EquipmentStatusInRepair:
+		return fmt.Errorf("%w (id %d)", ErrMaintenanceOpen, id)
+	case HistoryStatusWrittenOff:
+		return fmt.Errorf("%w (id %d)", ErrEquipmentWrittenOff, id)
+	}
+	return nil
+}
+
+// assignEquipment закрепляет оборудование за сотрудником в рамках переданной транзакции
+func assignEquipment(q queryer, equipmentID, userID int, opts AssignOptions) error {
+	locked, err := lockEquipment(q, equipmentID)
 	if err != nil {
-		return fmt.Errorf("ошибка при возврате оборудования: %v", err)
+		return err
+	}
+	if locked.AssignedTo != nil {
+		return fmt.Errorf("%w (id %d, сотрудник %d)", ErrEquipmentAssigned, equipmentID, *locked.AssignedTo)
+	}
+	// Оборудование из ремонта выдаётся только после закрытия ремонта, списанное не выдаётся
+	if err := locked.requireInService(equipmentID); err != nil {
+		return err
+	}
+
+	// За уволенным или увольняемым сотрудником новое оборудование не закрепляется
+	if err := requireActiveEmployee(q, userID); err != nil {
+		return err
+	}
+	if err := checkAssignmentPolicies(q, equipmentID, userID, opts.Override); err != nil {
+		return err
+	}
+
+	if _, err := q.Exec("UPDATE equipment SET assigned_to = $1, "+bumpVersion+" WHERE id = $2", userID, equipmentID); err != nil {
+		return fmt.Errorf("ошибка при закреплении оборудования: %v", err)
+	}
+
+	if _, err := q.Exec(
+		"INSERT INTO equipment_logs (equipment_id, user_id, status, due_at, note) VALUES ($1, $2, $3, $4, $5)",
+		equipmentID, userID, HistoryStatusIssued, opts.DueAt, opts.Note,
+	); err != nil {
+		return fmt.Errorf("ошибка при записи истории выдачи: %v", err)
 	}
 
 	return nil
 }
 
-// GetEquipmentDetails возвращает подробности об оборудовании, включая информацию о том, за кем оно закреплено
-func (s *EquipmentService) GetEquipmentDetails(id int) (*Equipment, error) {
-	var equipment Equipment
+// returnEquipment возвращает оборудование в рамках переданной транзакции
+func returnEquipment(q queryer, equipmentID int) error {
+	_, err := closeAssignment(q, equipmentID, HistoryStatusReturned, "")
+	return err
+}
+
+// closeAssignment снимает закрепление оборудования и закрывает открытую запись истории с указанным статусом,
+// возвращая идентификатор прежнего владельца
+func closeAssignment(q queryer, equipmentID int, status, note string) (int, error) {
+	assignedTo, err := lockAssignee(q, equipmentID)
+	if err != nil {
+		return 0, err
+	}
+	if assignedTo == nil {
+		return 0, fmt.Errorf("%w (id %d)", ErrEquipmentNotAssigned, equipmentID)
+	}
+
+	if _, err := q.Exec("UPDATE equipment SET assigned_to = NULL, "+bumpVersion+" WHERE id = $1", equipmentID); err != nil {
+		return 0, fmt.Errorf("ошибка при возврате оборудования: %v", err)
+	}
+
+	if _, err := q.Exec(
+		"UPDATE equipment_logs SET returned_at = NOW(), status = $1, note = COALESCE(NULLIF($2, ''), note) WHERE equipment_id = $3 AND returned_at IS NULL",
+		status, note, equipmentID,
+	); err != nil {
+		return 0, fmt.Errorf("ошибка при записи истории возврата: %v", err)
+	}
+
+	// Открытая задача на возврат при увольнении закрывается тем же статусом
+	if _, err := q.Exec(
+		"UPDATE return_tasks SET status = $1, resolved_at = NOW() WHERE equipment_id = $2 AND status = $3",
+		status, equipmentID, ReturnTaskStatusOpen,
+	); err != nil {
+		return 0, fmt.Errorf("ошибка при закрытии задачи на возврат: %v", err)
+	}
+
+	return *assignedTo, nil
+}
+
+// TransferEquipment атомарно передаёт оборудование от текущего владельца другому сотруднику,
+// закрывая его запись истории и открывая новую
+func (s *EquipmentService) TransferEquipment(equipmentID, toUserID int, reason string, dueAt *time.Time) (*TransferResult, error) {
+	result := &TransferResult{EquipmentID: equipmentID, ToUserID: toUserID, Reason: reason, DueAt: dueAt}
+
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		assignedTo, err := lockAssignee(tx, equipmentID)
+		if err != nil {
+			return err
+		}
+		if assignedTo != nil && *assignedTo == toUserID {
+			return fmt.Errorf("%w (id %d, сотрудник %d)", ErrEquipmentAssigned, equipmentID, toUserID)
+		}
+
+		result.FromUserID, err = closeAssignment(tx, equipmentID, HistoryStatusTransferred, reason)
+		if err != nil {
+			return err
+		}
+
+		return assignEquipment(tx, equipmentID, toUserID, AssignOptions{DueAt: dueAt, Note: reason})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.TransferredAt = time.Now()
+	return result, nil
+}
+
+// GetEquipmentDetails возвращает карточку оборудования: срок возврата и место размещения,
+// сотрудника, за которым оно закреплено, и последние DetailsHistoryLimit записей истории
+func (s *EquipmentService) GetEquipmentDetails(id int) (*EquipmentDetails, error) {
+	var (
+		details      EquipmentDetails
+		assigneeName sql.NullString
+		orderID      sql.NullInt64
+		orderNumber  sql.NullString
+		vendorID     sql.NullInt64
+		vendorName   sql.NullString
+	)
+	equipment := &details.Equipment
 	err := s.db.QueryRow(
-		"SELECT id, model, status, assigned_to FROM equipment WHERE id = $1", id,
-	).Scan(&equipment.ID, &equipment.Model, &equipment.Status, &equipment.AssignedTo)
+		`SELECT e.id, e.model, e.serial_number, e.status, e.assigned_to, COALESCE(e.location, ''), COALESCE(e.category, ''),
+		e.purchase_cost, e.purchase_date, e.warranty_expires_at, COALESCE(e.support_contract, ''),
+		e.currency, COALESCE(e.depreciation_method, ''), e.useful_life_months, e.purchase_order_line_id, e.version, l.due_at, emp.name,
+		po.id, po.number, v.id, v.name
+		FROM equipment e
+		LEFT JOIN equipment_logs l ON l.equipment_id = e.id AND l.returned_at IS NULL
+		LEFT JOIN employees emp ON emp.id = e.assigned_to
+		LEFT JOIN purchase_order_lines pol ON pol.id = e.purchase_order_line_id
+		LEFT JOIN purchase_orders po ON po.id = pol.order_id
+		LEFT JOIN vendors v ON v.id = po.vendor_id
+		WHERE e.id = $1`, id,
+	).Scan(&equipment.ID, &equipment.Model, &equipment.SerialNumber, &equipment.Status, &equipment.AssignedTo,
+		&equipment.Location, &equipment.Category, &equipment.PurchaseCost, &equipment.PurchaseDate, &equipment.WarrantyExpiresAt,
+		&equipment.SupportContract, &equipment.Currency, &equipment.DepreciationMethod, &equipment.UsefulLifeMonths,
+		&equipment.PurchaseOrderLineID, &equipment.Version, &equipment.DueAt, &assigneeName,
+		&orderID, &orderNumber, &vendorID, &vendorName)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("оборудование с id %d не найдено", id)
+			return nil, fmt.Errorf("%w (id %d)", ErrEquipmentNotFound, id)
 		}
 		return nil, fmt.Errorf("ошибка при получении оборудования: %v", err)
 	}
-	log.Printf("ID: %d, Model: %s, Status: %s, AssignedTo: %v", equipment.ID, equipment.Model, equipment.Status, equipment.AssignedTo)
+	equipment.Overdue = equipment.DueAt != nil && equipment.DueAt.Before(time.Now())
+	details.AssetTag = AssetTag(equipment.ID)
+	if equipment.AssignedTo != nil {
+		details.Assignee = &Employee{ID: *equipment.AssignedTo, Name: assigneeName.String}
+	}
+	if orderID.Valid {
+		details.Source = &EquipmentSource{
+			PurchaseOrderID:     int(orderID.Int64),
+			PurchaseOrderNumber: orderNumber.String,
+			VendorID:            int(vendorID.Int64),
+			VendorName:          vendorName.String,
+		}
+	}
 
-	return &equipment, nil
+	if details.RecentHistory, err = queryHistory(s.db, id, DetailsHistoryLimit); err != nil {
+		return nil, err
+	}
+
+	return &details, nil
+}
+
+// GetOverdueEquipment возвращает выдачи, срок возврата которых истёк к моменту now
+func (s *EquipmentService) GetOverdueEquipment(now time.Time) ([]OverdueItem, error) {
+	rows, err := s.db.Query(
+		`SELECT e.id, e.model, COALESCE(e.serial_number, ''), l.user_id, COALESCE(emp.name, ''), l.issued_at, l.due_at
+		FROM equipment_logs l
+		JOIN equipment e ON e.id = l.equipment_id
+		LEFT JOIN employees emp ON emp.id = l.user_id
+		WHERE l.returned_at IS NULL AND l.due_at IS NOT NULL AND l.due_at < $1
+		ORDER BY l.due_at`, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении просроченного оборудования: %v", err)
+	}
+	return scanOverdue(rows, now)
+}
+
+// ClaimOverdueNotifications отмечает время уведомления у просроченных выдач, о которых не сообщали
+// последние renotify, и возвращает их. Отметка ставится тем же запросом, что и выборка, поэтому
+// о каждой выдаче сообщается не чаще раза в renotify даже при нескольких экземплярах сервера.
+func (s *EquipmentService) ClaimOverdueNotifications(now time.Time, renotify time.Duration) ([]OverdueItem, error) {
+	rows, err := s.db.Query(
+		`WITH claimed AS (
+			UPDATE equipment_logs SET overdue_notified_at = $1
+			WHERE returned_at IS NULL AND due_at IS NOT NULL AND due_at < $1
+			AND (overdue_notified_at IS NULL OR overdue_notified_at <= $2)
+			RETURNING equipment_id, user_id, issued_at, due_at
+		)
+		SELECT e.id, e.model, COALESCE(e.serial_number, ''), c.user_id, COALESCE(emp.name, ''), c.issued_at, c.due_at
+		FROM claimed c
+		JOIN equipment e ON e.id = c.equipment_id
+		LEFT JOIN employees emp ON emp.id = c.user_id
+		ORDER BY c.due_at`, now, now.Add(-renotify),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при отметке уведомлений о просрочке: %v", err)
+	}
+	return scanOverdue(rows, now)
+}
+
+// scanOverdue читает просроченные выдачи и считает дни просрочки к моменту now
+func scanOverdue(rows *sql.Rows, now time.Time) ([]OverdueItem, error) {
+	defer rows.Close()
+
+	items := []OverdueItem{}
+	for rows.Next() {
+		var item OverdueItem
+		if err := rows.Scan(&item.EquipmentID, &item.Model, &item.SerialNumber, &item.UserID, &item.UserName, &item.IssuedAt, &item.DueAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		item.AssetTag = AssetTag(item.EquipmentID)
+		item.OverdueDays = int(now.Sub(item.DueAt).Hours() / 24)
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при переборе строк: %v", err)
+	}
+
+	return items, nil
+}
+
+// GetWarrantyExpiring возвращает оборудование, гарантия которого истекает в ближайшие within после now,
+// начиная с ближайшего срока; списанное и утилизированное оборудование в отчёт не попадает
+func (s *EquipmentService) GetWarrantyExpiring(now time.Time, within time.Duration) ([]WarrantyItem, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	rows, err := s.db.Query(
+		`SELECT id, model, COALESCE(serial_number, ''), COALESCE(category, ''), assigned_to,
+		purchase_date, warranty_expires_at, COALESCE(support_contract, '')
+		FROM equipment
+		WHERE warranty_expires_at >= $1 AND warranty_expires_at <= $2 AND status NOT IN ($3, $4)
+		ORDER BY warranty_expires_at, id`,
+		today, now.Add(within), HistoryStatusWrittenOff, EquipmentStatusDisposed,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении оборудования с истекающей гарантией: %v", err)
+	}
+	return scanWarranty(rows, today)
+}
+
+// ClaimWarrantyNotifications запоминает дату окончания гарантии, о которой предупредили, у оборудования
+// из отчёта GetWarrantyExpiring и возвращает только то, о чём по этой дате ещё не предупреждали.
+// При продлении гарантии предупреждение о новой дате придёт снова.
+func (s *EquipmentService) ClaimWarrantyNotifications(now time.Time, within time.Duration) ([]WarrantyItem, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	rows, err := s.db.Query(
+		`WITH claimed AS (
+			UPDATE equipment SET warranty_notified_for = warranty_expires_at
+			WHERE warranty_expires_at >= $1 AND warranty_expires_at <= $2 AND status NOT IN ($3, $4)
+			AND warranty_notified_for IS DISTINCT FROM warranty_expires_at
+			RETURNING id, model, COALESCE(serial_number, '') AS serial_number, COALESCE(category, '') AS category, assigned_to,
+			purchase_date, warranty_expires_at, COALESCE(support_contract, '') AS support_contract
+		)
+		SELECT * FROM claimed ORDER BY warranty_expires_at, id`,
+		today, now.Add(within), HistoryStatusWrittenOff, EquipmentStatusDisposed,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при отметке предупреждений об окончании гарантии: %v", err)
+	}
+	return scanWarranty(rows, today)
+}
+
+// scanWarranty читает оборудование с истекающей гарантией и считает оставшиеся дни от today
+func scanWarranty(rows *sql.Rows, today time.Time) ([]WarrantyItem, error) {
+	defer rows.Close()
+
+	items := []WarrantyItem{}
+	for rows.Next() {
+		var item WarrantyItem
+		if err := rows.Scan(&item.EquipmentID, &item.Model, &item.SerialNumber, &item.Category, &item.AssignedTo,
+			&item.PurchaseDate, &item.WarrantyExpiresAt, &item.SupportContract); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		item.AssetTag = AssetTag(item.EquipmentID)
+		item.DaysLeft = int(item.WarrantyExpiresAt.Sub(today).Hours() / 24)
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при переборе строк: %v", err)
+	}
+
+	return items, nil
 }
 
 // CreateEquipment создает новую единицу оборудования в базе данных
 func (s *EquipmentService) CreateEquipment(model, serialNumber, status string) (*Equipment, error) {
+	if err := ValidateNewEquipment(model, serialNumber, status); err != nil {
+		return nil, err
+	}
+	return createEquipment(s.db, model, serialNumber, status)
+}
+
+// createEquipment создает оборудование в рамках переданной транзакции без проверки данных
+func createEquipment(q queryer, model, serialNumber, status string) (*Equipment, error) {
 	var id int
-	err := s.db.QueryRow(
+	err := q.QueryRow(
 		"INSERT INTO equipment (model, serial_number, status) VALUES ($1, $2, $3) RETURNING id",
 		model, serialNumber, status,
 	).Scan(&id)
@@ -94,12 +641,29 @@ func (s *EquipmentService) CreateEquipment(model, serialNumber, status string) (
 func (s *EquipmentService) GetEquipmentByID(id int) (*Equipment, error) {
 	var equipment Equipment
 	err := s.db.QueryRow(
-		"SELECT id, model, serial_number, status, assigned_to FROM equipment WHERE id = $1", id,
+		"SELECT id, model, serial_number, status, assigned_to, version, updated_at FROM equipment WHERE id = $1", id,
+	).Scan(&equipment.ID, &equipment.Model, &equipment.SerialNumber, &equipment.Status, &equipment.AssignedTo, &equipment.Version, &equipment.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w (id %d)", ErrEquipmentNotFound, id)
+		}
+		return nil, fmt.Errorf("ошибка при получении оборудования: %v", err)
+	}
+
+	return &equipment, nil
+}
+
+// GetEquipmentBySerialNumber возвращает оборудование по серийному номеру
+func (s *EquipmentService) GetEquipmentBySerialNumber(serialNumber string) (*Equipment, error) {
+	var equipment Equipment
+	err := s.db.QueryRow(
+		"SELECT id, model, serial_number, status, assigned_to FROM equipment WHERE serial_number = $1", serialNumber,
 	).Scan(&equipment.ID, &equipment.Model, &equipment.SerialNumber, &equipment.Status, &equipment.AssignedTo)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("оборудование с id %d не найдено", id)
+			return nil, fmt.Errorf("%w (серийный номер %s)", ErrEquipmentNotFound, serialNumber)
 		}
 		return nil, fmt.Errorf("ошибка при получении оборудования: %v", err)
 	}
@@ -135,25 +699,362 @@ func (s *EquipmentService) GetAllEquipment() ([]Equipment, error) {
 	return equipmentList, nil
 }
 
+// FindEquipment возвращает оборудование, удовлетворяющее фильтру
+func (s *EquipmentService) FindEquipment(filter EquipmentFilter) ([]Equipment, error) {
+	query := "SELECT id, model, serial_number, status, assigned_to FROM equipment"
+	where, args := filter.where("")
+	query += where + " ORDER BY id"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении оборудования по фильтру: %v", err)
+	}
+	defer rows.Close()
+
+	var equipmentList []Equipment
+	for rows.Next() {
+		var equipment Equipment
+		if err := rows.Scan(&equipment.ID, &equipment.Model, &equipment.SerialNumber, &equipment.Status, &equipment.AssignedTo); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		equipmentList = append(equipmentList, equipment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при переборе строк: %v", err)
+	}
+
+	return equipmentList, nil
+}
+
 // UpdateEquipment обновляет данные оборудования
 func (s *EquipmentService) UpdateEquipment(id int, model, serialNumber, status string) error {
-	_, err := s.db.Exec(
-		"UPDATE equipment SET model = $1, serial_number = $2, status = $3 WHERE id = $4",
-		model, serialNumber, status, id,
-	)
+	_, err := s.UpdateEquipmentIfMatch(id, 0, model, serialNumber, status)
+	return err
+}
+
+// UpdateEquipmentIfMatch обновляет данные оборудования, если его текущая версия равна version,
+// и возвращает новую версию; version 0 означает обновление без проверки версии
+func (s *EquipmentService) UpdateEquipmentIfMatch(id, version int, model, serialNumber, status string) (int, error) {
+	if err := ValidateEquipment(model, serialNumber, status); err != nil {
+		return 0, err
+	}
+
+	var updated int
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		locked, err := lockEquipment(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := locked.checkVersion(id, version); err != nil {
+			return err
+		}
+		v := &ValidationError{}
+		checkStatusChange(v, locked.Status, status)
+		if err := v.ErrOrNil(); err != nil {
+			return err
+		}
+
+		err = tx.QueryRow(
+			"UPDATE equipment SET model = $1, serial_number = $2, status = $3, "+bumpVersion+" WHERE id = $4 RETURNING version",
+			model, serialNumber, status, id,
+		).Scan(&updated)
+		if err != nil {
+			return fmt.Errorf("ошибка при обновлении оборудования: %v", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("ошибка при обновлении оборудования: %v", err)
+		return 0, err
 	}
+	return updated, nil
+}
 
-	return nil
+// EquipmentPatch частичное обновление оборудования; nil означает, что поле не изменяется,
+// а пустая строка — что необязательное поле очищается
+type EquipmentPatch struct {
+	Model        *string
+	SerialNumber *string
+	Status       *string
+	Location     *string
+	Category     *string
+	// PurchaseCost стоимость в виде десятичной строки
+	PurchaseCost *string
+	// PurchaseDate и WarrantyExpiresAt даты в формате YYYY-MM-DD
+	PurchaseDate      *string
+	WarrantyExpiresAt *string
+	SupportContract   *string
+	// Currency код валюты ISO 4217; пустая строка возвращает валюту по умолчанию
+	Currency           *string
+	DepreciationMethod *string
+	// UsefulLifeMonths срок полезного использования в виде десятичной строки
+	UsefulLifeMonths *string
+}
+
+// PatchEquipment изменяет только переданные поля оборудования и возвращает обновлённую запись.
+// Изменённая запись проверяется целиком; version 0 означает обновление без проверки версии.
+func (s *EquipmentService) PatchEquipment(id, version int, patch EquipmentPatch) (*Equipment, error) {
+	var equipment Equipment
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		var location, category, supportContract, method sql.NullString
+		err := tx.QueryRow(
+			`SELECT id, model, serial_number, status, assigned_to, location, category, purchase_cost,
+			purchase_date, warranty_expires_at, support_contract, currency, depreciation_method, useful_life_months, version
+			FROM equipment WHERE id = $1 FOR UPDATE`, id,
+		).Scan(&equipment.ID, &equipment.Model, &equipment.SerialNumber, &equipment.Status, &equipment.AssignedTo,
+			&location, &category, &equipment.PurchaseCost,
+			&equipment.PurchaseDate, &equipment.WarrantyExpiresAt, &supportContract,
+			&equipment.Currency, &method, &equipment.UsefulLifeMonths, &equipment.Version)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w (id %d)", ErrEquipmentNotFound, id)
+			}
+			return fmt.Errorf("ошибка при получении оборудования: %v", err)
+		}
+		if version > 0 && equipment.Version != version {
+			return fmt.Errorf("%w (id %d, ожидалась версия %d, текущая %d)", ErrVersionMismatch, id, version, equipment.Version)
+		}
+		if equipment.Status == EquipmentStatusDisposed {
+			return fmt.Errorf("%w (id %d)", ErrEquipmentDisposed, id)
+		}
+		equipment.Location = location.String
+		equipment.Category = category.String
+		equipment.SupportContract = supportContract.String
+		equipment.DepreciationMethod = method.String
+
+		currentStatus := equipment.Status
+		applyPatch(&equipment.Model, patch.Model)
+		applyPatch(&equipment.SerialNumber, patch.SerialNumber)
+		applyPatch(&equipment.Status, patch.Status)
+		applyPatch(&equipment.Location, patch.Location)
+		applyPatch(&equipment.Category, patch.Category)
+		applyPatch(&equipment.SupportContract, patch.SupportContract)
+		applyPatch(&equipment.Currency, patch.Currency)
+		applyPatch(&equipment.DepreciationMethod, patch.DepreciationMethod)
+		if equipment.Currency == "" {
+			equipment.Currency = DefaultCurrency
+		}
+
+		v := &ValidationError{}
+		checkEquipment(v, equipment.Model, equipment.SerialNumber, equipment.Status)
+		checkStatusChange(v, currentStatus, equipment.Status)
+		v.MaxLength("location", equipment.Location, maxLocationLength)
+		v.MaxLength("category", equipment.Category, maxCategoryLength)
+		if patch.PurchaseCost != nil {
+			equipment.PurchaseCost = parseCost(v, "purchase_cost", *patch.PurchaseCost)
+		}
+		if patch.PurchaseDate != nil {
+			equipment.PurchaseDate = parseDate(v, "purchase_date", *patch.PurchaseDate)
+		}
+		if patch.WarrantyExpiresAt != nil {
+			equipment.WarrantyExpiresAt = parseDate(v, "warranty_expires_at", *patch.WarrantyExpiresAt)
+		}
+		if equipment.PurchaseDate != nil && equipment.WarrantyExpiresAt != nil && equipment.WarrantyExpiresAt.Before(*equipment.PurchaseDate) {
+			v.Add("warranty_expires_at", validation.CodeOutOfRange, "гарантия не может истекать раньше даты покупки")
+		}
+		v.MaxLength("support_contract", equipment.SupportContract, maxSupportContractLength)
+		if !isCurrencyCode(equipment.Currency) {
+			v.Add("currency", validation.CodeInvalid, "ожидается трёхбуквенный код валюты ISO 4217, например USD")
+		}
+		if equipment.DepreciationMethod != "" {
+			checkDepreciationMethod(v, "depreciation_method", equipment.DepreciationMethod)
+		}
+		if patch.UsefulLifeMonths != nil {
+			equipment.UsefulLifeMonths = parseUsefulLife(v, "useful_life_months", *patch.UsefulLifeMonths)
+		}
+		if err := v.ErrOrNil(); err != nil {
+			return err
+		}
+
+		return tx.QueryRow(
+			"UPDATE equipment SET model = $1, serial_number = $2, status = $3, location = NULLIF($4, ''), category = NULLIF($5, ''), "+
+				"purchase_cost = $6, purchase_date = $7, warranty_expires_at = $8, support_contract = NULLIF($9, ''), "+
+				"currency = $10, depreciation_method = NULLIF($11, ''), useful_life_months = $12, "+
+				bumpVersion+" WHERE id = $13 RETURNING version, updated_at",
+			equipment.Model, equipment.SerialNumber, equipment.Status, equipment.Location, equipment.Category,
+			equipment.PurchaseCost, equipment.PurchaseDate, equipment.WarrantyExpiresAt, equipment.SupportContract,
+			equipment.Currency, equipment.DepreciationMethod, equipment.UsefulLifeMonths, id,
+		).Scan(&equipment.Version, &equipment.UpdatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &equipment, nil
+}
+
+// parseCost разбирает стоимость из частичного обновления; пустая строка очищает значение
+func parseCost(v *ValidationError, field, value string) *float64 {
+	if value == "" {
+		return nil
+	}
+	cost, err := strconv.ParseFloat(value, 64)
+	if err != nil || cost < 0 || math.IsInf(cost, 0) {
+		v.Add(field, validation.CodeInvalid, "ожидается неотрицательное число")
+		return nil
+	}
+	return &cost
+}
+
+// parseDate разбирает дату в формате YYYY-MM-DD из частичного обновления; пустая строка очищает значение
+func parseDate(v *ValidationError, field, value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		v.Add(field, validation.CodeInvalid, "ожидается дата в формате YYYY-MM-DD")
+		return nil
+	}
+	return &date
+}
+
+// isCurrencyCode проверяет, что код валюты состоит из трёх заглавных латинских букв
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// applyPatch заменяет значение поля, если оно передано в частичном обновлении
+func applyPatch(field *string, value *string) {
+	if value != nil {
+		*field = *value
+	}
 }
 
 // DeleteEquipment удаляет оборудование из базы данных
 func (s *EquipmentService) DeleteEquipment(id int) error {
-	_, err := s.db.Exec("DELETE FROM equipment WHERE id = $1", id)
+	return s.DeleteEquipmentIfMatch(id, 0)
+}
+
+// DeleteEquipmentIfMatch удаляет оборудование, если его текущая версия равна version;
+// version 0 означает удаление без проверки версии
+func (s *EquipmentService) DeleteEquipmentIfMatch(id, version int) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		return deleteEquipmentIfMatch(tx, id, version)
+	})
+}
+
+// UpdateEquipmentStatus изменяет только статус оборудования
+func (s *EquipmentService) UpdateEquipmentStatus(id int, status string) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		return updateEquipmentStatus(tx, id, status)
+	})
+}
+
+// MoveEquipment изменяет место размещения оборудования
+func (s *EquipmentService) MoveEquipment(id int, location string) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		return moveEquipment(tx, id, location)
+	})
+}
+
+// deleteEquipment удаляет оборудование в рамках переданной транзакции
+func deleteEquipment(q queryer, id int) error {
+	return deleteEquipmentIfMatch(q, id, 0)
+}
+
+// deleteEquipmentIfMatch удаляет оборудование с проверкой версии в рамках переданной транзакции;
+// утилизированное оборудование не удаляется, чтобы сохранить акт и историю
+func deleteEquipmentIfMatch(q queryer, id, version int) error {
+	locked, err := lockEquipment(q, id)
 	if err != nil {
-		return fmt.Errorf("ошибка при удалении оборудования: %v", err)
+		return err
+	}
+	if err := locked.checkVersion(id, version); err != nil {
+		return err
 	}
 
+	if _, err := q.Exec("DELETE FROM equipment WHERE id = $1", id); err != nil {
+		return deleteError(err, id)
+	}
+	return nil
+}
+
+// deleteError отличает удаление оборудования, на которое ссылаются другие записи (например, акт
+// об утилизации), от прочих ошибок базы данных
+func deleteError(err error, id int) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+		return fmt.Errorf("%w (id %d)", ErrEquipmentReferenced, id)
+	}
+	return fmt.Errorf("ошибка при удалении оборудования: %v", err)
+}
+
+// updateEquipmentStatus проверяет и изменяет статус оборудования в рамках переданной транзакции;
+// статусы процессов так не устанавливаются и не снимаются
+func updateEquipmentStatus(q queryer, id int, status string) error {
+	v := &ValidationError{}
+	v.RequireString("status", status, maxStatusLength)
+	if err := v.ErrOrNil(); err != nil {
+		return err
+	}
+	locked, err := lockEquipment(q, id)
+	if err != nil {
+		return err
+	}
+	checkStatusChange(v, locked.Status, status)
+	if err := v.ErrOrNil(); err != nil {
+		return err
+	}
+	return setEquipmentStatus(q, id, status)
+}
+
+// changeWorkflowStatus блокирует оборудование и устанавливает статус процесса: утилизации, списания или ремонта
+func changeWorkflowStatus(q queryer, id int, status string) error {
+	if _, err := lockEquipment(q, id); err != nil {
+		return err
+	}
+	return setEquipmentStatus(q, id, status)
+}
+
+// setEquipmentStatus записывает статус оборудования, уже заблокированного в рамках транзакции
+func setEquipmentStatus(q queryer, id int, status string) error {
+	result, err := q.Exec("UPDATE equipment SET status = $1, "+bumpVersion+" WHERE id = $2", status, id)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении статуса оборудования: %v", err)
+	}
+	return requireAffected(result, id)
+}
+
+// moveEquipment проверяет и изменяет место размещения оборудования в рамках переданной транзакции;
+// утилизированное оборудование не перемещается
+func moveEquipment(q queryer, id int, location string) error {
+	v := &ValidationError{}
+	v.RequireString("location", location, maxLocationLength)
+	if err := v.ErrOrNil(); err != nil {
+		return err
+	}
+	if _, err := lockEquipment(q, id); err != nil {
+		return err
+	}
+
+	result, err := q.Exec("UPDATE equipment SET location = $1, "+bumpVersion+" WHERE id = $2", location, id)
+	if err != nil {
+		return fmt.Errorf("ошибка при перемещении оборудования: %v", err)
+	}
+	return requireAffected(result, id)
+}
+
+// requireAffected возвращает ErrEquipmentNotFound, если запрос не затронул ни одной строки
+func requireAffected(result sql.Result, id int) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении числа изменённых строк: %v", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w (id %d)", ErrEquipmentNotFound, id)
+	}
 	return nil
 }
@@ -0,0 +1,49 @@
+package services
+
+import "errors"
+
+// Ошибки сервисов, по которым обработчики подбирают HTTP-статус ответа
+var (
+	ErrEquipmentNotFound         = errors.New("оборудование не найдено")
+	ErrEmployeeNotFound          = errors.New("сотрудник не найден")
+	ErrEquipmentAssigned         = errors.New("оборудование уже закреплено за сотрудником")
+	ErrEquipmentNotAssigned      = errors.New("оборудование ни за кем не закреплено")
+	ErrReservationNotFound       = errors.New("бронирование не найдено")
+	ErrReservationConflict       = errors.New("время бронирования пересекается с другим бронированием или выдачей")
+	ErrInvalidReservation        = errors.New("некорректный интервал бронирования")
+	ErrVersionMismatch           = errors.New("запись была изменена другим пользователем")
+	ErrIdempotencyKeyReused      = errors.New("ключ идемпотентности уже использован для другого запроса")
+	ErrIdempotencyInProgress     = errors.New("запрос с этим ключом идемпотентности ещё выполняется")
+	ErrEmployeeInactive          = errors.New("сотрудник уволен или находится в процессе увольнения")
+	ErrReturnTaskNotFound        = errors.New("задача на возврат не найдена")
+	ErrReturnTaskClosed          = errors.New("задача на возврат уже закрыта")
+	ErrOffboardingIncomplete     = errors.New("не всё оборудование сотрудника возвращено или списано")
+	ErrKitNotFound               = errors.New("комплект не найден")
+	ErrKitShortage               = errors.New("недостаточно свободного оборудования для комплекта")
+	ErrPolicyNotFound            = errors.New("политика выдачи не найдена")
+	ErrPolicyViolation           = errors.New("выдача нарушает политики выдачи оборудования")
+	ErrPolicyOverrideDenied      = errors.New("исключение из политик выдачи может разрешить только администратор")
+	ErrRequestNotFound           = errors.New("заявка на оборудование не найдена")
+	ErrRequestClosed             = errors.New("решение по заявке уже принято")
+	ErrRequestNotApproved        = errors.New("заявка не одобрена")
+	ErrApprovalDenied            = errors.New("решение по заявке может принять только руководитель или администратор")
+	ErrMaintenanceNotFound       = errors.New("запись о ремонте не найдена")
+	ErrMaintenanceOpen           = errors.New("оборудование уже находится в ремонте")
+	ErrMaintenanceClosed         = errors.New("ремонт уже завершён")
+	ErrScheduleNotFound          = errors.New("расписание обслуживания не найдено")
+	ErrMaintenanceTaskNotFound   = errors.New("задача обслуживания не найдена")
+	ErrMaintenanceTaskDone       = errors.New("задача обслуживания уже выполнена")
+	ErrDepreciationRuleNotFound  = errors.New("правило амортизации не найдено")
+	ErrVendorNotFound            = errors.New("поставщик не найден")
+	ErrPurchaseOrderNotFound     = errors.New("заказ поставщику не найден")
+	ErrPurchaseOrderLineNotFound = errors.New("позиция заказа не найдена")
+	ErrPurchaseOrderLineReceived = errors.New("позиция заказа уже принята полностью")
+	ErrDisposalNotFound          = errors.New("заявка на утилизацию не найдена")
+	ErrDisposalPending           = errors.New("по оборудованию уже есть незавершённая заявка на утилизацию")
+	ErrDisposalClosed            = errors.New("решение по заявке на утилизацию уже принято")
+	ErrDataWipeRequired          = errors.New("очистка данных не подтверждена")
+	ErrDisposalNotCompleted      = errors.New("утилизация не завершена, акт недоступен")
+	ErrEquipmentDisposed         = errors.New("оборудование утилизировано")
+	ErrEquipmentWrittenOff       = errors.New("оборудование списано")
+	ErrEquipmentReferenced       = errors.New("оборудование нельзя удалить: на него ссылаются другие записи")
+)
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"inva/pkg/export"
+	"strings"
+	"time"
+)
+
+// HistoryFilter описывает условия отбора записей истории выдачи для выгрузки
+type HistoryFilter struct {
+	EquipmentID int
+	UserID      int
+	From        *time.Time
+	To          *time.Time
+}
+
+// ExportService выгружает данные инвентаризации построчно, не загружая их целиком в память
+type ExportService struct {
+	db *sql.DB
+}
+
+// NewExportService создаёт новый экземпляр ExportService
+func NewExportService(db *sql.DB) *ExportService {
+	return &ExportService{db: db}
+}
+
+// ExportEquipment выгружает оборудование вместе с именем текущего владельца
+func (s *ExportService) ExportEquipment(filter EquipmentFilter, w export.Writer) error {
+	where, args := filter.where("e.")
+	query := `SELECT e.id, e.model, COALESCE(e.serial_number, ''), e.status, e.assigned_to, COALESCE(emp.name, '')
+		FROM equipment e LEFT JOIN employees emp ON emp.id = e.assigned_to` + where + " ORDER BY e.id"
+
+	columns := []string{"id", "asset_tag", "model", "serial_number", "status", "assigned_to", "assignee_name"}
+	return s.stream(w, columns, query, args, func(rows *sql.Rows) ([]interface{}, error) {
+		var id int
+		var model, serialNumber, status, assigneeName string
+		var assignedTo *int
+		if err := rows.Scan(&id, &model, &serialNumber, &status, &assignedTo, &assigneeName); err != nil {
+			return nil, err
+		}
+		return []interface{}{id, AssetTag(id), model, serialNumber, status, assignedTo, assigneeName}, nil
+	})
+}
+
+// ExportEmployees выгружает сотрудников
+func (s *ExportService) ExportEmployees(w export.Writer) error {
+	columns := []string{"id", "badge", "name"}
+	return s.stream(w, columns, "SELECT id, name FROM employees ORDER BY id", nil, func(rows *sql.Rows) ([]interface{}, error) {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		return []interface{}{id, BadgeID(id), name}, nil
+	})
+}
+
+// ExportHistory выгружает историю выдачи оборудования
+func (s *ExportService) ExportHistory(filter HistoryFilter, w export.Writer) error {
+	var conditions []string
+	var args []interface{}
+	if filter.EquipmentID != 0 {
+		args = append(args, filter.EquipmentID)
+		conditions = append(conditions, fmt.Sprintf("l.equipment_id = $%d", len(args)))
+	}
+	if filter.UserID != 0 {
+		args = append(args, filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("l.user_id = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("l.issued_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("l.issued_at < $%d", len(args)))
+	}
+
+	query := `SELECT l.id, l.equipment_id, COALESCE(e.model, ''), l.user_id, COALESCE(emp.name, ''),
+		l.issued_at, l.due_at, l.returned_at, l.status, COALESCE(l.note, '')
+		FROM equipment_logs l
+		LEFT JOIN equipment e ON e.id = l.equipment_id
+		LEFT JOIN employees emp ON emp.id = l.user_id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY l.issued_at, l.id"
+
+	columns := []string{"id", "equipment_id", "asset_tag", "model", "user_id", "user_name", "issued_at", "due_at", "returned_at", "status", "note"}
+	return s.stream(w, columns, query, args, func(rows *sql.Rows) ([]interface{}, error) {
+		var entry HistoryEntry
+		var model string
+		if err := rows.Scan(&entry.ID, &entry.EquipmentID, &model, &entry.UserID, &entry.UserName,
+			&entry.IssuedAt, &entry.DueAt, &entry.ReturnedAt, &entry.Status, &entry.Note); err != nil {
+			return nil, err
+		}
+		return []interface{}{entry.ID, entry.EquipmentID, AssetTag(entry.EquipmentID), model, entry.UserID, entry.UserName,
+			entry.IssuedAt, entry.DueAt, entry.ReturnedAt, entry.Status, entry.Note}, nil
+	})
+}
+
+// stream выполняет запрос и передаёт строки в Writer по мере чтения из базы
+func (s *ExportService) stream(w export.Writer, columns []string, query string, args []interface{}, scan func(rows *sql.Rows) ([]interface{}, error)) error {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса выгрузки: %v", err)
+	}
+	defer rows.Close()
+
+	if err := w.WriteHeader(columns); err != nil {
+		return fmt.Errorf("ошибка при записи заголовка выгрузки: %v", err)
+	}
+
+	for rows.Next() {
+		values, err := scan(rows)
+		if err != nil {
+			return fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		if err := w.WriteRow(values); err != nil {
+			return fmt.Errorf("ошибка при записи строки выгрузки: %v", err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при переборе строк: %v", err)
+	}
+
+	return w.Close()
+}
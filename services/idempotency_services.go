@@ -0,0 +1,113 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// IdempotencyKeyTTL время, в течение которого повтор запроса с тем же ключом возвращает сохранённый ответ
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// IdempotentResponse сохранённый ответ на запрос с ключом идемпотентности
+type IdempotentResponse struct {
+	StatusCode int
+	Headers    map[string]string
+	Body       []byte
+}
+
+// IdempotencyService хранит ключи идемпотентности и ответы на исходные запросы
+type IdempotencyService struct {
+	db *sql.DB
+}
+
+// NewIdempotencyService создаёт новый экземпляр IdempotencyService
+func NewIdempotencyService(db *sql.DB) *IdempotencyService {
+	return &IdempotencyService{db: db}
+}
+
+// Begin резервирует ключ для запроса с хешем requestHash. Возвращает nil, если ключ новый и запрос нужно выполнить,
+// или сохранённый ответ, если запрос уже был выполнен. Повтор ключа с другим запросом возвращает ErrIdempotencyKeyReused,
+// а повтор во время выполнения исходного запроса — ErrIdempotencyInProgress.
+func (s *IdempotencyService) Begin(key, requestHash string) (*IdempotentResponse, error) {
+	// Просроченный ключ освобождается, чтобы его можно было использовать снова
+	if _, err := s.db.Exec(
+		"DELETE FROM idempotency_keys WHERE key = $1 AND created_at < $2",
+		key, time.Now().Add(-IdempotencyKeyTTL),
+	); err != nil {
+		return nil, fmt.Errorf("ошибка при удалении просроченного ключа идемпотентности: %v", err)
+	}
+
+	result, err := s.db.Exec(
+		"INSERT INTO idempotency_keys (key, request_hash) VALUES ($1, $2) ON CONFLICT (key) DO NOTHING",
+		key, requestHash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при сохранении ключа идемпотентности: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении числа изменённых строк: %v", err)
+	}
+	if affected > 0 {
+		return nil, nil
+	}
+
+	var (
+		storedHash string
+		statusCode sql.NullInt64
+		headers    []byte
+		body       []byte
+	)
+	err = s.db.QueryRow(
+		"SELECT request_hash, status_code, response_headers, response_body FROM idempotency_keys WHERE key = $1", key,
+	).Scan(&storedHash, &statusCode, &headers, &body)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Исходный запрос завершился ошибкой и освободил ключ между вставкой и чтением
+			return nil, fmt.Errorf("%w (ключ %s)", ErrIdempotencyInProgress, key)
+		}
+		return nil, fmt.Errorf("ошибка при получении ключа идемпотентности: %v", err)
+	}
+
+	if storedHash != requestHash {
+		return nil, fmt.Errorf("%w (ключ %s)", ErrIdempotencyKeyReused, key)
+	}
+	if !statusCode.Valid {
+		return nil, fmt.Errorf("%w (ключ %s)", ErrIdempotencyInProgress, key)
+	}
+
+	response := &IdempotentResponse{StatusCode: int(statusCode.Int64), Body: body}
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &response.Headers); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении сохранённых заголовков: %v", err)
+		}
+	}
+	return response, nil
+}
+
+// Complete сохраняет ответ на запрос, зарезервировавший ключ
+func (s *IdempotencyService) Complete(key string, response *IdempotentResponse) error {
+	headers, err := json.Marshal(response.Headers)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении заголовков: %v", err)
+	}
+
+	_, err = s.db.Exec(
+		"UPDATE idempotency_keys SET status_code = $2, response_headers = $3, response_body = $4, completed_at = NOW() WHERE key = $1",
+		key, response.StatusCode, headers, response.Body,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении ответа для ключа идемпотентности: %v", err)
+	}
+	return nil
+}
+
+// Release освобождает ключ незавершённого запроса, чтобы клиент мог повторить его
+func (s *IdempotencyService) Release(key string) error {
+	if _, err := s.db.Exec("DELETE FROM idempotency_keys WHERE key = $1 AND completed_at IS NULL", key); err != nil {
+		return fmt.Errorf("ошибка при освобождении ключа идемпотентности: %v", err)
+	}
+	return nil
+}
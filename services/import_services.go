@@ -0,0 +1,247 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"inva/pkg/validation"
+	"io"
+	"strings"
+)
+
+// Поля оборудования и сотрудников, которые можно загрузить из CSV
+var (
+	equipmentImportFields = []string{"model", "serial_number", "status"}
+	employeeImportFields  = []string{"name", "email"}
+)
+
+// defaultImportStatus статус оборудования, если колонка статуса пустая
+const defaultImportStatus = "available"
+
+// RowError описывает ошибку в строке загружаемого файла
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResult представляет результат загрузки CSV
+type ImportResult struct {
+	DryRun   bool       `json:"dry_run"`
+	Total    int        `json:"total"`
+	Imported int        `json:"imported"`
+	Errors   []RowError `json:"errors"`
+}
+
+// ImportService выполняет массовую загрузку оборудования и сотрудников из CSV
+type ImportService struct {
+	db *sql.DB
+}
+
+// NewImportService создаёт новый экземпляр ImportService
+func NewImportService(db *sql.DB) *ImportService {
+	return &ImportService{db: db}
+}
+
+// ImportEquipment загружает оборудование из CSV. mapping сопоставляет заголовки файла с полями
+// (model, serial_number, status); без сопоставления заголовки должны совпадать с именами полей.
+// Все строки сохраняются в одной транзакции; при любой ошибке не сохраняется ничего.
+func (s *ImportService) ImportEquipment(r io.Reader, mapping map[string]string, dryRun bool) (*ImportResult, error) {
+	records, result, err := readImportRecords(r, mapping, equipmentImportFields, []string{"model"}, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	serials := make(map[string]int)
+	for i, record := range records {
+		row := i + 2 // первая строка файла — заголовок
+		if record["status"] == "" {
+			record["status"] = defaultImportStatus
+		}
+		addValidationErrors(result, row, ValidateNewEquipment(record["model"], record["serial_number"], record["status"]))
+
+		if serial := record["serial_number"]; serial != "" {
+			if first, ok := serials[serial]; ok {
+				result.Errors = append(result.Errors, RowError{
+					Row: row, Field: "serial_number", Code: validation.CodeDuplicate,
+					Message: fmt.Sprintf("серийный номер повторяет строку %d", first),
+				})
+			} else {
+				serials[serial] = row
+			}
+		}
+	}
+
+	return result, s.commit(result, func(tx *sql.Tx, i int) error {
+		record := records[i]
+		_, err := createEquipment(tx, record["model"], record["serial_number"], record["status"])
+		return err
+	})
+}
+
+// ImportEmployees загружает сотрудников из CSV с колонками name и email по тем же правилам, что и ImportEquipment;
+// email не должен повторяться ни в файле, ни у уже существующих сотрудников
+func (s *ImportService) ImportEmployees(r io.Reader, mapping map[string]string, dryRun bool) (*ImportResult, error) {
+	records, result, err := readImportRecords(r, mapping, employeeImportFields, []string{"name"}, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	emails := make(map[string]int)
+	for i, record := range records {
+		row := i + 2
+		addValidationErrors(result, row, ValidateEmployee(record["name"], record["email"]))
+
+		if email := strings.ToLower(record["email"]); email != "" {
+			if first, ok := emails[email]; ok {
+				result.Errors = append(result.Errors, RowError{
+					Row: row, Field: "email", Code: validation.CodeDuplicate,
+					Message: fmt.Sprintf("email повторяет строку %d", first),
+				})
+			} else {
+				emails[email] = row
+			}
+		}
+	}
+
+	return result, s.commit(result, func(tx *sql.Tx, i int) error {
+		record := records[i]
+		if err := checkEmailTaken(tx, record["email"], 0); err != nil {
+			return err
+		}
+		_, err := createEmployee(tx, &Employee{Name: record["name"], Email: record["email"]})
+		return err
+	})
+}
+
+// commit сохраняет все строки в одной транзакции, если проверка прошла успешно и это не пробный запуск
+func (s *ImportService) commit(result *ImportResult, insert func(tx *sql.Tx, i int) error) error {
+	if result.DryRun || len(result.Errors) > 0 {
+		return nil
+	}
+
+	var rowErr *RowError
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		for i := 0; i < result.Total; i++ {
+			if err := insert(tx, i); err != nil {
+				rowErr = &RowError{Row: i + 2, Message: err.Error()}
+				return err
+			}
+		}
+		return nil
+	})
+	if rowErr != nil {
+		// Ошибка базы данных в строке откатывает всю загрузку и возвращается как ошибка строки
+		result.Errors = append(result.Errors, *rowErr)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	result.Imported = result.Total
+	return nil
+}
+
+// readImportRecords читает CSV и возвращает строки в виде словарей поле → значение
+func readImportRecords(r io.Reader, mapping map[string]string, fields, required []string, dryRun bool) ([]map[string]string, *ImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, validation.New("file", validation.CodeRequired, "файл пуст")
+		}
+		return nil, nil, validation.New("file", validation.CodeMalformed, "%v", err)
+	}
+
+	columns, err := mapImportColumns(header, mapping, fields, required)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &ImportResult{DryRun: dryRun, Errors: []RowError{}}
+	var records []map[string]string
+	for {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, validation.New("file", validation.CodeMalformed, "%v", err)
+		}
+
+		record := make(map[string]string, len(fields))
+		for i, field := range columns {
+			if field != "" && i < len(values) {
+				record[field] = strings.TrimSpace(values[i])
+			}
+		}
+		records = append(records, record)
+	}
+
+	result.Total = len(records)
+	return records, result, nil
+}
+
+// mapImportColumns сопоставляет колонки файла с полями; пустая строка означает, что колонка пропускается
+func mapImportColumns(header []string, mapping map[string]string, fields, required []string) ([]string, error) {
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field] = true
+	}
+
+	normalized := make(map[string]string, len(mapping))
+	for column, field := range mapping {
+		normalized[strings.ToLower(strings.TrimSpace(column))] = field
+	}
+
+	v := &ValidationError{}
+	columns := make([]string, len(header))
+	seen := make(map[string]bool)
+	for i, column := range header {
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		field, mapped := normalized[name]
+		if !mapped {
+			field = name
+		}
+		if field == "" {
+			continue
+		}
+		if !known[field] {
+			v.Add("header", validation.CodeUnknownField, "колонка %q не соответствует ни одному полю (%s)", column, strings.Join(fields, ", "))
+			continue
+		}
+		if seen[field] {
+			v.Add("header", validation.CodeDuplicate, "поле %s указано в нескольких колонках", field)
+			continue
+		}
+		seen[field] = true
+		columns[i] = field
+	}
+
+	for _, field := range required {
+		if !seen[field] {
+			v.Add("header", validation.CodeRequired, "отсутствует обязательная колонка %s", field)
+		}
+	}
+
+	if err := v.ErrOrNil(); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// addValidationErrors добавляет ошибки проверки строки в результат загрузки
+func addValidationErrors(result *ImportResult, row int, err error) {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		return
+	}
+	for _, field := range validationErr.Fields {
+		result.Errors = append(result.Errors, RowError{Row: row, Field: field.Field, Code: field.Code, Message: field.Message})
+	}
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"inva/pkg/validation"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KitAvailableStatus статус оборудования, которое может быть выдано в составе комплекта
+const KitAvailableStatus = "available"
+
+// Kit представляет шаблон комплекта оборудования, например набор нового разработчика
+type Kit struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Items     []KitItem `json:"items"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// KitItem позиция комплекта: категория и (или) модель оборудования и нужное количество
+type KitItem struct {
+	Category string `json:"category,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Quantity int    `json:"quantity"`
+}
+
+// describe возвращает описание позиции для сообщений об ошибках
+func (i KitItem) describe() string {
+	switch {
+	case i.Category != "" && i.Model != "":
+		return i.Category + "/" + i.Model
+	case i.Model != "":
+		return i.Model
+	}
+	return i.Category
+}
+
+// KitShortage нехватка свободного оборудования по позиции комплекта
+type KitShortage struct {
+	Category  string `json:"category,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Requested int    `json:"requested"`
+	Available int    `json:"available"`
+}
+
+// KitShortageError возвращается, если на складе не хватает оборудования хотя бы по одной позиции;
+// в этом случае ничего не выдаётся
+type KitShortageError struct {
+	Kit       string        `json:"kit"`
+	Shortages []KitShortage `json:"shortages"`
+}
+
+// Error описывает недостающие позиции комплекта
+func (e *KitShortageError) Error() string {
+	parts := make([]string, len(e.Shortages))
+	for i, shortage := range e.Shortages {
+		item := KitItem{Category: shortage.Category, Model: shortage.Model}
+		parts[i] = fmt.Sprintf("%s: нужно %d, свободно %d", item.describe(), shortage.Requested, shortage.Available)
+	}
+	return fmt.Sprintf("%v (комплект %s; %s)", ErrKitShortage, e.Kit, strings.Join(parts, "; "))
+}
+
+// Unwrap позволяет сравнивать ошибку с ErrKitShortage через errors.Is
+func (e *KitShortageError) Unwrap() error {
+	return ErrKitShortage
+}
+
+// KitAssignedItem единица оборудования, выданная в составе комплекта
+type KitAssignedItem struct {
+	EquipmentID  int    `json:"equipment_id"`
+	AssetTag     string `json:"asset_tag"`
+	Model        string `json:"model"`
+	SerialNumber string `json:"serial_number"`
+	Category     string `json:"category,omitempty"`
+}
+
+// KitAssignment результат выдачи комплекта сотруднику
+type KitAssignment struct {
+	KitID      int               `json:"kit_id"`
+	Kit        string            `json:"kit"`
+	EmployeeID int               `json:"employee_id"`
+	DueAt      *time.Time        `json:"due_at,omitempty"`
+	Items      []KitAssignedItem `json:"items"`
+}
+
+// KitService предоставляет методы для работы с шаблонами комплектов и их выдачи
+type KitService struct {
+	db *sql.DB
+}
+
+// NewKitService создаёт новый экземпляр KitService
+func NewKitService(db *sql.DB) *KitService {
+	return &KitService{db: db}
+}
+
+// ValidateKit проверяет шаблон комплекта перед сохранением
+func ValidateKit(kit *Kit) error {
+	v := &ValidationError{}
+	v.RequireString("name", kit.Name, maxKitNameLength)
+	if _, err := strconv.Atoi(kit.Name); err == nil {
+		v.Add("name", validation.CodeInvalid, "название не может состоять только из цифр")
+	}
+	if len(kit.Items) == 0 {
+		v.Add("items", validation.CodeRequired, "комплект должен содержать хотя бы одну позицию")
+	}
+	for i, item := range kit.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		if item.Category == "" && item.Model == "" {
+			v.Add(field, validation.CodeRequired, "укажите категорию или модель")
+		}
+		v.MaxLength(field+".category", item.Category, maxCategoryLength)
+		v.MaxLength(field+".model", item.Model, maxModelLength)
+		if item.Quantity < 1 || item.Quantity > maxKitItemQuantity {
+			v.Add(field+".quantity", validation.CodeOutOfRange, "должно быть от 1 до %d", maxKitItemQuantity)
+		}
+	}
+	return v.ErrOrNil()
+}
+
+// CreateKit сохраняет шаблон комплекта вместе с позициями
+func (s *KitService) CreateKit(kit *Kit) (*Kit, error) {
+	if err := ValidateKit(kit); err != nil {
+		return nil, err
+	}
+
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		var exists bool
+		if err := tx.QueryRow(
+			"SELECT EXISTS (SELECT 1 FROM kits WHERE lower(name) = lower($1))", kit.Name,
+		).Scan(&exists); err != nil {
+			return fmt.Errorf("ошибка при проверке названия комплекта: %v", err)
+		}
+		if exists {
+			return validation.New("name", validation.CodeDuplicate, "комплект %q уже существует", kit.Name)
+		}
+
+		if err := tx.QueryRow(
+			"INSERT INTO kits (name) VALUES ($1) RETURNING id, created_at", kit.Name,
+		).Scan(&kit.ID, &kit.CreatedAt); err != nil {
+			return fmt.Errorf("ошибка при создании комплекта: %v", err)
+		}
+
+		for i, item := range kit.Items {
+			if _, err := tx.Exec(
+				"INSERT INTO kit_items (kit_id, position, category, model, quantity) VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)",
+				kit.ID, i, item.Category, item.Model, item.Quantity,
+			); err != nil {
+				return fmt.Errorf("ошибка при сохранении позиции комплекта: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return kit, nil
+}
+
+// GetAllKits возвращает все шаблоны комплектов с позициями
+func (s *KitService) GetAllKits() ([]Kit, error) {
+	rows, err := s.db.Query("SELECT id, name, created_at FROM kits ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении комплектов: %v", err)
+	}
+	defer rows.Close()
+
+	kits := []Kit{}
+	for rows.Next() {
+		var kit Kit
+		if err := rows.Scan(&kit.ID, &kit.Name, &kit.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении комплекта: %v", err)
+		}
+		kits = append(kits, kit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении комплектов: %v", err)
+	}
+
+	for i := range kits {
+		if kits[i].Items, err = queryKitItems(s.db, kits[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return kits, nil
+}
+
+// GetKit возвращает шаблон комплекта по идентификатору или названию
+func (s *KitService) GetKit(ref string) (*Kit, error) {
+	return findKit(s.db, ref, false)
+}
+
+// DeleteKit удаляет шаблон комплекта; уже выданное оборудование не затрагивается
+func (s *KitService) DeleteKit(ref string) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		kit, err := findKit(tx, ref, true)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM kit_items WHERE kit_id = $1", kit.ID); err != nil {
+			return fmt.Errorf("ошибка при удалении позиций комплекта: %v", err)
+		}
+		if _, err := tx.Exec("DELETE FROM kits WHERE id = $1", kit.ID); err != nil {
+			return fmt.Errorf("ошибка при удалении комплекта: %v", err)
+		}
+		return nil
+	})
+}
+
+// AssignKit подбирает свободное оборудование по позициям комплекта и выдаёт его сотруднику
+// в одной транзакции через assignEquipment. Если хотя бы по одной позиции оборудования не хватает,
+// транзакция откатывается и возвращается *KitShortageError со всеми недостающими позициями.
+func (s *KitService) AssignKit(employeeID int, ref string, opts AssignOptions) (*KitAssignment, error) {
+	var assignment *KitAssignment
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		kit, err := findKit(tx, ref, false)
+		if err != nil {
+			return err
+		}
+		if err := requireActiveEmployee(tx, employeeID); err != nil {
+			return err
+		}
+		if opts.Note == "" {
+			opts.Note = "комплект " + kit.Name
+		}
+
+		assignment = &KitAssignment{KitID: kit.ID, Kit: kit.Name, EmployeeID: employeeID, DueAt: opts.DueAt}
+		shortage := &KitShortageError{Kit: kit.Name}
+		for _, item := range kit.Items {
+			// Выданное по предыдущим позициям оборудование уже закреплено и не попадёт в выборку повторно,
+			// поэтому подобранное закрепляется сразу, даже если по позиции есть нехватка
+			picked, err := pickKitEquipment(tx, item)
+			if err != nil {
+				return err
+			}
+			for _, equipment := range picked {
+				if err := assignEquipment(tx, equipment.EquipmentID, employeeID, opts); err != nil {
+					return err
+				}
+				assignment.Items = append(assignment.Items, equipment)
+			}
+
+			if len(picked) < item.Quantity {
+				shortage.Shortages = append(shortage.Shortages, KitShortage{
+					Category:  item.Category,
+					Model:     item.Model,
+					Requested: item.Quantity,
+					Available: len(picked),
+				})
+			}
+		}
+
+		if len(shortage.Shortages) > 0 {
+			return shortage
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// pickKitEquipment блокирует до item.Quantity единиц свободного оборудования, подходящего под позицию комплекта;
+// строки, заблокированные параллельными выдачами, пропускаются
+func pickKitEquipment(q queryer, item KitItem) ([]KitAssignedItem, error) {
+	rows, err := q.Query(
+		`SELECT id, model, serial_number, COALESCE(category, '') FROM equipment
+		WHERE assigned_to IS NULL AND status = $1
+		AND ($2 = '' OR lower(category) = lower($2))
+		AND ($3 = '' OR lower(model) = lower($3))
+		ORDER BY id LIMIT $4 FOR UPDATE SKIP LOCKED`,
+		KitAvailableStatus, item.Category, item.Model, item.Quantity,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подборе оборудования для комплекта: %v", err)
+	}
+	defer rows.Close()
+
+	var picked []KitAssignedItem
+	for rows.Next() {
+		var equipment KitAssignedItem
+		if err := rows.Scan(&equipment.EquipmentID, &equipment.Model, &equipment.SerialNumber, &equipment.Category); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении оборудования: %v", err)
+		}
+		equipment.AssetTag = AssetTag(equipment.EquipmentID)
+		picked = append(picked, equipment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении оборудования: %v", err)
+	}
+	return picked, nil
+}
+
+// findKit находит шаблон комплекта по числовому идентификатору или названию без учёта регистра;
+// lock блокирует строку комплекта до конца транзакции
+func findKit(q queryer, ref string, lock bool) (*Kit, error) {
+	query := "SELECT id, name, created_at FROM kits WHERE lower(name) = lower($1)"
+	var arg interface{} = ref
+	if id, err := strconv.Atoi(ref); err == nil {
+		query = "SELECT id, name, created_at FROM kits WHERE id = $1"
+		arg = id
+	}
+	if lock {
+		query += " FOR UPDATE"
+	}
+
+	var kit Kit
+	if err := q.QueryRow(query, arg).Scan(&kit.ID, &kit.Name, &kit.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w (%s)", ErrKitNotFound, ref)
+		}
+		return nil, fmt.Errorf("ошибка при получении комплекта: %v", err)
+	}
+
+	items, err := queryKitItems(q, kit.ID)
+	if err != nil {
+		return nil, err
+	}
+	kit.Items = items
+	return &kit, nil
+}
+
+// queryKitItems возвращает позиции комплекта в порядке их добавления
+func queryKitItems(q queryer, kitID int) ([]KitItem, error) {
+	rows, err := q.Query(
+		"SELECT COALESCE(category, ''), COALESCE(model, ''), quantity FROM kit_items WHERE kit_id = $1 ORDER BY position",
+		kitID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении позиций комплекта: %v", err)
+	}
+	defer rows.Close()
+
+	items := []KitItem{}
+	for rows.Next() {
+		var item KitItem
+		if err := rows.Scan(&item.Category, &item.Model, &item.Quantity); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении позиции комплекта: %v", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении позиций комплекта: %v", err)
+	}
+	return items, nil
+}
@@ -0,0 +1,409 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"inva/pkg/schedule"
+	"inva/pkg/validation"
+	"strings"
+	"time"
+)
+
+// Статусы задачи планового обслуживания
+const (
+	MaintenanceTaskOpen = "open"
+	MaintenanceTaskDone = "done"
+)
+
+// Отбор задач планового обслуживания по сроку
+const (
+	TaskDueUpcoming = "upcoming"
+	TaskDueOverdue  = "overdue"
+)
+
+// Ограничения на длину полей расписания обслуживания
+const (
+	maxScheduleNameLength = 255
+	maxScheduleSpecLength = 100
+)
+
+// MaintenanceSchedule расписание планового обслуживания единицы оборудования или целой категории,
+// например калибровка лабораторных приборов раз в полгода
+type MaintenanceSchedule struct {
+	ID          int       `json:"id"`
+	EquipmentID *int      `json:"equipment_id,omitempty"`
+	Category    string    `json:"category,omitempty"`
+	Name        string    `json:"name"`
+	Spec        string    `json:"spec"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// MaintenanceTask задача планового обслуживания конкретной единицы оборудования
+type MaintenanceTask struct {
+	ID           int        `json:"id"`
+	ScheduleID   int        `json:"schedule_id"`
+	ScheduleName string     `json:"schedule_name"`
+	EquipmentID  int        `json:"equipment_id"`
+	AssetTag     string     `json:"asset_tag"`
+	Model        string     `json:"model"`
+	DueAt        time.Time  `json:"due_at"`
+	Status       string     `json:"status"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	Note         string     `json:"note,omitempty"`
+	Overdue      bool       `json:"overdue"`
+	// NextDueAt срок следующей задачи, созданной при выполнении этой
+	NextDueAt *time.Time `json:"next_due_at,omitempty"`
+}
+
+// TaskFilter условия отбора открытых задач планового обслуживания
+type TaskFilter struct {
+	// Due отбор по сроку: TaskDueUpcoming, TaskDueOverdue или пустая строка для всех открытых задач
+	Due string
+	// Within горизонт для предстоящих задач
+	Within      time.Duration
+	EquipmentID int
+	Now         time.Time
+}
+
+// MaintenanceScheduleService предоставляет методы для расписаний планового обслуживания и их задач
+type MaintenanceScheduleService struct {
+	db *sql.DB
+}
+
+// NewMaintenanceScheduleService создаёт новый экземпляр MaintenanceScheduleService
+func NewMaintenanceScheduleService(db *sql.DB) *MaintenanceScheduleService {
+	return &MaintenanceScheduleService{db: db}
+}
+
+// CreateSchedule проверяет и сохраняет расписание; задачи по нему создаёт планировщик
+func (s *MaintenanceScheduleService) CreateSchedule(sch *MaintenanceSchedule) (*MaintenanceSchedule, error) {
+	v := &ValidationError{}
+	v.RequireString("name", sch.Name, maxScheduleNameLength)
+	v.MaxLength("category", sch.Category, maxCategoryLength)
+	if (sch.EquipmentID == nil) == (sch.Category == "") {
+		v.Add("equipment_id", validation.CodeRequired, "укажите либо equipment_id, либо category")
+	}
+	v.RequireString("spec", sch.Spec, maxScheduleSpecLength)
+	if strings.TrimSpace(sch.Spec) != "" {
+		if _, err := schedule.Parse(sch.Spec); err != nil {
+			v.Add("spec", validation.CodeInvalid, "%v", err)
+		}
+	}
+	if err := v.ErrOrNil(); err != nil {
+		return nil, err
+	}
+
+	if sch.EquipmentID != nil {
+		var exists bool
+		if err := s.db.QueryRow("SELECT EXISTS (SELECT 1 FROM equipment WHERE id = $1)", *sch.EquipmentID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("ошибка при проверке оборудования: %v", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w (id %d)", ErrEquipmentNotFound, *sch.EquipmentID)
+		}
+	}
+
+	err := s.db.QueryRow(
+		`INSERT INTO maintenance_schedules (equipment_id, category, name, spec)
+		VALUES ($1, NULLIF($2, ''), $3, $4) RETURNING id, created_at`,
+		sch.EquipmentID, sch.Category, sch.Name, sch.Spec,
+	).Scan(&sch.ID, &sch.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании расписания обслуживания: %v", err)
+	}
+	return sch, nil
+}
+
+// GetAllSchedules возвращает все расписания планового обслуживания
+func (s *MaintenanceScheduleService) GetAllSchedules() ([]MaintenanceSchedule, error) {
+	rows, err := s.db.Query(
+		"SELECT id, equipment_id, COALESCE(category, ''), name, spec, created_at FROM maintenance_schedules ORDER BY id",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении расписаний обслуживания: %v", err)
+	}
+	defer rows.Close()
+
+	schedules := []MaintenanceSchedule{}
+	for rows.Next() {
+		var sch MaintenanceSchedule
+		if err := rows.Scan(&sch.ID, &sch.EquipmentID, &sch.Category, &sch.Name, &sch.Spec, &sch.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении расписания обслуживания: %v", err)
+		}
+		schedules = append(schedules, sch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении расписаний обслуживания: %v", err)
+	}
+	return schedules, nil
+}
+
+// DeleteSchedule удаляет расписание вместе с его задачами
+func (s *MaintenanceScheduleService) DeleteSchedule(id int) error {
+	result, err := s.db.Exec("DELETE FROM maintenance_schedules WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении расписания обслуживания: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении расписания обслуживания: %v", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w (id %d)", ErrScheduleNotFound, id)
+	}
+	return nil
+}
+
+// GenerateTasks создаёт по открытой задаче для каждой единицы оборудования, подпадающей под расписание
+// и ещё не имеющей открытой задачи по нему. Срок отсчитывается от последнего выполнения задачи,
+// а если её ещё не выполняли — от создания расписания. Возвращает созданные задачи.
+func (s *MaintenanceScheduleService) GenerateTasks(now time.Time) ([]MaintenanceTask, error) {
+	schedules, err := s.GetAllSchedules()
+	if err != nil {
+		return nil, err
+	}
+
+	var created []MaintenanceTask
+	for _, sch := range schedules {
+		spec, err := schedule.Parse(sch.Spec)
+		if err != nil {
+			return created, fmt.Errorf("некорректное расписание обслуживания %d: %v", sch.ID, err)
+		}
+
+		equipmentID := 0
+		if sch.EquipmentID != nil {
+			equipmentID = *sch.EquipmentID
+		}
+		rows, err := s.db.Query(
+			`SELECT e.id, (SELECT MAX(t.completed_at) FROM maintenance_tasks t WHERE t.schedule_id = $1 AND t.equipment_id = e.id)
+			FROM equipment e
+			WHERE (e.id = $2 OR ($3 <> '' AND lower(COALESCE(e.category, '')) = lower($3)))
+			AND e.status NOT IN ($4, $5)
+			AND NOT EXISTS (SELECT 1 FROM maintenance_tasks t WHERE t.schedule_id = $1 AND t.equipment_id = e.id AND t.status = $6)
+			ORDER BY e.id`,
+			sch.ID, equipmentID, sch.Category, HistoryStatusWrittenOff, EquipmentStatusDisposed, MaintenanceTaskOpen,
+		)
+		if err != nil {
+			return created, fmt.Errorf("ошибка при подборе оборудования по расписанию %d: %v", sch.ID, err)
+		}
+
+		type pending struct {
+			equipmentID int
+			dueAt       time.Time
+		}
+		var due []pending
+		for rows.Next() {
+			var id int
+			var lastCompleted *time.Time
+			if err := rows.Scan(&id, &lastCompleted); err != nil {
+				rows.Close()
+				return created, fmt.Errorf("ошибка при чтении оборудования по расписанию: %v", err)
+			}
+			base := sch.CreatedAt
+			if lastCompleted != nil {
+				base = *lastCompleted
+			}
+			if next := spec.Next(base); !next.IsZero() {
+				due = append(due, pending{equipmentID: id, dueAt: next})
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return created, fmt.Errorf("ошибка при чтении оборудования по расписанию: %v", err)
+		}
+
+		for _, p := range due {
+			task, err := insertTask(s.db, sch.ID, p.equipmentID, p.dueAt)
+			if err != nil {
+				return created, err
+			}
+			if task == nil {
+				// Открытую задачу успела создать параллельная операция
+				continue
+			}
+			task.ScheduleName = sch.Name
+			task.Overdue = task.DueAt.Before(now)
+			created = append(created, *task)
+		}
+	}
+	return created, nil
+}
+
+// GetTasks возвращает открытые задачи планового обслуживания по сроку, начиная с ближайших
+func (s *MaintenanceScheduleService) GetTasks(filter TaskFilter) ([]MaintenanceTask, error) {
+	conditions := []string{"t.status = $1"}
+	args := []interface{}{MaintenanceTaskOpen}
+	switch filter.Due {
+	case TaskDueOverdue:
+		args = append(args, filter.Now)
+		conditions = append(conditions, fmt.Sprintf("t.due_at < $%d", len(args)))
+	case TaskDueUpcoming:
+		args = append(args, filter.Now, filter.Now.Add(filter.Within))
+		conditions = append(conditions, fmt.Sprintf("t.due_at >= $%d AND t.due_at <= $%d", len(args)-1, len(args)))
+	}
+	if filter.EquipmentID > 0 {
+		args = append(args, filter.EquipmentID)
+		conditions = append(conditions, fmt.Sprintf("t.equipment_id = $%d", len(args)))
+	}
+
+	rows, err := s.db.Query(taskColumns+" WHERE "+strings.Join(conditions, " AND ")+" ORDER BY t.due_at, t.id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении задач обслуживания: %v", err)
+	}
+	defer rows.Close()
+
+	tasks := []MaintenanceTask{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		task.Overdue = task.DueAt.Before(filter.Now)
+		tasks = append(tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении задач обслуживания: %v", err)
+	}
+	return tasks, nil
+}
+
+// ClaimDueNotifications отмечает время уведомления у открытых задач со сроком до now, о которых ещё
+// не сообщали, и возвращает их. Отметка ставится тем же запросом, что и выборка, поэтому о каждой
+// задаче сообщается один раз даже при нескольких экземплярах сервера.
+func (s *MaintenanceScheduleService) ClaimDueNotifications(now time.Time) ([]MaintenanceTask, error) {
+	rows, err := s.db.Query(
+		`WITH claimed AS (
+			UPDATE maintenance_tasks SET notified_at = $2
+			WHERE status = $1 AND due_at < $2 AND notified_at IS NULL
+			RETURNING id
+		)
+		`+taskColumns+`
+		WHERE t.id IN (SELECT id FROM claimed)
+		ORDER BY t.due_at, t.id`, MaintenanceTaskOpen, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при отметке уведомлений о задачах обслуживания: %v", err)
+	}
+	defer rows.Close()
+
+	tasks := []MaintenanceTask{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		task.Overdue = true
+		tasks = append(tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении задач обслуживания: %v", err)
+	}
+	return tasks, nil
+}
+
+// CompleteTask отмечает задачу выполненной и сразу создаёт следующую задачу по тому же расписанию
+// со сроком, отсчитанным от даты выполнения
+func (s *MaintenanceScheduleService) CompleteTask(id int, note string, completedAt time.Time) (*MaintenanceTask, error) {
+	v := &ValidationError{}
+	v.MaxLength("note", note, maxJustification)
+	if err := v.ErrOrNil(); err != nil {
+		return nil, err
+	}
+
+	var nextDueAt *time.Time
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		var scheduleID, equipmentID int
+		var status, spec string
+		err := tx.QueryRow(
+			`SELECT t.schedule_id, t.equipment_id, t.status, s.spec
+			FROM maintenance_tasks t
+			JOIN maintenance_schedules s ON s.id = t.schedule_id
+			WHERE t.id = $1 FOR UPDATE OF t`, id,
+		).Scan(&scheduleID, &equipmentID, &status, &spec)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w (id %d)", ErrMaintenanceTaskNotFound, id)
+			}
+			return fmt.Errorf("ошибка при получении задачи обслуживания: %v", err)
+		}
+		if status != MaintenanceTaskOpen {
+			return fmt.Errorf("%w (id %d)", ErrMaintenanceTaskDone, id)
+		}
+
+		if _, err := tx.Exec(
+			"UPDATE maintenance_tasks SET status = $1, completed_at = $2, note = NULLIF($3, '') WHERE id = $4",
+			MaintenanceTaskDone, completedAt, note, id,
+		); err != nil {
+			return fmt.Errorf("ошибка при выполнении задачи обслуживания: %v", err)
+		}
+
+		parsed, err := schedule.Parse(spec)
+		if err != nil {
+			return fmt.Errorf("некорректное расписание обслуживания %d: %v", scheduleID, err)
+		}
+		if next := parsed.Next(completedAt); !next.IsZero() {
+			task, err := insertTask(tx, scheduleID, equipmentID, next)
+			if err != nil {
+				return err
+			}
+			if task != nil {
+				nextDueAt = &task.DueAt
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := scanTask(s.db.QueryRow(taskColumns+" WHERE t.id = $1", id))
+	if err != nil {
+		return nil, err
+	}
+	task.NextDueAt = nextDueAt
+	return task, nil
+}
+
+// insertTask создаёт открытую задачу обслуживания, если по расписанию для этого оборудования
+// ещё нет открытой задачи, и возвращает nil, если она уже есть. Проверка и вставка выполняются
+// одним запросом, а уникальный частичный индекс по (schedule_id, equipment_id) для открытых задач
+// не даёт создать вторую задачу, даже если планировщик и выполнение задачи идут одновременно.
+func insertTask(q queryer, scheduleID, equipmentID int, dueAt time.Time) (*MaintenanceTask, error) {
+	task := &MaintenanceTask{ScheduleID: scheduleID, EquipmentID: equipmentID, DueAt: dueAt, Status: MaintenanceTaskOpen}
+	err := q.QueryRow(
+		`INSERT INTO maintenance_tasks (schedule_id, equipment_id, due_at, status)
+		SELECT $1, $2, $3, $4
+		WHERE NOT EXISTS (SELECT 1 FROM maintenance_tasks WHERE schedule_id = $1 AND equipment_id = $2 AND status = $4)
+		ON CONFLICT DO NOTHING
+		RETURNING id`,
+		scheduleID, equipmentID, dueAt, MaintenanceTaskOpen,
+	).Scan(&task.ID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании задачи обслуживания: %v", err)
+	}
+	task.AssetTag = AssetTag(equipmentID)
+	return task, nil
+}
+
+// taskColumns столбцы задачи обслуживания вместе с названием расписания и моделью оборудования
+const taskColumns = `SELECT t.id, t.schedule_id, s.name, t.equipment_id, e.model, t.due_at, t.status, t.completed_at, COALESCE(t.note, '')
+	FROM maintenance_tasks t
+	JOIN maintenance_schedules s ON s.id = t.schedule_id
+	JOIN equipment e ON e.id = t.equipment_id`
+
+// scanTask читает задачу обслуживания из строки результата
+func scanTask(row interface{ Scan(...interface{}) error }) (*MaintenanceTask, error) {
+	var task MaintenanceTask
+	err := row.Scan(
+		&task.ID, &task.ScheduleID, &task.ScheduleName, &task.EquipmentID, &task.Model,
+		&task.DueAt, &task.Status, &task.CompletedAt, &task.Note,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении задачи обслуживания: %v", err)
+	}
+	task.AssetTag = AssetTag(task.EquipmentID)
+	return &task, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"inva/pkg/events"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventMaintenanceDue тип события о наступившем сроке планового обслуживания
+const EventMaintenanceDue = "maintenance.due"
+
+// MaintenanceScheduler периодически создаёт задачи по расписаниям обслуживания
+// и публикует события о задачах, срок которых наступил
+type MaintenanceScheduler struct {
+	service  *MaintenanceScheduleService
+	bus      *events.Bus
+	interval time.Duration
+}
+
+// NewMaintenanceScheduler создаёт новый экземпляр MaintenanceScheduler
+func NewMaintenanceScheduler(service *MaintenanceScheduleService, bus *events.Bus, interval time.Duration) *MaintenanceScheduler {
+	return &MaintenanceScheduler{service: service, bus: bus, interval: interval}
+}
+
+// Run выполняет планирование с заданным интервалом до отмены контекста
+func (s *MaintenanceScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.Tick(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick создаёт недостающие задачи и один раз публикует событие для каждой открытой задачи со сроком до now
+func (s *MaintenanceScheduler) Tick(now time.Time) {
+	created, err := s.service.GenerateTasks(now)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при создании задач планового обслуживания")
+	}
+	if len(created) > 0 {
+		logrus.WithField("tasks", len(created)).Info("Созданы задачи планового обслуживания")
+	}
+
+	tasks, err := s.service.ClaimDueNotifications(now)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при проверке задач планового обслуживания")
+		return
+	}
+
+	for _, task := range tasks {
+		s.bus.Publish(events.Event{
+			Type: EventMaintenanceDue,
+			At:   now,
+			Payload: map[string]interface{}{
+				"task_id":      task.ID,
+				"schedule":     task.ScheduleName,
+				"equipment_id": task.EquipmentID,
+				"asset_tag":    task.AssetTag,
+				"due_at":       task.DueAt,
+			},
+		})
+	}
+}
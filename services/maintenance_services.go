@@ -0,0 +1,293 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"inva/pkg/validation"
+	"math"
+	"time"
+)
+
+// Статусы оборудования, которые выставляет учёт ремонтов: на время ремонта оборудование
+// получает статус in_repair, а после неудачного ремонта — broken
+const (
+	EquipmentStatusInRepair = "in_repair"
+	EquipmentStatusBroken   = "broken"
+)
+
+// Итоги ремонта: после успешного ремонта оборудованию возвращается статус, который был до него
+const (
+	MaintenanceOutcomeRepaired    = "repaired"
+	MaintenanceOutcomeNotRepaired = "not_repaired"
+)
+
+// Ограничения на длину полей записи о ремонте
+const (
+	maxIssueLength  = 1000
+	maxVendorLength = 255
+)
+
+// MaintenanceRecord представляет запись о ремонте или обслуживании оборудования
+type MaintenanceRecord struct {
+	ID             int        `json:"id"`
+	EquipmentID    int        `json:"equipment_id"`
+	AssetTag       string     `json:"asset_tag"`
+	Model          string     `json:"model"`
+	SerialNumber   string     `json:"serial_number"`
+	AssignedTo     *int       `json:"assigned_to"`
+	Issue          string     `json:"issue"`
+	Vendor         string     `json:"vendor,omitempty"`
+	Cost           *float64   `json:"cost"`
+	Outcome        string     `json:"outcome,omitempty"`
+	Note           string     `json:"note,omitempty"`
+	PreviousStatus string     `json:"previous_status"`
+	OpenedAt       time.Time  `json:"opened_at"`
+	ClosedAt       *time.Time `json:"closed_at"`
+	DaysOpen       int        `json:"days_open,omitempty"`
+}
+
+// MaintenanceOpening данные об отправке оборудования в ремонт
+type MaintenanceOpening struct {
+	Issue  string
+	Vendor string
+	Cost   *float64
+	// OpenedAt дата передачи в ремонт; nil означает текущий момент
+	OpenedAt *time.Time
+}
+
+// MaintenanceClosing данные о завершении ремонта
+type MaintenanceClosing struct {
+	Outcome string
+	// Cost итоговая стоимость; nil оставляет стоимость, указанную при открытии
+	Cost *float64
+	Note string
+	// ClosedAt дата возврата из ремонта; nil означает текущий момент
+	ClosedAt *time.Time
+}
+
+// MaintenanceService предоставляет методы для учёта ремонтов оборудования
+type MaintenanceService struct {
+	db *sql.DB
+}
+
+// NewMaintenanceService создаёт новый экземпляр MaintenanceService
+func NewMaintenanceService(db *sql.DB) *MaintenanceService {
+	return &MaintenanceService{db: db}
+}
+
+// OpenMaintenance отправляет оборудование в ремонт: создаёт запись о ремонте и переводит
+// оборудование в статус in_repair, запоминая прежний статус. Закрепление за сотрудником сохраняется.
+func (s *MaintenanceService) OpenMaintenance(equipmentID int, opening MaintenanceOpening) (*MaintenanceRecord, error) {
+	v := &ValidationError{}
+	v.RequireString("issue", opening.Issue, maxIssueLength)
+	v.MaxLength("vendor", opening.Vendor, maxVendorLength)
+	checkCost(v, "cost", opening.Cost)
+	if err := v.ErrOrNil(); err != nil {
+		return nil, err
+	}
+	openedAt := time.Now()
+	if opening.OpenedAt != nil {
+		openedAt = *opening.OpenedAt
+	}
+
+	var id int
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		var status string
+		err := tx.QueryRow("SELECT status FROM equipment WHERE id = $1 FOR UPDATE", equipmentID).Scan(&status)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w (id %d)", ErrEquipmentNotFound, equipmentID)
+			}
+			return fmt.Errorf("ошибка при получении оборудования: %v", err)
+		}
+
+		open, err := hasOpenMaintenance(tx, equipmentID)
+		if err != nil {
+			return err
+		}
+		if open || status == EquipmentStatusInRepair {
+			return fmt.Errorf("%w (id %d)", ErrMaintenanceOpen, equipmentID)
+		}
+
+		if err := tx.QueryRow(
+			`INSERT INTO maintenance_records (equipment_id, issue, vendor, cost, previous_status, opened_at)
+			VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6) RETURNING id`,
+			equipmentID, opening.Issue, opening.Vendor, opening.Cost, status, openedAt,
+		).Scan(&id); err != nil {
+			return fmt.Errorf("ошибка при создании записи о ремонте: %v", err)
+		}
+		return changeWorkflowStatus(tx, equipmentID, EquipmentStatusInRepair)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return queryMaintenance(s.db, id)
+}
+
+// hasOpenMaintenance сообщает, находится ли оборудование в незавершённом ремонте
+func hasOpenMaintenance(q queryer, equipmentID int) (bool, error) {
+	var open bool
+	if err := q.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM maintenance_records WHERE equipment_id = $1 AND closed_at IS NULL)", equipmentID,
+	).Scan(&open); err != nil {
+		return false, fmt.Errorf("ошибка при проверке открытых ремонтов: %v", err)
+	}
+	return open, nil
+}
+
+// CloseMaintenance завершает ремонт: после успешного ремонта оборудованию возвращается прежний статус,
+// после неудачного оно получает статус broken. Статус меняется, только если оборудование всё ещё в ремонте.
+func (s *MaintenanceService) CloseMaintenance(id int, closing MaintenanceClosing) (*MaintenanceRecord, error) {
+	v := &ValidationError{}
+	if closing.Outcome != MaintenanceOutcomeRepaired && closing.Outcome != MaintenanceOutcomeNotRepaired {
+		v.Add("outcome", validation.CodeNotAllowed, "допустимые значения: %s, %s", MaintenanceOutcomeRepaired, MaintenanceOutcomeNotRepaired)
+	}
+	checkCost(v, "cost", closing.Cost)
+	v.MaxLength("note", closing.Note, maxJustification)
+	if err := v.ErrOrNil(); err != nil {
+		return nil, err
+	}
+	closedAt := time.Now()
+	if closing.ClosedAt != nil {
+		closedAt = *closing.ClosedAt
+	}
+
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		var equipmentID int
+		var previousStatus string
+		var openedAt time.Time
+		var closed *time.Time
+		err := tx.QueryRow(
+			"SELECT equipment_id, previous_status, opened_at, closed_at FROM maintenance_records WHERE id = $1 FOR UPDATE", id,
+		).Scan(&equipmentID, &previousStatus, &openedAt, &closed)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w (id %d)", ErrMaintenanceNotFound, id)
+			}
+			return fmt.Errorf("ошибка при получении записи о ремонте: %v", err)
+		}
+		if closed != nil {
+			return fmt.Errorf("%w (id %d)", ErrMaintenanceClosed, id)
+		}
+		if closedAt.Before(openedAt) {
+			v.Add("closed_at", validation.CodeOutOfRange, "дата возврата из ремонта раньше даты передачи")
+			return v
+		}
+
+		if _, err := tx.Exec(
+			`UPDATE maintenance_records SET outcome = $1, cost = COALESCE($2, cost), note = NULLIF($3, ''), closed_at = $4
+			WHERE id = $5`,
+			closing.Outcome, closing.Cost, closing.Note, closedAt, id,
+		); err != nil {
+			return fmt.Errorf("ошибка при закрытии записи о ремонте: %v", err)
+		}
+
+		// Статус, изменённый вручную во время ремонта, например списание, не перезаписывается
+		locked, err := lockEquipment(tx, equipmentID)
+		if err != nil {
+			return err
+		}
+		if locked.Status != EquipmentStatusInRepair {
+			return nil
+		}
+		status := previousStatus
+		if closing.Outcome == MaintenanceOutcomeNotRepaired {
+			status = EquipmentStatusBroken
+		}
+		return setEquipmentStatus(tx, equipmentID, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return queryMaintenance(s.db, id)
+}
+
+// GetEquipmentMaintenance возвращает историю ремонтов оборудования, начиная с последнего
+func (s *MaintenanceService) GetEquipmentMaintenance(equipmentID int) ([]MaintenanceRecord, error) {
+	var exists bool
+	if err := s.db.QueryRow("SELECT EXISTS (SELECT 1 FROM equipment WHERE id = $1)", equipmentID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("ошибка при проверке оборудования: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w (id %d)", ErrEquipmentNotFound, equipmentID)
+	}
+	return queryMaintenanceList(s.db, " WHERE m.equipment_id = $1 ORDER BY m.opened_at DESC, m.id DESC", equipmentID)
+}
+
+// GetOpenMaintenance возвращает оборудование, находящееся в ремонте, начиная с самого долгого ремонта
+func (s *MaintenanceService) GetOpenMaintenance() ([]MaintenanceRecord, error) {
+	records, err := queryMaintenanceList(s.db, " WHERE m.closed_at IS NULL ORDER BY m.opened_at, m.id")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range records {
+		records[i].DaysOpen = int(now.Sub(records[i].OpenedAt).Hours() / 24)
+	}
+	return records, nil
+}
+
+// checkCost проверяет, что стоимость, если указана, неотрицательна и конечна
+func checkCost(v *ValidationError, field string, cost *float64) {
+	if cost != nil && (*cost < 0 || math.IsNaN(*cost) || math.IsInf(*cost, 0)) {
+		v.Add(field, validation.CodeOutOfRange, "ожидается неотрицательное число")
+	}
+}
+
+// maintenanceColumns столбцы записи о ремонте вместе с данными оборудования
+const maintenanceColumns = `SELECT m.id, m.equipment_id, e.model, COALESCE(e.serial_number, ''), e.assigned_to,
+	m.issue, COALESCE(m.vendor, ''), m.cost, COALESCE(m.outcome, ''), COALESCE(m.note, ''),
+	m.previous_status, m.opened_at, m.closed_at
+	FROM maintenance_records m
+	JOIN equipment e ON e.id = m.equipment_id`
+
+// queryMaintenance возвращает запись о ремонте по идентификатору
+func queryMaintenance(q queryer, id int) (*MaintenanceRecord, error) {
+	record, err := scanMaintenance(q.QueryRow(maintenanceColumns+" WHERE m.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w (id %d)", ErrMaintenanceNotFound, id)
+	}
+	return record, err
+}
+
+// queryMaintenanceList возвращает записи о ремонте по условию и порядку сортировки
+func queryMaintenanceList(q queryer, where string, args ...interface{}) ([]MaintenanceRecord, error) {
+	rows, err := q.Query(maintenanceColumns+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении записей о ремонте: %v", err)
+	}
+	defer rows.Close()
+
+	records := []MaintenanceRecord{}
+	for rows.Next() {
+		record, err := scanMaintenance(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении записей о ремонте: %v", err)
+	}
+	return records, nil
+}
+
+// scanMaintenance читает запись о ремонте из строки результата
+func scanMaintenance(row interface{ Scan(...interface{}) error }) (*MaintenanceRecord, error) {
+	var record MaintenanceRecord
+	err := row.Scan(
+		&record.ID, &record.EquipmentID, &record.Model, &record.SerialNumber, &record.AssignedTo,
+		&record.Issue, &record.Vendor, &record.Cost, &record.Outcome, &record.Note,
+		&record.PreviousStatus, &record.OpenedAt, &record.ClosedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("ошибка при чтении записи о ремонте: %v", err)
+	}
+	record.AssetTag = AssetTag(record.EquipmentID)
+	return &record, nil
+}
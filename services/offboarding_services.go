@@ -0,0 +1,283 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Статусы сотрудника: после начала увольнения сотрудник становится неактивным,
+// а после возврата или списания всего оборудования — окончательно деактивированным
+const (
+	EmployeeStatusActive      = "active"
+	EmployeeStatusInactive    = "inactive"
+	EmployeeStatusDeactivated = "deactivated"
+)
+
+// Статусы задачи на возврат; закрытая задача получает статус записи истории, которой закрылась выдача
+const (
+	ReturnTaskStatusOpen = "open"
+)
+
+// DefaultReturnDeadline срок возврата оборудования, если дата не указана при увольнении
+const DefaultReturnDeadline = 14 * 24 * time.Hour
+
+// ReturnTask представляет задачу на возврат оборудования увольняемым сотрудником
+type ReturnTask struct {
+	ID           int        `json:"id"`
+	EmployeeID   int        `json:"employee_id"`
+	EquipmentID  int        `json:"equipment_id"`
+	AssetTag     string     `json:"asset_tag"`
+	Model        string     `json:"model"`
+	SerialNumber string     `json:"serial_number"`
+	DueAt        time.Time  `json:"due_at"`
+	Status       string     `json:"status"`
+	Note         string     `json:"note"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ResolvedAt   *time.Time `json:"resolved_at"`
+}
+
+// Offboarding представляет ход увольнения сотрудника: его статус и задачи на возврат оборудования
+type Offboarding struct {
+	EmployeeID int          `json:"employee_id"`
+	Status     string       `json:"status"`
+	OpenTasks  int          `json:"open_tasks"`
+	Tasks      []ReturnTask `json:"tasks"`
+}
+
+// OffboardingService предоставляет методы для возврата оборудования при увольнении сотрудника
+type OffboardingService struct {
+	db *sql.DB
+}
+
+// NewOffboardingService создаёт новый экземпляр OffboardingService
+func NewOffboardingService(db *sql.DB) *OffboardingService {
+	return &OffboardingService{db: db}
+}
+
+// Offboard начинает увольнение: сотрудник становится неактивным, а на каждое закреплённое за ним
+// оборудование создаётся задача на возврат со сроком dueAt. Повторный вызов добавляет задачи только
+// для оборудования, у которого ещё нет открытой задачи, и не меняет сроки существующих.
+func (s *OffboardingService) Offboard(employeeID int, dueAt time.Time) (*Offboarding, error) {
+	var offboarding *Offboarding
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		status, err := lockEmployeeStatus(tx, employeeID)
+		if err != nil {
+			return err
+		}
+		if status == EmployeeStatusDeactivated {
+			return fmt.Errorf("%w (id %d)", ErrEmployeeInactive, employeeID)
+		}
+
+		if status == EmployeeStatusActive {
+			if _, err := tx.Exec(
+				"UPDATE employees SET status = $1, "+bumpVersion+" WHERE id = $2",
+				EmployeeStatusInactive, employeeID,
+			); err != nil {
+				return fmt.Errorf("ошибка при изменении статуса сотрудника: %v", err)
+			}
+		}
+
+		if _, err := tx.Exec(
+			`INSERT INTO return_tasks (employee_id, equipment_id, due_at)
+			SELECT $1, e.id, $2 FROM equipment e
+			WHERE e.assigned_to = $1
+			AND NOT EXISTS (SELECT 1 FROM return_tasks t WHERE t.equipment_id = e.id AND t.status = $3)`,
+			employeeID, dueAt, ReturnTaskStatusOpen,
+		); err != nil {
+			return fmt.Errorf("ошибка при создании задач на возврат: %v", err)
+		}
+
+		offboarding, err = queryOffboarding(tx, employeeID, EmployeeStatusInactive)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return offboarding, nil
+}
+
+// GetOffboarding возвращает статус сотрудника и все его задачи на возврат
+func (s *OffboardingService) GetOffboarding(employeeID int) (*Offboarding, error) {
+	var status string
+	err := s.db.QueryRow("SELECT status FROM employees WHERE id = $1", employeeID).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w (id %d)", ErrEmployeeNotFound, employeeID)
+		}
+		return nil, fmt.Errorf("ошибка при получении сотрудника: %v", err)
+	}
+	return queryOffboarding(s.db, employeeID, status)
+}
+
+// WriteOff закрывает задачу на возврат списанием: оборудование открепляется от сотрудника,
+// получает статус written_off, а в историю выдачи записывается причина
+func (s *OffboardingService) WriteOff(taskID int, note string) (*ReturnTask, error) {
+	var equipmentID int
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		var status string
+		err := tx.QueryRow(
+			"SELECT equipment_id, status FROM return_tasks WHERE id = $1 FOR UPDATE", taskID,
+		).Scan(&equipmentID, &status)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w (id %d)", ErrReturnTaskNotFound, taskID)
+			}
+			return fmt.Errorf("ошибка при получении задачи на возврат: %v", err)
+		}
+		if status != ReturnTaskStatusOpen {
+			return fmt.Errorf("%w (id %d, статус %s)", ErrReturnTaskClosed, taskID, status)
+		}
+
+		// closeAssignment закрывает и запись истории, и саму задачу
+		if _, err := closeAssignment(tx, equipmentID, HistoryStatusWrittenOff, note); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			"UPDATE return_tasks SET note = $1 WHERE id = $2", note, taskID,
+		); err != nil {
+			return fmt.Errorf("ошибка при записи причины списания: %v", err)
+		}
+		return changeWorkflowStatus(tx, equipmentID, HistoryStatusWrittenOff)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return queryReturnTask(s.db, taskID)
+}
+
+// Deactivate окончательно деактивирует сотрудника, если увольнение начато, все задачи на возврат закрыты
+// и за ним не закреплено никакого оборудования
+func (s *OffboardingService) Deactivate(employeeID int) (*Offboarding, error) {
+	var offboarding *Offboarding
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		status, err := lockEmployeeStatus(tx, employeeID)
+		if err != nil {
+			return err
+		}
+		if status == EmployeeStatusActive {
+			return fmt.Errorf("%w (id %d): увольнение не начато", ErrOffboardingIncomplete, employeeID)
+		}
+
+		var open int
+		if err := tx.QueryRow(
+			"SELECT COUNT(*) FROM return_tasks WHERE employee_id = $1 AND status = $2",
+			employeeID, ReturnTaskStatusOpen,
+		).Scan(&open); err != nil {
+			return fmt.Errorf("ошибка при подсчёте задач на возврат: %v", err)
+		}
+		if open > 0 {
+			return fmt.Errorf("%w (id %d, открытых задач %d)", ErrOffboardingIncomplete, employeeID, open)
+		}
+
+		// Задачи на возврат создаются при начале увольнения, поэтому проверяется и текущее закрепление
+		var assigned int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM equipment WHERE assigned_to = $1", employeeID).Scan(&assigned); err != nil {
+			return fmt.Errorf("ошибка при подсчёте закреплённого оборудования: %v", err)
+		}
+		if assigned > 0 {
+			return fmt.Errorf("%w (id %d, закреплено оборудования %d)", ErrOffboardingIncomplete, employeeID, assigned)
+		}
+
+		if status != EmployeeStatusDeactivated {
+			if _, err := tx.Exec(
+				"UPDATE employees SET status = $1, "+bumpVersion+" WHERE id = $2",
+				EmployeeStatusDeactivated, employeeID,
+			); err != nil {
+				return fmt.Errorf("ошибка при деактивации сотрудника: %v", err)
+			}
+		}
+
+		offboarding, err = queryOffboarding(tx, employeeID, EmployeeStatusDeactivated)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return offboarding, nil
+}
+
+// lockEmployeeStatus блокирует строку сотрудника до конца транзакции и возвращает его статус
+func lockEmployeeStatus(q queryer, employeeID int) (string, error) {
+	var status string
+	err := q.QueryRow("SELECT status FROM employees WHERE id = $1 FOR UPDATE", employeeID).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("%w (id %d)", ErrEmployeeNotFound, employeeID)
+		}
+		return "", fmt.Errorf("ошибка при получении сотрудника: %v", err)
+	}
+	return status, nil
+}
+
+// requireActiveEmployee проверяет, что сотрудник существует и за ним можно закреплять оборудование.
+// Строка сотрудника блокируется до конца транзакции: начатое одновременно увольнение дождётся
+// выдачи и создаст задачу на возврат, а выдача после увольнения увидит новый статус.
+func requireActiveEmployee(q queryer, employeeID int) error {
+	status, err := lockEmployeeStatus(q, employeeID)
+	if err != nil {
+		return err
+	}
+	if status != EmployeeStatusActive {
+		return fmt.Errorf("%w (id %d, статус %s)", ErrEmployeeInactive, employeeID, status)
+	}
+	return nil
+}
+
+// returnTaskColumns столбцы задачи на возврат вместе с данными оборудования
+const returnTaskColumns = `SELECT t.id, t.employee_id, t.equipment_id, e.model, e.serial_number,
+	t.due_at, t.status, COALESCE(t.note, ''), t.created_at, t.resolved_at
+	FROM return_tasks t
+	JOIN equipment e ON e.id = t.equipment_id`
+
+// queryOffboarding собирает задачи на возврат сотрудника в сводку увольнения
+func queryOffboarding(q queryer, employeeID int, status string) (*Offboarding, error) {
+	rows, err := q.Query(returnTaskColumns+" WHERE t.employee_id = $1 ORDER BY t.id", employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении задач на возврат: %v", err)
+	}
+	defer rows.Close()
+
+	offboarding := &Offboarding{EmployeeID: employeeID, Status: status, Tasks: []ReturnTask{}}
+	for rows.Next() {
+		task, err := scanReturnTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		if task.Status == ReturnTaskStatusOpen {
+			offboarding.OpenTasks++
+		}
+		offboarding.Tasks = append(offboarding.Tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении задач на возврат: %v", err)
+	}
+	return offboarding, nil
+}
+
+// queryReturnTask возвращает задачу на возврат по идентификатору
+func queryReturnTask(q queryer, taskID int) (*ReturnTask, error) {
+	task, err := scanReturnTask(q.QueryRow(returnTaskColumns+" WHERE t.id = $1", taskID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w (id %d)", ErrReturnTaskNotFound, taskID)
+	}
+	return task, err
+}
+
+// scanReturnTask читает задачу на возврат из строки результата
+func scanReturnTask(row interface{ Scan(...interface{}) error }) (*ReturnTask, error) {
+	var task ReturnTask
+	err := row.Scan(
+		&task.ID, &task.EmployeeID, &task.EquipmentID, &task.Model, &task.SerialNumber,
+		&task.DueAt, &task.Status, &task.Note, &task.CreatedAt, &task.ResolvedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("ошибка при чтении задачи на возврат: %v", err)
+	}
+	task.AssetTag = AssetTag(task.EquipmentID)
+	return &task, nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"inva/pkg/events"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventEquipmentOverdue тип события о просроченном возврате оборудования
+const EventEquipmentOverdue = "equipment.overdue"
+
+// OverdueRenotifyInterval как часто повторяется событие о той же просроченной выдаче
+const OverdueRenotifyInterval = 24 * time.Hour
+
+// OverdueChecker периодически ищет просроченные выдачи и публикует о них события
+type OverdueChecker struct {
+	service  *EquipmentService
+	bus      *events.Bus
+	interval time.Duration
+}
+
+// NewOverdueChecker создаёт новый экземпляр OverdueChecker
+func NewOverdueChecker(service *EquipmentService, bus *events.Bus, interval time.Duration) *OverdueChecker {
+	return &OverdueChecker{service: service, bus: bus, interval: interval}
+}
+
+// Run выполняет проверку с заданным интервалом до отмены контекста
+func (c *OverdueChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.Check(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check публикует событие для каждой выдачи, просроченной к моменту now, если о ней не сообщали
+// в течение OverdueRenotifyInterval
+func (c *OverdueChecker) Check(now time.Time) {
+	items, err := c.service.ClaimOverdueNotifications(now, OverdueRenotifyInterval)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при проверке просроченного оборудования")
+		return
+	}
+
+	for _, item := range items {
+		c.bus.Publish(events.Event{
+			Type: EventEquipmentOverdue,
+			At:   now,
+			Payload: map[string]interface{}{
+				"equipment_id": item.EquipmentID,
+				"asset_tag":    item.AssetTag,
+				"user_id":      item.UserID,
+				"due_at":       item.DueAt,
+				"overdue_days": item.OverdueDays,
+			},
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"inva/pkg/validation"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Правила политик выдачи оборудования
+const (
+	// PolicyMaxPerCategory ограничивает число единиц одной категории у сотрудника
+	PolicyMaxPerCategory = "max_per_category"
+	// PolicyRestrictedCategory разрешает категорию только перечисленным отделам и должностям
+	PolicyRestrictedCategory = "restricted_category"
+	// PolicyApprovalThreshold требует одобрения администратора для оборудования дороже порога
+	PolicyApprovalThreshold = "approval_threshold"
+)
+
+// Коды нарушений политик выдачи
+const (
+	ViolationLimitExceeded  = "limit_exceeded"
+	ViolationRestricted     = "restricted"
+	ViolationApprovalNeeded = "approval_required"
+)
+
+// Списки отделов и должностей хранятся в одной строке через запятую
+const (
+	policyListSeparator      = ","
+	maxPolicyListEntryLength = 100
+)
+
+// Policy представляет правило, проверяемое при каждой выдаче оборудования.
+// Пустая категория означает, что правило действует для оборудования любой категории.
+type Policy struct {
+	ID             int       `json:"id"`
+	Rule           string    `json:"rule"`
+	Category       string    `json:"category,omitempty"`
+	MaxItems       int       `json:"max_items,omitempty"`
+	Departments    []string  `json:"departments,omitempty"`
+	Positions      []string  `json:"positions,omitempty"`
+	ValueThreshold float64   `json:"value_threshold,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// PolicyViolation нарушение одного правила при выдаче
+type PolicyViolation struct {
+	PolicyID int    `json:"policy_id"`
+	Rule     string `json:"rule"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// PolicyViolationError возвращается, если выдача нарушает политики и не разрешена администратором
+type PolicyViolationError struct {
+	EquipmentID int               `json:"equipment_id"`
+	EmployeeID  int               `json:"employee_id"`
+	Violations  []PolicyViolation `json:"violations"`
+}
+
+// Error перечисляет нарушенные правила
+func (e *PolicyViolationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return fmt.Sprintf("%v (оборудование %d, сотрудник %d: %s)", ErrPolicyViolation, e.EquipmentID, e.EmployeeID, strings.Join(messages, "; "))
+}
+
+// Unwrap позволяет сравнивать ошибку с ErrPolicyViolation через errors.Is
+func (e *PolicyViolationError) Unwrap() error {
+	return ErrPolicyViolation
+}
+
+// PolicyOverride разрешение администратора выдать оборудование вопреки политикам с обоснованием
+type PolicyOverride struct {
+	AdminID       int    `json:"admin_id"`
+	Justification string `json:"justification"`
+}
+
+// PolicyOverrideRecord запись журнала выдач вопреки политикам
+type PolicyOverrideRecord struct {
+	ID            int               `json:"id"`
+	EquipmentID   int               `json:"equipment_id"`
+	EmployeeID    int               `json:"employee_id"`
+	AdminID       int               `json:"admin_id"`
+	Justification string            `json:"justification"`
+	Violations    []PolicyViolation `json:"violations"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// PolicyService предоставляет методы для управления политиками выдачи
+type PolicyService struct {
+	db *sql.DB
+}
+
+// NewPolicyService создаёт новый экземпляр PolicyService
+func NewPolicyService(db *sql.DB) *PolicyService {
+	return &PolicyService{db: db}
+}
+
+// ValidatePolicy проверяет правило перед сохранением
+func ValidatePolicy(policy *Policy) error {
+	v := &ValidationError{}
+	v.MaxLength("category", policy.Category, maxCategoryLength)
+	switch policy.Rule {
+	case PolicyMaxPerCategory:
+		if policy.MaxItems < 1 {
+			v.Add("max_items", validation.CodeOutOfRange, "должно быть не меньше 1")
+		}
+	case PolicyRestrictedCategory:
+		if policy.Category == "" {
+			v.Add("category", validation.CodeRequired, "обязательное поле для правила %s", policy.Rule)
+		}
+		if len(policy.Departments) == 0 && len(policy.Positions) == 0 {
+			v.Add("departments", validation.CodeRequired, "укажите разрешённые отделы или должности")
+		}
+	case PolicyApprovalThreshold:
+		if policy.ValueThreshold <= 0 {
+			v.Add("value_threshold", validation.CodeOutOfRange, "должно быть больше 0")
+		}
+	default:
+		v.Add("rule", validation.CodeNotAllowed, "допустимые значения: %s, %s, %s",
+			PolicyMaxPerCategory, PolicyRestrictedCategory, PolicyApprovalThreshold)
+	}
+	for i, entry := range policy.Departments {
+		checkPolicyListEntry(v, fmt.Sprintf("departments[%d]", i), entry)
+	}
+	for i, entry := range policy.Positions {
+		checkPolicyListEntry(v, fmt.Sprintf("positions[%d]", i), entry)
+	}
+	return v.ErrOrNil()
+}
+
+// checkPolicyListEntry проверяет элемент списка отделов или должностей, хранимого через запятую
+func checkPolicyListEntry(v *ValidationError, field, entry string) {
+	v.RequireString(field, entry, maxPolicyListEntryLength)
+	if strings.Contains(entry, policyListSeparator) {
+		v.Add(field, validation.CodeInvalid, "не может содержать запятую")
+	}
+}
+
+// CreatePolicy сохраняет правило выдачи
+func (s *PolicyService) CreatePolicy(policy *Policy) (*Policy, error) {
+	if err := ValidatePolicy(policy); err != nil {
+		return nil, err
+	}
+
+	err := s.db.QueryRow(
+		`INSERT INTO assignment_policies (rule, category, max_items, allowed_departments, allowed_positions, value_threshold)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, 0), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, 0))
+		RETURNING id, created_at`,
+		policy.Rule, policy.Category, policy.MaxItems,
+		strings.Join(policy.Departments, policyListSeparator), strings.Join(policy.Positions, policyListSeparator),
+		policy.ValueThreshold,
+	).Scan(&policy.ID, &policy.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании политики выдачи: %v", err)
+	}
+	return policy, nil
+}
+
+// GetAllPolicies возвращает все правила выдачи
+func (s *PolicyService) GetAllPolicies() ([]Policy, error) {
+	return queryPolicies(s.db)
+}
+
+// DeletePolicy удаляет правило выдачи
+func (s *PolicyService) DeletePolicy(id int) error {
+	result, err := s.db.Exec("DELETE FROM assignment_policies WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении политики выдачи: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении числа изменённых строк: %v", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w (id %d)", ErrPolicyNotFound, id)
+	}
+	return nil
+}
+
+// GetOverrides возвращает журнал выдач вопреки политикам, начиная с последней
+func (s *PolicyService) GetOverrides() ([]PolicyOverrideRecord, error) {
+	rows, err := s.db.Query(
+		`SELECT id, equipment_id, employee_id, admin_id, justification, violations, created_at
+		FROM policy_overrides ORDER BY created_at DESC, id DESC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении журнала исключений: %v", err)
+	}
+	defer rows.Close()
+
+	records := []PolicyOverrideRecord{}
+	for rows.Next() {
+		var (
+			record     PolicyOverrideRecord
+			violations []byte
+		)
+		if err := rows.Scan(&record.ID, &record.EquipmentID, &record.EmployeeID, &record.AdminID,
+			&record.Justification, &violations, &record.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении журнала исключений: %v", err)
+		}
+		if err := json.Unmarshal(violations, &record.Violations); err != nil {
+			return nil, fmt.Errorf("ошибка при разборе нарушений: %v", err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении журнала исключений: %v", err)
+	}
+	return records, nil
+}
+
+// queryPolicies возвращает правила выдачи в порядке создания
+func queryPolicies(q queryer) ([]Policy, error) {
+	rows, err := q.Query(
+		`SELECT id, rule, COALESCE(category, ''), COALESCE(max_items, 0), COALESCE(allowed_departments, ''),
+		COALESCE(allowed_positions, ''), COALESCE(value_threshold, 0), created_at
+		FROM assignment_policies ORDER BY id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении политик выдачи: %v", err)
+	}
+	defer rows.Close()
+
+	policies := []Policy{}
+	for rows.Next() {
+		var (
+			policy                 Policy
+			departments, positions string
+		)
+		if err := rows.Scan(&policy.ID, &policy.Rule, &policy.Category, &policy.MaxItems, &departments,
+			&positions, &policy.ValueThreshold, &policy.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении политики выдачи: %v", err)
+		}
+		policy.Departments = splitPolicyList(departments)
+		policy.Positions = splitPolicyList(positions)
+		policies = append(policies, policy)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении политик выдачи: %v", err)
+	}
+	return policies, nil
+}
+
+// splitPolicyList разбирает список отделов или должностей, хранимый через запятую
+func splitPolicyList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, policyListSeparator)
+}
+
+// checkAssignmentPolicies проверяет выдачу по всем правилам. Нарушения возвращаются *PolicyViolationError,
+// если выдача не разрешена администратором; разрешение вопреки политикам записывается в журнал вместе с обоснованием.
+func checkAssignmentPolicies(q queryer, equipmentID, userID int, override *PolicyOverride) error {
+	policies, err := queryPolicies(q)
+	if err != nil || len(policies) == 0 {
+		return err
+	}
+
+	var (
+		category, department, position string
+		cost                           sql.NullFloat64
+	)
+	if err := q.QueryRow(
+		"SELECT COALESCE(category, ''), purchase_cost FROM equipment WHERE id = $1", equipmentID,
+	).Scan(&category, &cost); err != nil {
+		return fmt.Errorf("ошибка при получении оборудования: %v", err)
+	}
+	// Строка сотрудника блокируется до подсчёта выданного: одновременные выдачи одному сотруднику
+	// выполняются по очереди и не превышают лимит max_per_category вдвоём
+	if err := q.QueryRow(
+		"SELECT COALESCE(department, ''), COALESCE(position, '') FROM employees WHERE id = $1 FOR UPDATE", userID,
+	).Scan(&department, &position); err != nil {
+		return fmt.Errorf("ошибка при получении сотрудника: %v", err)
+	}
+
+	held := -1
+	var violations []PolicyViolation
+	for _, policy := range policies {
+		if policy.Category != "" && !strings.EqualFold(policy.Category, category) {
+			continue
+		}
+
+		violation := PolicyViolation{PolicyID: policy.ID, Rule: policy.Rule}
+		switch policy.Rule {
+		case PolicyMaxPerCategory:
+			if held < 0 {
+				if err := q.QueryRow(
+					"SELECT COUNT(*) FROM equipment WHERE assigned_to = $1 AND lower(COALESCE(category, '')) = lower($2)",
+					userID, category,
+				).Scan(&held); err != nil {
+					return fmt.Errorf("ошибка при подсчёте оборудования сотрудника: %v", err)
+				}
+			}
+			if held < policy.MaxItems {
+				continue
+			}
+			violation.Code = ViolationLimitExceeded
+			violation.Message = fmt.Sprintf("у сотрудника уже %d ед. категории %q при лимите %d", held, category, policy.MaxItems)
+		case PolicyRestrictedCategory:
+			if containsFold(policy.Departments, department) || containsFold(policy.Positions, position) {
+				continue
+			}
+			violation.Code = ViolationRestricted
+			violation.Message = fmt.Sprintf("категория %q недоступна отделу %q и должности %q", category, department, position)
+		case PolicyApprovalThreshold:
+			if !cost.Valid || cost.Float64 <= policy.ValueThreshold {
+				continue
+			}
+			violation.Code = ViolationApprovalNeeded
+			violation.Message = fmt.Sprintf("стоимость %.2f превышает порог %.2f и требует одобрения", cost.Float64, policy.ValueThreshold)
+		default:
+			continue
+		}
+		violations = append(violations, violation)
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	if override == nil {
+		return &PolicyViolationError{EquipmentID: equipmentID, EmployeeID: userID, Violations: violations}
+	}
+	return applyPolicyOverride(q, equipmentID, userID, override, violations)
+}
+
+// applyPolicyOverride проверяет, что исключение разрешает администратор с обоснованием, и записывает его в журнал
+func applyPolicyOverride(q queryer, equipmentID, userID int, override *PolicyOverride, violations []PolicyViolation) error {
+	v := &ValidationError{}
+	v.RequireString("override.justification", override.Justification, maxJustification)
+	if err := v.ErrOrNil(); err != nil {
+		return err
+	}
+
+	var role string
+	if err := q.QueryRow("SELECT role FROM employees WHERE id = $1", override.AdminID).Scan(&role); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w (id %d)", ErrEmployeeNotFound, override.AdminID)
+		}
+		return fmt.Errorf("ошибка при проверке администратора: %v", err)
+	}
+	if role != EmployeeRoleAdmin {
+		return fmt.Errorf("%w (сотрудник %d не администратор)", ErrPolicyOverrideDenied, override.AdminID)
+	}
+
+	payload, err := json.Marshal(violations)
+	if err != nil {
+		return fmt.Errorf("ошибка при сериализации нарушений: %v", err)
+	}
+	if _, err := q.Exec(
+		"INSERT INTO policy_overrides (equipment_id, employee_id, admin_id, justification, violations) VALUES ($1, $2, $3, $4, $5)",
+		equipmentID, userID, override.AdminID, override.Justification, payload,
+	); err != nil {
+		return fmt.Errorf("ошибка при записи исключения из политик: %v", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"equipment_id":  equipmentID,
+		"employee_id":   userID,
+		"admin_id":      override.AdminID,
+		"justification": override.Justification,
+		"violations":    len(violations),
+	}).Warn("Оборудование выдано вопреки политикам по решению администратора")
+	return nil
+}
+
+// containsFold сообщает, содержит ли список значение без учёта регистра
+func containsFold(values []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,423 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"inva/pkg/validation"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Статусы заказов поставщикам
+const (
+	PurchaseOrderOpen              = "open"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+)
+
+// Ограничения заказа поставщику
+const (
+	maxOrderNumberLength = 50
+	maxOrderLines        = 100
+	maxOrderLineQuantity = 1000
+	maxWarrantyMonths    = 600
+)
+
+// receivedStatus статус оборудования, принятого по заказу
+const receivedStatus = "available"
+
+// PurchaseOrderLine позиция заказа: модель, количество и цена за единицу
+type PurchaseOrderLine struct {
+	ID               int     `json:"id"`
+	Model            string  `json:"model"`
+	Category         string  `json:"category,omitempty"`
+	Quantity         int     `json:"quantity"`
+	UnitCost         float64 `json:"unit_cost"`
+	WarrantyMonths   int     `json:"warranty_months,omitempty"`
+	ReceivedQuantity int     `json:"received_quantity"`
+}
+
+// PurchaseOrder заказ поставщику с позициями
+type PurchaseOrder struct {
+	ID         int                 `json:"id"`
+	VendorID   int                 `json:"vendor_id"`
+	VendorName string              `json:"vendor_name,omitempty"`
+	Number     string              `json:"number"`
+	Status     string              `json:"status"`
+	Currency   string              `json:"currency"`
+	OrderedAt  time.Time           `json:"ordered_at"`
+	Lines      []PurchaseOrderLine `json:"lines"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// PurchaseOrderFilter условия выборки заказов; нулевые поля не ограничивают выборку
+type PurchaseOrderFilter struct {
+	VendorID int
+	Status   string
+}
+
+// Receipt приёмка части позиции заказа: по одной единице оборудования на каждый серийный номер
+type Receipt struct {
+	SerialNumbers []string
+	Location      string
+	// ReceivedAt дата приёмки, она же дата покупки оборудования; nil означает сегодня
+	ReceivedAt *time.Time
+}
+
+// ReceiptResult результат приёмки: созданное оборудование и остаток позиции
+type ReceiptResult struct {
+	OrderID     int         `json:"order_id"`
+	LineID      int         `json:"line_id"`
+	OrderStatus string      `json:"order_status"`
+	Received    int         `json:"received"`
+	Remaining   int         `json:"remaining"`
+	Equipment   []Equipment `json:"equipment"`
+}
+
+// PurchaseOrderService предоставляет методы для заказов поставщикам и приёмки оборудования
+type PurchaseOrderService struct {
+	db *sql.DB
+}
+
+// NewPurchaseOrderService создаёт новый экземпляр PurchaseOrderService
+func NewPurchaseOrderService(db *sql.DB) *PurchaseOrderService {
+	return &PurchaseOrderService{db: db}
+}
+
+// ValidatePurchaseOrder проверяет заказ перед сохранением; пустая валюта заменяется валютой по умолчанию
+func ValidatePurchaseOrder(order *PurchaseOrder) error {
+	v := &ValidationError{}
+	if order.VendorID < 1 {
+		v.Add("vendor_id", validation.CodeRequired, "обязательное поле")
+	}
+	v.RequireString("number", order.Number, maxOrderNumberLength)
+	if order.Currency == "" {
+		order.Currency = DefaultCurrency
+	}
+	if !isCurrencyCode(order.Currency) {
+		v.Add("currency", validation.CodeInvalid, "ожидается трёхбуквенный код валюты ISO 4217, например USD")
+	}
+	if len(order.Lines) == 0 {
+		v.Add("lines", validation.CodeRequired, "заказ должен содержать хотя бы одну позицию")
+	}
+	if len(order.Lines) > maxOrderLines {
+		v.Add("lines", validation.CodeTooLong, "должно содержать не больше %d элементов", maxOrderLines)
+	}
+	for i, line := range order.Lines {
+		field := fmt.Sprintf("lines[%d]", i)
+		v.RequireString(field+".model", line.Model, maxModelLength)
+		v.MaxLength(field+".category", line.Category, maxCategoryLength)
+		if line.Quantity < 1 || line.Quantity > maxOrderLineQuantity {
+			v.Add(field+".quantity", validation.CodeOutOfRange, "должно быть от 1 до %d", maxOrderLineQuantity)
+		}
+		if line.UnitCost < 0 {
+			v.Add(field+".unit_cost", validation.CodeOutOfRange, "должно быть не меньше 0")
+		}
+		if line.WarrantyMonths < 0 || line.WarrantyMonths > maxWarrantyMonths {
+			v.Add(field+".warranty_months", validation.CodeOutOfRange, "должно быть от 0 до %d", maxWarrantyMonths)
+		}
+	}
+	return v.ErrOrNil()
+}
+
+// CreatePurchaseOrder сохраняет заказ поставщику вместе с позициями; номер заказа уникален
+func (s *PurchaseOrderService) CreatePurchaseOrder(order *PurchaseOrder) (*PurchaseOrder, error) {
+	if err := ValidatePurchaseOrder(order); err != nil {
+		return nil, err
+	}
+	if order.OrderedAt.IsZero() {
+		now := time.Now()
+		order.OrderedAt = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	var id int
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		var vendorExists, numberTaken bool
+		if err := tx.QueryRow(
+			"SELECT EXISTS (SELECT 1 FROM vendors WHERE id = $1), EXISTS (SELECT 1 FROM purchase_orders WHERE number = $2)",
+			order.VendorID, order.Number,
+		).Scan(&vendorExists, &numberTaken); err != nil {
+			return fmt.Errorf("ошибка при проверке заказа: %v", err)
+		}
+		if !vendorExists {
+			return fmt.Errorf("%w (id %d)", ErrVendorNotFound, order.VendorID)
+		}
+		if numberTaken {
+			return validation.New("number", validation.CodeDuplicate, "заказ %q уже существует", order.Number)
+		}
+
+		if err := tx.QueryRow(
+			"INSERT INTO purchase_orders (vendor_id, number, status, currency, ordered_at) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+			order.VendorID, order.Number, PurchaseOrderOpen, order.Currency, order.OrderedAt,
+		).Scan(&id); err != nil {
+			return fmt.Errorf("ошибка при создании заказа: %v", err)
+		}
+
+		for i, line := range order.Lines {
+			if _, err := tx.Exec(
+				`INSERT INTO purchase_order_lines (order_id, position, model, category, quantity, unit_cost, warranty_months)
+				VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, NULLIF($7, 0))`,
+				id, i, line.Model, line.Category, line.Quantity, line.UnitCost, line.WarrantyMonths,
+			); err != nil {
+				return fmt.Errorf("ошибка при сохранении позиции заказа: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPurchaseOrder(id)
+}
+
+// purchaseOrderColumns столбцы выборки заказа вместе с названием поставщика
+const purchaseOrderColumns = `o.id, o.vendor_id, v.name, o.number, o.status, o.currency, o.ordered_at, o.created_at
+	FROM purchase_orders o
+	JOIN vendors v ON v.id = o.vendor_id`
+
+// scanPurchaseOrder читает заказ из строки, выбранной по purchaseOrderColumns
+func scanPurchaseOrder(row interface{ Scan(...interface{}) error }) (*PurchaseOrder, error) {
+	var order PurchaseOrder
+	err := row.Scan(&order.ID, &order.VendorID, &order.VendorName, &order.Number, &order.Status, &order.Currency,
+		&order.OrderedAt, &order.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetPurchaseOrders возвращает заказы с позициями, начиная с последних
+func (s *PurchaseOrderService) GetPurchaseOrders(filter PurchaseOrderFilter) ([]PurchaseOrder, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.VendorID > 0 {
+		args = append(args, filter.VendorID)
+		conditions = append(conditions, fmt.Sprintf("o.vendor_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("o.status = $%d", len(args)))
+	}
+
+	query := "SELECT " + purchaseOrderColumns
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	rows, err := s.db.Query(query+" ORDER BY o.ordered_at DESC, o.id DESC", args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении заказов: %v", err)
+	}
+	defer rows.Close()
+
+	orders := []PurchaseOrder{}
+	for rows.Next() {
+		order, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		orders = append(orders, *order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при переборе строк: %v", err)
+	}
+
+	for i := range orders {
+		if orders[i].Lines, err = queryOrderLines(s.db, orders[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return orders, nil
+}
+
+// GetPurchaseOrder возвращает заказ с позициями
+func (s *PurchaseOrderService) GetPurchaseOrder(id int) (*PurchaseOrder, error) {
+	order, err := scanPurchaseOrder(s.db.QueryRow("SELECT "+purchaseOrderColumns+" WHERE o.id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w (id %d)", ErrPurchaseOrderNotFound, id)
+		}
+		return nil, fmt.Errorf("ошибка при получении заказа: %v", err)
+	}
+	if order.Lines, err = queryOrderLines(s.db, id); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// queryOrderLines возвращает позиции заказа в порядке их добавления
+func queryOrderLines(q queryer, orderID int) ([]PurchaseOrderLine, error) {
+	rows, err := q.Query(
+		`SELECT id, model, COALESCE(category, ''), quantity, unit_cost, COALESCE(warranty_months, 0), received_quantity
+		FROM purchase_order_lines WHERE order_id = $1 ORDER BY position`, orderID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении позиций заказа: %v", err)
+	}
+	defer rows.Close()
+
+	lines := []PurchaseOrderLine{}
+	for rows.Next() {
+		var line PurchaseOrderLine
+		if err := rows.Scan(&line.ID, &line.Model, &line.Category, &line.Quantity, &line.UnitCost,
+			&line.WarrantyMonths, &line.ReceivedQuantity); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при переборе строк: %v", err)
+	}
+	return lines, nil
+}
+
+// validateReceipt проверяет серийные номера и место размещения принимаемого оборудования
+func validateReceipt(receipt Receipt) error {
+	v := &ValidationError{}
+	if len(receipt.SerialNumbers) == 0 {
+		v.Add("serial_numbers", validation.CodeRequired, "укажите серийные номера принимаемого оборудования")
+	}
+	seen := make(map[string]int, len(receipt.SerialNumbers))
+	for i, serial := range receipt.SerialNumbers {
+		field := fmt.Sprintf("serial_numbers[%d]", i)
+		v.RequireString(field, serial, maxSerialNumberLength)
+		if first, ok := seen[serial]; ok && serial != "" {
+			v.Add(field, validation.CodeDuplicate, "совпадает с serial_numbers[%d]", first)
+			continue
+		}
+		seen[serial] = i
+	}
+	v.MaxLength("location", receipt.Location, maxLocationLength)
+	return v.ErrOrNil()
+}
+
+// ReceiveLine принимает оборудование по позиции заказа: для каждого серийного номера создаётся единица
+// со стоимостью, валютой и гарантией из заказа, а статус заказа пересчитывается. Принять больше,
+// чем осталось по позиции, нельзя; если хотя бы один номер уже есть в инвентаре, ничего не создаётся.
+func (s *PurchaseOrderService) ReceiveLine(orderID, lineID int, receipt Receipt) (*ReceiptResult, error) {
+	if err := validateReceipt(receipt); err != nil {
+		return nil, err
+	}
+	receivedAt := time.Now()
+	if receipt.ReceivedAt != nil {
+		receivedAt = *receipt.ReceivedAt
+	}
+	purchaseDate := time.Date(receivedAt.Year(), receivedAt.Month(), receivedAt.Day(), 0, 0, 0, 0, time.UTC)
+
+	result := &ReceiptResult{OrderID: orderID, LineID: lineID, Received: len(receipt.SerialNumbers)}
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		var line PurchaseOrderLine
+		var currency string
+		err := tx.QueryRow(
+			`SELECT l.model, COALESCE(l.category, ''), l.quantity, l.unit_cost, COALESCE(l.warranty_months, 0), l.received_quantity, o.currency
+			FROM purchase_order_lines l
+			JOIN purchase_orders o ON o.id = l.order_id
+			WHERE l.id = $1 AND l.order_id = $2
+			FOR UPDATE OF l, o`, lineID, orderID,
+		).Scan(&line.Model, &line.Category, &line.Quantity, &line.UnitCost, &line.WarrantyMonths, &line.ReceivedQuantity, &currency)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w (заказ %d, позиция %d)", ErrPurchaseOrderLineNotFound, orderID, lineID)
+			}
+			return fmt.Errorf("ошибка при получении позиции заказа: %v", err)
+		}
+
+		remaining := line.Quantity - line.ReceivedQuantity
+		if remaining == 0 {
+			return fmt.Errorf("%w (заказ %d, позиция %d)", ErrPurchaseOrderLineReceived, orderID, lineID)
+		}
+		if len(receipt.SerialNumbers) > remaining {
+			return validation.New("serial_numbers", validation.CodeOutOfRange,
+				"по позиции осталось принять %d из %d", remaining, line.Quantity)
+		}
+
+		if err := checkSerialsFree(tx, receipt.SerialNumbers); err != nil {
+			return err
+		}
+
+		var warrantyExpiresAt *time.Time
+		if line.WarrantyMonths > 0 {
+			expires := purchaseDate.AddDate(0, line.WarrantyMonths, 0)
+			warrantyExpiresAt = &expires
+		}
+		for _, serial := range receipt.SerialNumbers {
+			equipment := Equipment{
+				Model:               line.Model,
+				SerialNumber:        serial,
+				Status:              receivedStatus,
+				Location:            receipt.Location,
+				Category:            line.Category,
+				PurchaseCost:        &line.UnitCost,
+				PurchaseDate:        &purchaseDate,
+				WarrantyExpiresAt:   warrantyExpiresAt,
+				Currency:            currency,
+				PurchaseOrderLineID: &lineID,
+			}
+			if err := tx.QueryRow(
+				`INSERT INTO equipment (model, serial_number, status, location, category, purchase_cost, purchase_date,
+				warranty_expires_at, currency, purchase_order_line_id)
+				VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9, $10) RETURNING id, version`,
+				equipment.Model, equipment.SerialNumber, equipment.Status, equipment.Location, equipment.Category,
+				equipment.PurchaseCost, equipment.PurchaseDate, equipment.WarrantyExpiresAt, equipment.Currency,
+				equipment.PurchaseOrderLineID,
+			).Scan(&equipment.ID, &equipment.Version); err != nil {
+				return fmt.Errorf("ошибка при создании оборудования по заказу: %v", err)
+			}
+			result.Equipment = append(result.Equipment, equipment)
+		}
+
+		if _, err := tx.Exec(
+			"UPDATE purchase_order_lines SET received_quantity = received_quantity + $1 WHERE id = $2",
+			len(receipt.SerialNumbers), lineID,
+		); err != nil {
+			return fmt.Errorf("ошибка при обновлении позиции заказа: %v", err)
+		}
+		result.Remaining = remaining - len(receipt.SerialNumbers)
+
+		// Заказ считается полученным, когда по всем позициям принято заказанное количество
+		if err := tx.QueryRow(
+			`UPDATE purchase_orders SET status = CASE
+				WHEN EXISTS (SELECT 1 FROM purchase_order_lines WHERE order_id = $1 AND received_quantity < quantity) THEN $2
+				ELSE $3 END
+			WHERE id = $1 RETURNING status`,
+			orderID, PurchaseOrderPartiallyReceived, PurchaseOrderReceived,
+		).Scan(&result.OrderStatus); err != nil {
+			return fmt.Errorf("ошибка при обновлении статуса заказа: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkSerialsFree проверяет, что серийные номера ещё не заняты другим оборудованием
+func checkSerialsFree(q queryer, serials []string) error {
+	rows, err := q.Query("SELECT serial_number FROM equipment WHERE serial_number = ANY($1)", pq.Array(serials))
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке серийных номеров: %v", err)
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var serial string
+		if err := rows.Scan(&serial); err != nil {
+			return fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		taken[serial] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при переборе строк: %v", err)
+	}
+
+	v := &ValidationError{}
+	for i, serial := range serials {
+		if taken[serial] {
+			v.Add(fmt.Sprintf("serial_numbers[%d]", i), validation.CodeDuplicate, "оборудование с серийным номером %q уже есть", serial)
+		}
+	}
+	return v.ErrOrNil()
+}
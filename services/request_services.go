@@ -0,0 +1,300 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"inva/pkg/validation"
+	"strings"
+	"time"
+)
+
+// Статусы заявки на оборудование: новая заявка ждёт решения руководителя,
+// одобренная — выдачи конкретной единицы кладовщиком
+const (
+	RequestStatusPending   = "pending"
+	RequestStatusApproved  = "approved"
+	RequestStatusRejected  = "rejected"
+	RequestStatusFulfilled = "fulfilled"
+	RequestStatusCancelled = "cancelled"
+)
+
+// EquipmentRequest представляет заявку сотрудника на оборудование
+type EquipmentRequest struct {
+	ID            int        `json:"id"`
+	EmployeeID    int        `json:"employee_id"`
+	Category      string     `json:"category"`
+	Justification string     `json:"justification"`
+	Status        string     `json:"status"`
+	ManagerID     *int       `json:"manager_id"`
+	DecisionNote  string     `json:"decision_note,omitempty"`
+	EquipmentID   *int       `json:"equipment_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DecidedAt     *time.Time `json:"decided_at"`
+	FulfilledAt   *time.Time `json:"fulfilled_at"`
+}
+
+// RequestFilter условия отбора заявок; пустые поля не ограничивают выборку
+type RequestFilter struct {
+	Status     string
+	EmployeeID int
+}
+
+// RequestService предоставляет методы для заявок сотрудников на оборудование
+type RequestService struct {
+	db *sql.DB
+}
+
+// NewRequestService создаёт новый экземпляр RequestService
+func NewRequestService(db *sql.DB) *RequestService {
+	return &RequestService{db: db}
+}
+
+// CreateRequest регистрирует заявку активного сотрудника на оборудование указанной категории
+func (s *RequestService) CreateRequest(employeeID int, category, justification string) (*EquipmentRequest, error) {
+	v := &ValidationError{}
+	v.RequireString("category", category, maxCategoryLength)
+	v.RequireString("justification", justification, maxJustification)
+	if err := v.ErrOrNil(); err != nil {
+		return nil, err
+	}
+
+	var id int
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		// Уволенные и увольняемые сотрудники не могут запрашивать оборудование
+		if err := requireActiveEmployee(tx, employeeID); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(
+			"INSERT INTO equipment_requests (employee_id, category, justification, status) VALUES ($1, $2, $3, $4) RETURNING id",
+			employeeID, category, justification, RequestStatusPending,
+		).Scan(&id); err != nil {
+			return fmt.Errorf("ошибка при создании заявки: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return queryRequest(s.db, id)
+}
+
+// GetRequests возвращает заявки, подходящие под фильтр, начиная с самых старых
+func (s *RequestService) GetRequests(filter RequestFilter) ([]EquipmentRequest, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.EmployeeID > 0 {
+		args = append(args, filter.EmployeeID)
+		conditions = append(conditions, fmt.Sprintf("employee_id = $%d", len(args)))
+	}
+
+	query := requestColumns
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	rows, err := s.db.Query(query+" ORDER BY id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении заявок: %v", err)
+	}
+	defer rows.Close()
+
+	requests := []EquipmentRequest{}
+	for rows.Next() {
+		request, err := scanRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении заявок: %v", err)
+	}
+	return requests, nil
+}
+
+// GetRequest возвращает заявку по идентификатору
+func (s *RequestService) GetRequest(id int) (*EquipmentRequest, error) {
+	return queryRequest(s.db, id)
+}
+
+// Approve одобряет заявку; решение может принять только руководитель или администратор
+func (s *RequestService) Approve(id, managerID int, note string) (*EquipmentRequest, error) {
+	return s.decide(id, managerID, RequestStatusApproved, note)
+}
+
+// Reject отклоняет заявку с указанием причины; решение может принять только руководитель или администратор
+func (s *RequestService) Reject(id, managerID int, note string) (*EquipmentRequest, error) {
+	v := &ValidationError{}
+	v.RequireString("note", note, maxJustification)
+	if err := v.ErrOrNil(); err != nil {
+		return nil, err
+	}
+	return s.decide(id, managerID, RequestStatusRejected, note)
+}
+
+// Cancel отзывает заявку, по которой ещё не выдано оборудование
+func (s *RequestService) Cancel(id int) (*EquipmentRequest, error) {
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		request, err := lockRequest(tx, id)
+		if err != nil {
+			return err
+		}
+		if request.Status != RequestStatusPending && request.Status != RequestStatusApproved {
+			return fmt.Errorf("%w (id %d, статус %s)", ErrRequestClosed, id, request.Status)
+		}
+		_, err = tx.Exec("UPDATE equipment_requests SET status = $1 WHERE id = $2", RequestStatusCancelled, id)
+		if err != nil {
+			return fmt.Errorf("ошибка при отзыве заявки: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return queryRequest(s.db, id)
+}
+
+// Fulfill выполняет одобренную заявку: указанная единица оборудования закрепляется за автором заявки
+// с проверкой политик выдачи. Категория оборудования должна совпадать с категорией заявки.
+func (s *RequestService) Fulfill(id, equipmentID int, opts AssignOptions) (*EquipmentRequest, error) {
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		request, err := lockRequest(tx, id)
+		if err != nil {
+			return err
+		}
+		if request.Status != RequestStatusApproved {
+			return fmt.Errorf("%w (id %d, статус %s)", ErrRequestNotApproved, id, request.Status)
+		}
+
+		var category string
+		err = tx.QueryRow("SELECT COALESCE(category, '') FROM equipment WHERE id = $1", equipmentID).Scan(&category)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w (id %d)", ErrEquipmentNotFound, equipmentID)
+			}
+			return fmt.Errorf("ошибка при получении оборудования: %v", err)
+		}
+		if !strings.EqualFold(category, request.Category) {
+			v := &ValidationError{}
+			v.Add("equipment_id", validation.CodeNotAllowed, "категория оборудования %q не совпадает с категорией заявки %q", category, request.Category)
+			return v
+		}
+
+		if opts.Note == "" {
+			opts.Note = fmt.Sprintf("заявка #%d", id)
+		}
+		if err := assignEquipment(tx, equipmentID, request.EmployeeID, opts); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"UPDATE equipment_requests SET status = $1, equipment_id = $2, fulfilled_at = NOW() WHERE id = $3",
+			RequestStatusFulfilled, equipmentID, id,
+		)
+		if err != nil {
+			return fmt.Errorf("ошибка при выполнении заявки: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return queryRequest(s.db, id)
+}
+
+// decide записывает решение руководителя по заявке, ожидающей рассмотрения; автор заявки решение не принимает
+func (s *RequestService) decide(id, managerID int, status, note string) (*EquipmentRequest, error) {
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		request, err := lockRequest(tx, id)
+		if err != nil {
+			return err
+		}
+		if request.Status != RequestStatusPending {
+			return fmt.Errorf("%w (id %d, статус %s)", ErrRequestClosed, id, request.Status)
+		}
+		if managerID == request.EmployeeID {
+			return fmt.Errorf("%w (заявка %d: автор заявки не может принять по ней решение)", ErrApprovalDenied, id)
+		}
+		if err := requireApprover(tx, managerID); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"UPDATE equipment_requests SET status = $1, manager_id = $2, decision_note = NULLIF($3, ''), decided_at = NOW() WHERE id = $4",
+			status, managerID, note, id,
+		)
+		if err != nil {
+			return fmt.Errorf("ошибка при записи решения по заявке: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return queryRequest(s.db, id)
+}
+
+// requireApprover проверяет, что сотрудник может принимать решения по заявкам
+func requireApprover(q queryer, managerID int) error {
+	var role string
+	err := q.QueryRow("SELECT role FROM employees WHERE id = $1", managerID).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w (id %d)", ErrEmployeeNotFound, managerID)
+		}
+		return fmt.Errorf("ошибка при проверке руководителя: %v", err)
+	}
+	if role != EmployeeRoleManager && role != EmployeeRoleAdmin {
+		return fmt.Errorf("%w (сотрудник %d, роль %s)", ErrApprovalDenied, managerID, role)
+	}
+	return nil
+}
+
+// lockRequest блокирует заявку до конца транзакции и возвращает её статус, автора и категорию
+func lockRequest(q queryer, id int) (*EquipmentRequest, error) {
+	request := &EquipmentRequest{ID: id}
+	err := q.QueryRow(
+		"SELECT status, employee_id, category FROM equipment_requests WHERE id = $1 FOR UPDATE", id,
+	).Scan(&request.Status, &request.EmployeeID, &request.Category)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w (id %d)", ErrRequestNotFound, id)
+		}
+		return nil, fmt.Errorf("ошибка при получении заявки: %v", err)
+	}
+	return request, nil
+}
+
+// requestColumns столбцы заявки на оборудование
+const requestColumns = `SELECT id, employee_id, category, justification, status, manager_id,
+	COALESCE(decision_note, ''), equipment_id, created_at, decided_at, fulfilled_at
+	FROM equipment_requests`
+
+// queryRequest возвращает заявку по идентификатору
+func queryRequest(q queryer, id int) (*EquipmentRequest, error) {
+	request, err := scanRequest(q.QueryRow(requestColumns+" WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w (id %d)", ErrRequestNotFound, id)
+	}
+	return request, err
+}
+
+// scanRequest читает заявку из строки результата
+func scanRequest(row interface{ Scan(...interface{}) error }) (*EquipmentRequest, error) {
+	var request EquipmentRequest
+	err := row.Scan(
+		&request.ID, &request.EmployeeID, &request.Category, &request.Justification, &request.Status, &request.ManagerID,
+		&request.DecisionNote, &request.EquipmentID, &request.CreatedAt, &request.DecidedAt, &request.FulfilledAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("ошибка при чтении заявки: %v", err)
+	}
+	return &request, nil
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Статусы бронирования
+const (
+	ReservationStatusActive    = "active"
+	ReservationStatusCancelled = "cancelled"
+)
+
+// Reservation представляет бронирование оборудования на интервал времени
+type Reservation struct {
+	ID          int       `json:"id"`
+	EquipmentID int       `json:"equipment_id"`
+	UserID      int       `json:"user_id"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Note        string    `json:"note"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ReservationService предоставляет методы для бронирования общего оборудования
+type ReservationService struct {
+	db *sql.DB
+}
+
+// NewReservationService создаёт новый экземпляр ReservationService
+func NewReservationService(db *sql.DB) *ReservationService {
+	return &ReservationService{db: db}
+}
+
+// CreateReservation бронирует оборудование, проверяя пересечения с другими бронированиями и текущей выдачей;
+// утилизированное, списанное и находящееся в ремонте оборудование не бронируется
+func (s *ReservationService) CreateReservation(reservation *Reservation) (*Reservation, error) {
+	if !reservation.EndsAt.After(reservation.StartsAt) {
+		return nil, fmt.Errorf("%w: окончание должно быть позже начала", ErrInvalidReservation)
+	}
+	if reservation.EndsAt.Before(time.Now()) {
+		return nil, fmt.Errorf("%w: интервал уже завершился", ErrInvalidReservation)
+	}
+
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		// Блокировка строки оборудования сериализует конкурирующие бронирования
+		locked, err := lockEquipment(tx, reservation.EquipmentID)
+		if err != nil {
+			return err
+		}
+		if err := locked.requireInService(reservation.EquipmentID); err != nil {
+			return err
+		}
+
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1)", reservation.UserID).Scan(&exists); err != nil {
+			return fmt.Errorf("ошибка при проверке сотрудника: %v", err)
+		}
+		if !exists {
+			return fmt.Errorf("%w (id %d)", ErrEmployeeNotFound, reservation.UserID)
+		}
+
+		if err := checkReservationConflicts(tx, reservation.EquipmentID, reservation.StartsAt, reservation.EndsAt); err != nil {
+			return err
+		}
+
+		reservation.Status = ReservationStatusActive
+		err = tx.QueryRow(
+			`INSERT INTO reservations (equipment_id, user_id, starts_at, ends_at, note, status)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+			reservation.EquipmentID, reservation.UserID, reservation.StartsAt, reservation.EndsAt, reservation.Note, reservation.Status,
+		).Scan(&reservation.ID, &reservation.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("ошибка при создании бронирования: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// checkReservationConflicts проверяет пересечение интервала с активными бронированиями и текущей выдачей
+func checkReservationConflicts(q queryer, equipmentID int, startsAt, endsAt time.Time) error {
+	var conflictID int
+	err := q.QueryRow(
+		`SELECT id FROM reservations
+		WHERE equipment_id = $1 AND status = $2 AND starts_at < $4 AND ends_at > $3
+		ORDER BY starts_at LIMIT 1`,
+		equipmentID, ReservationStatusActive, startsAt, endsAt,
+	).Scan(&conflictID)
+	if err == nil {
+		return fmt.Errorf("%w (бронирование %d)", ErrReservationConflict, conflictID)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("ошибка при проверке пересечений бронирований: %v", err)
+	}
+
+	// Бессрочная выдача пересекается с любым интервалом, выдача со сроком — до даты возврата
+	var dueAt *time.Time
+	err = q.QueryRow(
+		"SELECT due_at FROM equipment_logs WHERE equipment_id = $1 AND returned_at IS NULL",
+		equipmentID,
+	).Scan(&dueAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке текущей выдачи: %v", err)
+	}
+	if dueAt == nil || dueAt.After(startsAt) {
+		return fmt.Errorf("%w (оборудование выдано)", ErrReservationConflict)
+	}
+
+	return nil
+}
+
+// CancelReservation отменяет активное бронирование
+func (s *ReservationService) CancelReservation(id int) error {
+	result, err := s.db.Exec(
+		"UPDATE reservations SET status = $1 WHERE id = $2 AND status = $3",
+		ReservationStatusCancelled, id, ReservationStatusActive,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при отмене бронирования: %v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при отмене бронирования: %v", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w (id %d)", ErrReservationNotFound, id)
+	}
+
+	return nil
+}
+
+// ListReservations возвращает бронирования оборудования, заканчивающиеся не раньше from
+func (s *ReservationService) ListReservations(equipmentID int, from time.Time, includeCancelled bool) ([]Reservation, error) {
+	query := `SELECT id, equipment_id, user_id, starts_at, ends_at, note, status, created_at
+		FROM reservations WHERE equipment_id = $1 AND ends_at >= $2`
+	args := []interface{}{equipmentID, from}
+	if !includeCancelled {
+		query += " AND status = $3"
+		args = append(args, ReservationStatusActive)
+	}
+	query += " ORDER BY starts_at"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении бронирований: %v", err)
+	}
+	defer rows.Close()
+
+	reservations := []Reservation{}
+	for rows.Next() {
+		var reservation Reservation
+		if err := rows.Scan(&reservation.ID, &reservation.EquipmentID, &reservation.UserID, &reservation.StartsAt,
+			&reservation.EndsAt, &reservation.Note, &reservation.Status, &reservation.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при переборе строк: %v", err)
+	}
+
+	return reservations, nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Действия, выполняемые на стойке выдачи по сканированию
+const (
+	ScanActionCheckout = "checkout"
+	ScanActionCheckin  = "checkin"
+)
+
+// ScanResult представляет подтверждение операции, выполненной по сканированию
+type ScanResult struct {
+	Action    string     `json:"action"`
+	AssetTag  string     `json:"asset_tag"`
+	Equipment *Equipment `json:"equipment"`
+	Employee  *Employee  `json:"employee"`
+	Message   string     `json:"message"`
+	At        time.Time  `json:"at"`
+}
+
+// ScanService выполняет выдачу и возврат оборудования по отсканированным идентификаторам
+type ScanService struct {
+	equipment *EquipmentService
+	employees *EmployeeService
+}
+
+// NewScanService создаёт новый экземпляр ScanService
+func NewScanService(equipment *EquipmentService, employees *EmployeeService) *ScanService {
+	return &ScanService{equipment: equipment, employees: employees}
+}
+
+// ResolveEquipment находит оборудование по инвентарному или серийному номеру
+func (s *ScanService) ResolveEquipment(identifier string) (*Equipment, error) {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return nil, fmt.Errorf("%w: пустой идентификатор", ErrEquipmentNotFound)
+	}
+
+	if id, ok := ParseAssetTag(identifier); ok {
+		return s.equipment.GetEquipmentByID(id)
+	}
+
+	return s.equipment.GetEquipmentBySerialNumber(identifier)
+}
+
+// ResolveEmployee находит сотрудника по номеру пропуска или адресу электронной почты
+func (s *ScanService) ResolveEmployee(identifier string) (*Employee, error) {
+	identifier = strings.TrimSpace(identifier)
+	if id, ok := ParseBadgeID(identifier); ok {
+		return s.employees.GetEmployeeByID(id)
+	}
+	if strings.Contains(identifier, "@") {
+		return s.employees.GetEmployeeByEmail(identifier)
+	}
+	return nil, fmt.Errorf("%w: нераспознанный пропуск %q", ErrEmployeeNotFound, identifier)
+}
+
+// Checkout выдаёт отсканированное оборудование отсканированному сотруднику
+func (s *ScanService) Checkout(assetIdentifier, badgeIdentifier string) (*ScanResult, error) {
+	equipment, err := s.ResolveEquipment(assetIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := s.ResolveEmployee(badgeIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.equipment.AssignEquipmentToUser(equipment.ID, employee.ID); err != nil {
+		return nil, err
+	}
+	equipment.AssignedTo = &employee.ID
+
+	tag := AssetTag(equipment.ID)
+	return &ScanResult{
+		Action:    ScanActionCheckout,
+		AssetTag:  tag,
+		Equipment: equipment,
+		Employee:  employee,
+		Message:   fmt.Sprintf("%s (%s) checked out to %s", equipment.Model, tag, employee.Name),
+		At:        time.Now(),
+	}, nil
+}
+
+// Checkin принимает отсканированное оборудование обратно
+func (s *ScanService) Checkin(assetIdentifier string) (*ScanResult, error) {
+	equipment, err := s.ResolveEquipment(assetIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if equipment.AssignedTo == nil {
+		return nil, fmt.Errorf("%w (id %d)", ErrEquipmentNotAssigned, equipment.ID)
+	}
+
+	// Сотрудник мог быть удалён, подтверждение возврата от этого не зависит
+	employee, err := s.employees.GetEmployeeByID(*equipment.AssignedTo)
+	if err != nil {
+		employee = &Employee{ID: *equipment.AssignedTo}
+	}
+
+	if err := s.equipment.ReturnEquipmentFromUser(equipment.ID); err != nil {
+		return nil, err
+	}
+	equipment.AssignedTo = nil
+
+	holder := employee.Name
+	if holder == "" {
+		holder = BadgeID(employee.ID)
+	}
+
+	tag := AssetTag(equipment.ID)
+	return &ScanResult{
+		Action:    ScanActionCheckin,
+		AssetTag:  tag,
+		Equipment: equipment,
+		Employee:  employee,
+		Message:   fmt.Sprintf("%s (%s) returned by %s", equipment.Model, tag, holder),
+		At:        time.Now(),
+	}, nil
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// queryer общий интерфейс *sql.DB и *sql.Tx, позволяющий выполнять одну и ту же логику в транзакции и вне её
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// withTx выполняет fn в транзакции, откатывая её при ошибке
+func withTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %v", err)
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback() // откат в случае ошибки
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при подтверждении транзакции: %v", err)
+	}
+
+	return nil
+}
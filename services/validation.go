@@ -0,0 +1,92 @@
+package services
+
+import (
+	"strings"
+
+	"inva/pkg/validation"
+)
+
+// Ограничения на длину полей, совпадающие со схемой базы данных
+const (
+	maxModelLength           = 255
+	maxSerialNumberLength    = 100
+	maxStatusLength          = 50
+	maxNameLength            = 255
+	maxLocationLength        = 255
+	maxCategoryLength        = 100
+	maxKitNameLength         = 100
+	maxKitItemQuantity       = 50
+	maxDepartmentLength      = 100
+	maxPositionLength        = 100
+	maxJustification         = 500
+	maxSupportContractLength = 255
+	maxEmailLength           = 255
+)
+
+// FieldError описывает ошибку проверки одного поля
+type FieldError = validation.FieldError
+
+// ValidationError набор ошибок проверки входных данных
+type ValidationError = validation.Error
+
+// ValidateEquipment проверяет данные оборудования перед сохранением
+func ValidateEquipment(model, serialNumber, status string) error {
+	v := &ValidationError{}
+	checkEquipment(v, model, serialNumber, status)
+	return v.ErrOrNil()
+}
+
+// ValidateNewEquipment проверяет данные нового оборудования: кроме основных полей,
+// статус не может быть статусом процесса, например disposed или in_repair
+func ValidateNewEquipment(model, serialNumber, status string) error {
+	v := &ValidationError{}
+	checkEquipment(v, model, serialNumber, status)
+	checkStatusChange(v, "", status)
+	return v.ErrOrNil()
+}
+
+// workflowStatuses статусы оборудования, которые устанавливаются и снимаются только своими процессами,
+// с названием процесса для сообщения об ошибке
+var workflowStatuses = map[string]string{
+	EquipmentStatusDisposed: "утилизацию",
+	HistoryStatusWrittenOff: "списание при увольнении",
+	EquipmentStatusInRepair: "передачу в ремонт и его закрытие",
+}
+
+// checkStatusChange запрещает обычным изменением оборудования устанавливать статус процесса
+// или снимать его; неизменённый статус допускается
+func checkStatusChange(v *ValidationError, current, next string) {
+	if next == current {
+		return
+	}
+	if process, ok := workflowStatuses[next]; ok {
+		v.Add("status", validation.CodeNotAllowed, "статус %s устанавливается только через %s", next, process)
+		return
+	}
+	if process, ok := workflowStatuses[current]; ok {
+		v.Add("status", validation.CodeNotAllowed, "статус %s меняется только через %s", current, process)
+	}
+}
+
+// checkEquipment добавляет ошибки проверки основных полей оборудования
+func checkEquipment(v *ValidationError, model, serialNumber, status string) {
+	v.RequireString("model", model, maxModelLength)
+	v.MaxLength("serial_number", serialNumber, maxSerialNumberLength)
+	v.RequireString("status", status, maxStatusLength)
+}
+
+// ValidateEmployee проверяет данные сотрудника перед сохранением; email необязателен
+func ValidateEmployee(name, email string) error {
+	v := &ValidationError{}
+	v.RequireString("name", name, maxNameLength)
+	checkEmail(v, email)
+	return v.ErrOrNil()
+}
+
+// checkEmail проверяет необязательный адрес электронной почты сотрудника
+func checkEmail(v *ValidationError, email string) {
+	v.MaxLength("email", email, maxEmailLength)
+	if email != "" && !strings.Contains(email, "@") {
+		v.Add("email", validation.CodeInvalid, "ожидается адрес электронной почты")
+	}
+}
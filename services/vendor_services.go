@@ -0,0 +1,118 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"inva/pkg/validation"
+	"strings"
+	"time"
+)
+
+// Ограничения на длину полей поставщика
+const (
+	maxContactLength = 255
+	maxPhoneLength   = 50
+)
+
+// Vendor поставщик оборудования
+type Vendor struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	ContactEmail string    `json:"contact_email,omitempty"`
+	Phone        string    `json:"phone,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// VendorService предоставляет методы для работы с поставщиками
+type VendorService struct {
+	db *sql.DB
+}
+
+// NewVendorService создаёт новый экземпляр VendorService
+func NewVendorService(db *sql.DB) *VendorService {
+	return &VendorService{db: db}
+}
+
+// ValidateVendor проверяет данные поставщика перед сохранением
+func ValidateVendor(vendor *Vendor) error {
+	v := &ValidationError{}
+	v.RequireString("name", vendor.Name, maxNameLength)
+	v.MaxLength("contact_email", vendor.ContactEmail, maxContactLength)
+	if vendor.ContactEmail != "" && !strings.Contains(vendor.ContactEmail, "@") {
+		v.Add("contact_email", validation.CodeInvalid, "ожидается адрес электронной почты")
+	}
+	v.MaxLength("phone", vendor.Phone, maxPhoneLength)
+	return v.ErrOrNil()
+}
+
+// CreateVendor сохраняет поставщика; название поставщика уникально без учёта регистра
+func (s *VendorService) CreateVendor(vendor *Vendor) (*Vendor, error) {
+	if err := ValidateVendor(vendor); err != nil {
+		return nil, err
+	}
+
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		var exists bool
+		if err := tx.QueryRow(
+			"SELECT EXISTS (SELECT 1 FROM vendors WHERE lower(name) = lower($1))", vendor.Name,
+		).Scan(&exists); err != nil {
+			return fmt.Errorf("ошибка при проверке названия поставщика: %v", err)
+		}
+		if exists {
+			return validation.New("name", validation.CodeDuplicate, "поставщик %q уже существует", vendor.Name)
+		}
+
+		if err := tx.QueryRow(
+			"INSERT INTO vendors (name, contact_email, phone) VALUES ($1, NULLIF($2, ''), NULLIF($3, '')) RETURNING id, created_at",
+			vendor.Name, vendor.ContactEmail, vendor.Phone,
+		).Scan(&vendor.ID, &vendor.CreatedAt); err != nil {
+			return fmt.Errorf("ошибка при создании поставщика: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vendor, nil
+}
+
+// GetAllVendors возвращает поставщиков, упорядоченных по названию
+func (s *VendorService) GetAllVendors() ([]Vendor, error) {
+	rows, err := s.db.Query(
+		"SELECT id, name, COALESCE(contact_email, ''), COALESCE(phone, ''), created_at FROM vendors ORDER BY name",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении поставщиков: %v", err)
+	}
+	defer rows.Close()
+
+	vendors := []Vendor{}
+	for rows.Next() {
+		var vendor Vendor
+		if err := rows.Scan(&vendor.ID, &vendor.Name, &vendor.ContactEmail, &vendor.Phone, &vendor.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		vendors = append(vendors, vendor)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при переборе строк: %v", err)
+	}
+
+	return vendors, nil
+}
+
+// GetVendor возвращает поставщика по идентификатору
+func (s *VendorService) GetVendor(id int) (*Vendor, error) {
+	var vendor Vendor
+	err := s.db.QueryRow(
+		"SELECT id, name, COALESCE(contact_email, ''), COALESCE(phone, ''), created_at FROM vendors WHERE id = $1", id,
+	).Scan(&vendor.ID, &vendor.Name, &vendor.ContactEmail, &vendor.Phone, &vendor.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w (id %d)", ErrVendorNotFound, id)
+		}
+		return nil, fmt.Errorf("ошибка при получении поставщика: %v", err)
+	}
+	return &vendor, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"inva/pkg/events"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventWarrantyExpiring тип события о скором окончании гарантии на оборудование
+const EventWarrantyExpiring = "equipment.warranty_expiring"
+
+// DefaultWarrantyNotice срок, за который предупреждают об окончании гарантии, если он не настроен
+const DefaultWarrantyNotice = 30 * 24 * time.Hour
+
+// WarrantyChecker периодически ищет оборудование с истекающей гарантией и публикует о нём события,
+// чтобы успеть отправить его в ремонт, пока оно на гарантии
+type WarrantyChecker struct {
+	service  *EquipmentService
+	bus      *events.Bus
+	interval time.Duration
+	notice   time.Duration
+}
+
+// NewWarrantyChecker создаёт новый экземпляр WarrantyChecker; notice 0 означает DefaultWarrantyNotice
+func NewWarrantyChecker(service *EquipmentService, bus *events.Bus, interval, notice time.Duration) *WarrantyChecker {
+	if notice <= 0 {
+		notice = DefaultWarrantyNotice
+	}
+	return &WarrantyChecker{service: service, bus: bus, interval: interval, notice: notice}
+}
+
+// Run выполняет проверку с заданным интервалом до отмены контекста
+func (c *WarrantyChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.Check(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check публикует событие для каждой единицы оборудования, гарантия которой истекает в пределах notice от now;
+// о каждой дате окончания гарантии предупреждают один раз
+func (c *WarrantyChecker) Check(now time.Time) {
+	items, err := c.service.ClaimWarrantyNotifications(now, c.notice)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при проверке гарантии оборудования")
+		return
+	}
+
+	for _, item := range items {
+		c.bus.Publish(events.Event{
+			Type: EventWarrantyExpiring,
+			At:   now,
+			Payload: map[string]interface{}{
+				"equipment_id":        item.EquipmentID,
+				"asset_tag":           item.AssetTag,
+				"warranty_expires_at": item.WarrantyExpiresAt.Format(dateLayout),
+				"days_left":           item.DaysLeft,
+				"support_contract":    item.SupportContract,
+			},
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package services_test
+
+import (
+	"inva/services"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteBulkRollsBackOnFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Вторая операция ссылается на отсутствующее оборудование, пакет откатывается целиком
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 1, "available", nil)
+	mock.ExpectExec("UPDATE equipment SET status = \\$1, (.+) WHERE id = \\$2").
+		WithArgs("retired", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT assigned_to, status, version FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"assigned_to", "status", "version"}))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	results, err := service.ExecuteBulk([]services.BulkOperation{
+		{Op: services.BulkUpdateStatus, ID: 1, Status: "retired"},
+		{Op: services.BulkMoveLocation, ID: 2, Location: "Warehouse"},
+		{Op: services.BulkDelete, ID: 3},
+	})
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrEquipmentNotFound)
+	assert.Equal(t, services.BulkResultRolledBack, results[0].Result)
+	assert.Equal(t, services.BulkResultFailed, results[1].Result)
+	assert.Equal(t, services.BulkResultSkipped, results[2].Result)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestExecuteBulkRejectsUnknownOperation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Вызываем метод: некорректный пакет не доходит до базы данных
+	results, err := service.ExecuteBulk([]services.BulkOperation{
+		{Op: services.BulkReturn, ID: 1},
+		{Op: "paint", ID: 2},
+	})
+
+	// Проверяем результаты
+	var validationErr *services.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, services.BulkResultSkipped, results[0].Result)
+	assert.Equal(t, services.BulkResultFailed, results[1].Result)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestExecuteBulkMoveDisposed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Утилизированное оборудование не перемещается, пакет откатывается целиком
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 1, services.EquipmentStatusDisposed, nil)
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	results, err := service.ExecuteBulk([]services.BulkOperation{
+		{Op: services.BulkMoveLocation, ID: 1, Location: "Warehouse"},
+	})
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrEquipmentDisposed)
+	assert.Equal(t, services.BulkResultFailed, results[0].Result)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
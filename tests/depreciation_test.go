@@ -0,0 +1,102 @@
+package services_test
+
+import (
+	"inva/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// valuationColumns столбцы выборки оборудования для отчёта об оценке
+var valuationColumns = []string{"id", "model", "category", "department", "currency", "purchase_cost", "purchase_date",
+	"method", "useful_life_months", "salvage_percent"}
+
+func TestDepreciate(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		months      int
+		accumulated float64
+		bookValue   float64
+	}{
+		{"до первого полного месяца", services.DepreciationStraightLine, 0, 0, 1200},
+		{"линейный, треть срока", services.DepreciationStraightLine, 12, 350, 850},
+		{"линейный, срок истёк", services.DepreciationStraightLine, 40, 1050, 150},
+		{"уменьшаемый остаток, треть срока", services.DepreciationDecliningBalance, 12, 595.64, 604.36},
+		{"уменьшаемый остаток, срок истёк", services.DepreciationDecliningBalance, 36, 1050, 150},
+	}
+	for _, tt := range tests {
+		// Стоимость 1200, срок 36 месяцев, ликвидационная стоимость 150
+		accumulated, bookValue := services.Depreciate(tt.method, 1200, 150, 36, tt.months)
+		assert.Equal(t, tt.accumulated, accumulated, tt.name)
+		assert.Equal(t, tt.bookValue, bookValue, tt.name)
+	}
+}
+
+func TestDepreciateSwitchesToStraightLine(t *testing.T) {
+	tests := []struct {
+		months      int
+		accumulated float64
+		bookValue   float64
+	}{
+		// До 18-го месяца удвоенная норма 2/36 от остатка даёт большее списание
+		{18, 771.1, 428.9},
+		// Дальше остаток 428.90 списывается равными долями по 23.83 за оставшиеся 18 месяцев
+		{19, 794.93, 405.07},
+		{35, 1176.17, 23.83},
+		{36, 1200, 0},
+	}
+	for _, tt := range tests {
+		// Стоимость 1200, срок 36 месяцев, без ликвидационной стоимости
+		accumulated, bookValue := services.Depreciate(services.DepreciationDecliningBalance, 1200, 0, 36, tt.months)
+		assert.Equal(t, tt.accumulated, accumulated, tt.months)
+		assert.Equal(t, tt.bookValue, bookValue, tt.months)
+	}
+}
+
+func TestGetValuationReportByDepartment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewDepreciationService(db)
+	asOf := time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+
+	// Метод первого ноутбука задан правилом категории, у второго переопределён; у монитора нет даты покупки.
+	// Отдел определяется по выдаче, открытой на конец дня asOf.
+	mock.ExpectQuery("SELECT (.+) FROM equipment e LEFT JOIN LATERAL \\(\\s*SELECT l.user_id FROM equipment_logs l "+
+		"WHERE l.equipment_id = e.id AND l.issued_at < \\$5 AND \\(l.returned_at IS NULL OR l.returned_at >= \\$5\\) (.+)"+
+		"WHERE \\(e.status NOT IN \\(\\$1, \\$2\\) (.+) d.decided_at >= \\$5\\)\\) AND (.+) ORDER BY e.id").
+		WithArgs(services.HistoryStatusWrittenOff, services.EquipmentStatusDisposed, asOf, services.DisposalCompleted,
+			time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)).
+		WillReturnRows(sqlmock.NewRows(valuationColumns).
+			AddRow(3, "Laptop", "laptop", "Engineering", "USD", 1200.0, time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC), services.DepreciationStraightLine, 36, 12.5).
+			AddRow(4, "Laptop", "laptop", "Engineering", "USD", 1500.0, time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC), services.DepreciationDecliningBalance, 24, 0).
+			AddRow(5, "Monitor", "monitor", "Engineering", "USD", 300.0, nil, services.DepreciationStraightLine, 60, 0))
+
+	// Вызываем метод
+	report, err := service.GetValuationReport(asOf, services.ValuationByDepartment)
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Empty(t, report.Items)
+	assert.Equal(t, []int{5}, report.Unvalued)
+	if assert.Len(t, report.Groups, 1) {
+		// 850 по линейному методу и 1500·(11/12)^6 = 889.94 по уменьшаемому остатку
+		assert.Equal(t, services.ValuationGroup{
+			Key:                     "Engineering",
+			Currency:                "USD",
+			Items:                   2,
+			AcquisitionCost:         2700,
+			AccumulatedDepreciation: 960.06,
+			BookValue:               1739.94,
+		}, report.Groups[0])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
@@ -0,0 +1,308 @@
+package services_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"inva/pkg/certificate"
+	"inva/services"
+	"io"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// disposalColumns столбцы выборки заявки на утилизацию
+var disposalColumns = []string{"id", "equipment_id", "model", "serial_number", "category", "status", "reason", "method",
+	"recipient", "requested_by", "requested_by_name", "data_wipe_method", "data_wiped_by", "data_wiped_by_name", "data_wiped_at",
+	"approved_by", "approved_by_name", "decision_note", "requested_at", "decided_at"}
+
+// expectLockDisposal ожидает блокировку заявки 4 сотрудника 5 на утилизацию оборудования 9
+func expectLockDisposal(mock sqlmock.Sqlmock, wipedAt interface{}) {
+	mock.ExpectQuery("SELECT status, equipment_id, requested_by, data_wiped_at FROM disposals WHERE id = \\$1 FOR UPDATE").
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "equipment_id", "requested_by", "data_wiped_at"}).
+			AddRow(services.DisposalPending, 9, 5, wipedAt))
+}
+
+// expectOpenMaintenance ожидает проверку незавершённого ремонта оборудования
+func expectOpenMaintenance(mock sqlmock.Sqlmock, equipmentID int, open bool) {
+	mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM maintenance_records WHERE equipment_id = \\$1 AND closed_at IS NULL\\)").
+		WithArgs(equipmentID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(open))
+}
+
+func TestApproveDisposal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewDisposalService(db)
+	requestedAt := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	wipedAt := requestedAt.Add(24 * time.Hour)
+	decidedAt := wipedAt.Add(24 * time.Hour)
+
+	// Данные очищены, оборудование возвращено: руководитель утилизирует его
+	mock.ExpectBegin()
+	expectLockDisposal(mock, wipedAt)
+	mock.ExpectQuery("SELECT role FROM employees WHERE id = \\$1").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(services.EmployeeRoleManager))
+	mock.ExpectQuery("SELECT assigned_to FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"assigned_to"}).AddRow(nil))
+	expectOpenMaintenance(mock, 9, false)
+	expectLockEquipment(mock, 9, "broken", nil)
+	mock.ExpectExec("UPDATE equipment SET status = \\$1").
+		WithArgs(services.EquipmentStatusDisposed, 9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE disposals SET status = \\$1, approved_by = \\$2").
+		WithArgs(services.DisposalCompleted, 2, "E-waste contract 2026", 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT (.+) FROM disposals d (.+) WHERE d.id = \\$1").
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows(disposalColumns).
+			AddRow(4, 9, "Laptop", "SN-009", "laptop", services.DisposalCompleted, "Broken motherboard", services.DisposalRecycle,
+				"GreenCycle Ltd", 5, "Alice", "NIST 800-88 purge", 6, "Bob", wipedAt,
+				2, "Carol", "E-waste contract 2026", requestedAt, decidedAt))
+
+	// Вызываем метод
+	disposal, err := service.Approve(4, 2, "E-waste contract 2026")
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, services.DisposalCompleted, disposal.Status)
+	assert.Equal(t, "INV-000009", disposal.AssetTag)
+	assert.Equal(t, "DSP-000004", disposal.CertificateNumber)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestApproveDisposalWithoutDataWipe(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewDisposalService(db)
+
+	// Очистка данных не подтверждена: статус оборудования не меняется
+	mock.ExpectBegin()
+	expectLockDisposal(mock, nil)
+	mock.ExpectQuery("SELECT role FROM employees WHERE id = \\$1").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(services.EmployeeRoleAdmin))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.Approve(4, 2, "")
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrDataWipeRequired)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestApproveDisposalDuringRepair(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewDisposalService(db)
+
+	// Оборудование в ремонте не утилизируется, пока ремонт не закрыт
+	mock.ExpectBegin()
+	expectLockDisposal(mock, time.Date(2026, 10, 13, 9, 0, 0, 0, time.UTC))
+	mock.ExpectQuery("SELECT role FROM employees WHERE id = \\$1").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(services.EmployeeRoleManager))
+	mock.ExpectQuery("SELECT assigned_to FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"assigned_to"}).AddRow(nil))
+	expectOpenMaintenance(mock, 9, true)
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.Approve(4, 2, "")
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrMaintenanceOpen)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestPatchDisposedEquipment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+	model := "Laptop Pro"
+
+	// Утилизированное оборудование изменить нельзя
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows(patchColumns).
+			AddRow(9, "Laptop", "SN-009", services.EquipmentStatusDisposed, nil, nil, "laptop", nil,
+				nil, nil, nil, "USD", nil, nil, 4))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.PatchEquipment(9, 0, services.EquipmentPatch{Model: &model})
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrEquipmentDisposed)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestAssignDisposedEquipment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Утилизированное оборудование не выдаётся ни напрямую, ни через сканер, комплекты или заявки
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 9, services.EquipmentStatusDisposed, nil)
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	err = service.AssignEquipment(9, 5, services.AssignOptions{})
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrEquipmentDisposed)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestExecuteBulkOnDisposedEquipment(t *testing.T) {
+	// Пакетные операции не возвращают утилизированное оборудование в оборот и не удаляют его
+	operations := []services.BulkOperation{
+		{Op: services.BulkUpdateStatus, ID: 9, Status: "available"},
+		{Op: services.BulkAssign, ID: 9, UserID: 5},
+		{Op: services.BulkDelete, ID: 9},
+	}
+	for _, operation := range operations {
+		t.Run(operation.Op, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Ошибка при создании mock DB: %v", err)
+			}
+			defer db.Close()
+
+			service := services.NewEquipmentService(db)
+			mock.ExpectBegin()
+			expectLockEquipment(mock, 9, services.EquipmentStatusDisposed, nil)
+			mock.ExpectRollback()
+
+			// Вызываем метод
+			results, err := service.ExecuteBulk([]services.BulkOperation{operation})
+
+			// Проверяем результаты
+			assert.ErrorIs(t, err, services.ErrEquipmentDisposed)
+			assert.Equal(t, services.BulkResultFailed, results[0].Result)
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("Ожидания не были удовлетворены: %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdateDisposedEquipment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// PUT не может вернуть утилизированное оборудование в оборот
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 9, services.EquipmentStatusDisposed, nil)
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	err = service.UpdateEquipment(9, "Laptop", "SN-009", "available")
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrEquipmentDisposed)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestWriteDisposalPDF(t *testing.T) {
+	var buf bytes.Buffer
+	err := certificate.WriteDisposalPDF(&buf, certificate.Disposal{
+		Number:         services.DisposalCertificateNumber(4),
+		AssetTag:       services.AssetTag(9),
+		Model:          "Ноутбук Laptop",
+		SerialNumber:   "SN-009",
+		Reason:         "Сгорела материнская плата",
+		Method:         services.DisposalRecycle,
+		DataWipeMethod: "NIST 800-88 purge",
+		DataWipedBy:    "Иван Петров",
+		DataWipedAt:    time.Date(2026, 10, 13, 9, 0, 0, 0, time.UTC),
+		DisposedAt:     time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC),
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF")))
+
+	// Кириллица выводится шрифтом UTF-8 и записывается в содержимое страницы в UTF-16BE
+	content := pdfStreams(t, buf.Bytes())
+	for _, text := range []string{"Ноутбук Laptop", "Сгорела материнская плата", "Иван Петров"} {
+		assert.True(t, bytes.Contains(content, utf16be(text)), text)
+	}
+}
+
+// pdfStreams распаковывает и склеивает все сжатые потоки PDF-документа
+func pdfStreams(t *testing.T, pdf []byte) []byte {
+	var content []byte
+	for {
+		start := bytes.Index(pdf, []byte("stream\n"))
+		if start < 0 {
+			return content
+		}
+		pdf = pdf[start+len("stream\n"):]
+		end := bytes.Index(pdf, []byte("endstream"))
+		if end < 0 {
+			t.Fatalf("Поток PDF не закрыт")
+		}
+		if r, err := zlib.NewReader(bytes.NewReader(pdf[:end])); err == nil {
+			data, _ := io.ReadAll(r)
+			content = append(content, data...)
+		}
+		pdf = pdf[end+len("endstream"):]
+	}
+}
+
+// utf16be кодирует строку в UTF-16BE, как текст шрифтов UTF-8 в содержимом PDF
+func utf16be(s string) []byte {
+	var out []byte
+	for _, unit := range utf16.Encode([]rune(s)) {
+		out = append(out, byte(unit>>8), byte(unit))
+	}
+	return out
+}
@@ -20,9 +20,11 @@ func TestCreateEmployee(t *testing.T) {
 
 	// Определяем ожидаемые данные
 	newEmployee := &services.Employee{Name: "John Doe"}
+	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO employees").
-		WithArgs(newEmployee.Name).
+		WithArgs(newEmployee.Name, "").
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
 
 	// Вызываем метод
 	result, err := service.CreateEmployee(newEmployee)
@@ -46,10 +48,11 @@ func TestGetEmployeeByID(t *testing.T) {
 	service := services.NewEmployeeService(db)
 
 	// Определяем ожидаемые данные
-	employee := &services.Employee{ID: 1, Name: "John Doe"}
-	mock.ExpectQuery("SELECT id, name FROM employees WHERE id = ?").
+	employee := &services.Employee{ID: 1, Name: "John Doe", Email: "john@example.com", Status: services.EmployeeStatusActive, Department: "engineering", Role: services.EmployeeRoleUser, Version: 3}
+	mock.ExpectQuery("SELECT id, name, (.+), status, (.+), role, version FROM employees WHERE id = ?").
 		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(employee.ID, employee.Name))
+		WillReturnRows(employeeRows().
+			AddRow(employee.ID, employee.Name, employee.Email, employee.Status, employee.Department, "", employee.Role, employee.Version))
 
 	// Вызываем метод
 	result, err := service.GetEmployeeByID(1)
@@ -72,9 +75,11 @@ func TestUpdateEmployee(t *testing.T) {
 	service := services.NewEmployeeService(db)
 
 	// Определяем ожидаемые данные
-	mock.ExpectExec("UPDATE employees SET name = \\$1 WHERE id = \\$2").
-		WithArgs("John Smith", 1).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE employees SET name = \\$1, email = NULLIF\\(\\$2, ''\\), version = version \\+ 1, updated_at = NOW\\(\\) WHERE id = \\$3 RETURNING version").
+		WithArgs("John Smith", "", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectCommit()
 
 	// Вызываем метод
 	err = service.UpdateEmployee(1, "John Smith")
@@ -121,14 +126,14 @@ func TestGetAllEmployees(t *testing.T) {
 
 	// Определяем ожидаемые данные
 	employeeList := []services.Employee{
-		{ID: 1, Name: "John Doe"},
-		{ID: 2, Name: "Jane Doe"},
+		{ID: 1, Name: "John Doe", Status: services.EmployeeStatusActive},
+		{ID: 2, Name: "Jane Doe", Status: services.EmployeeStatusInactive},
 	}
-	rows := sqlmock.NewRows([]string{"id", "name"})
+	rows := sqlmock.NewRows([]string{"id", "name", "status"})
 	for _, emp := range employeeList {
-		rows.AddRow(emp.ID, emp.Name)
+		rows.AddRow(emp.ID, emp.Name, emp.Status)
 	}
-	mock.ExpectQuery("SELECT id, name FROM employees").WillReturnRows(rows)
+	mock.ExpectQuery("SELECT id, name, status FROM employees").WillReturnRows(rows)
 
 	// Вызываем метод
 	result, err := service.GetAllEmployees()
@@ -140,3 +145,153 @@ func TestGetAllEmployees(t *testing.T) {
 		t.Fatalf("Ожидания не были удовлетворены: %v", err)
 	}
 }
+
+func TestUpdateEmployeeVersionMismatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEmployeeService(db)
+
+	// Версия изменилась после чтения, поэтому обновление не затрагивает строк
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE employees SET name = \\$1, (.+) WHERE id = \\$3 AND version = \\$4 RETURNING version").
+		WithArgs("John Smith", "", 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}))
+	mock.ExpectQuery("SELECT version FROM employees WHERE id = \\$1").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.UpdateEmployeeIfMatch(1, 2, "John Smith", "")
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrVersionMismatch)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestPatchEmployeeVersionMismatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEmployeeService(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, name, (.+), role, version FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(1).
+		WillReturnRows(employeeRows().
+			AddRow(1, "John Doe", "", services.EmployeeStatusActive, "", "", services.EmployeeRoleUser, 4))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	name := "John Smith"
+	_, err = service.PatchEmployee(1, 3, services.EmployeePatch{Name: &name})
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrVersionMismatch)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+// employeeRows возвращает колонки полной записи сотрудника
+func employeeRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "name", "email", "status", "department", "position", "role", "version"})
+}
+
+func TestCreateEmployeeInvalidEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEmployeeService(db)
+
+	// Адрес без @ отклоняется до обращения к базе данных
+	_, err = service.CreateEmployee(&services.Employee{Name: "John Doe", Email: "john.example.com"})
+
+	// Проверяем результаты
+	var validationErr *services.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "email", validationErr.Fields[0].Field)
+		assert.Equal(t, "invalid", validationErr.Fields[0].Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestCreateEmployeeDuplicateEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEmployeeService(db)
+
+	// Адрес уже указан у другого сотрудника в другом регистре
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM employees WHERE lower\\(email\\) = lower\\(\\$1\\) AND id <> \\$2\\)").
+		WithArgs("John@Example.com", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.CreateEmployee(&services.Employee{Name: "John Doe", Email: "John@Example.com"})
+
+	// Проверяем результаты
+	var validationErr *services.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "email", validationErr.Fields[0].Field)
+		assert.Equal(t, "duplicate", validationErr.Fields[0].Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestPatchEmployeeEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEmployeeService(db)
+
+	// Новый адрес проверяется на уникальность без учёта самого сотрудника
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, name, (.+), role, version FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(1).
+		WillReturnRows(employeeRows().
+			AddRow(1, "John Doe", "", services.EmployeeStatusActive, "", "", services.EmployeeRoleUser, 4))
+	mock.ExpectQuery("SELECT EXISTS (.+) FROM employees WHERE lower\\(email\\) = lower\\(\\$1\\) AND id <> \\$2").
+		WithArgs("john@example.com", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery("UPDATE employees SET name = \\$1, email = NULLIF\\(\\$2, ''\\), (.+) WHERE id = \\$6 RETURNING version").
+		WithArgs("John Doe", "john@example.com", "", "", services.EmployeeRoleUser, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(5))
+	mock.ExpectCommit()
+
+	// Вызываем метод
+	email := "john@example.com"
+	employee, err := service.PatchEmployee(1, 4, services.EmployeePatch{Email: &email})
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, "john@example.com", employee.Email)
+	assert.Equal(t, 5, employee.Version)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
@@ -1,11 +1,17 @@
 package services_test
 
 import (
+	"inva/handlers"
 	"inva/models"
 	"inva/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -48,11 +54,11 @@ func TestGetEquipmentByID(t *testing.T) {
 	service := services.NewEquipmentService(db)
 
 	// Ожидаемые данные
-	rows := sqlmock.NewRows([]string{"id", "model", "serial_number", "status", "assigned_to"}).
-		AddRow(1, "Laptop", "1234", "available", nil)
+	rows := sqlmock.NewRows([]string{"id", "model", "serial_number", "status", "assigned_to", "version", "updated_at"}).
+		AddRow(1, "Laptop", "1234", "available", nil, 2, "2026-10-01T10:00:00Z")
 
 	// Ожидаемый запрос
-	mock.ExpectQuery("^SELECT id, model, serial_number, status, assigned_to FROM equipment WHERE id = \\$1$").
+	mock.ExpectQuery("^SELECT id, model, serial_number, status, assigned_to, version, updated_at FROM equipment WHERE id = \\$1$").
 		WithArgs(1).
 		WillReturnRows(rows)
 
@@ -66,6 +72,8 @@ func TestGetEquipmentByID(t *testing.T) {
 		SerialNumber: "1234",
 		Status:       "available",
 		AssignedTo:   nil,
+		Version:      2,
+		UpdatedAt:    "2026-10-01T10:00:00Z",
 	}
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
@@ -85,9 +93,12 @@ func TestUpdateEquipment(t *testing.T) {
 	service := services.NewEquipmentService(db)
 
 	// Определяем ожидаемые данные
-	mock.ExpectExec(`^UPDATE equipment SET model = \$1, serial_number = \$2, status = \$3 WHERE id = \$4$`).
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 1, "available", nil)
+	mock.ExpectQuery(`^UPDATE equipment SET model = \$1, serial_number = \$2, status = \$3, version = version \+ 1, updated_at = NOW\(\) WHERE id = \$4 RETURNING version$`).
 		WithArgs("Laptop", "1234", "available", 1).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectCommit()
 
 	// Вызываем метод
 	err = service.UpdateEquipment(1, "Laptop", "1234", "available")
@@ -109,9 +120,12 @@ func TestDeleteEquipment(t *testing.T) {
 	service := services.NewEquipmentService(db)
 
 	// Определяем ожидаемые данные
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 1, "available", nil)
 	mock.ExpectExec("DELETE FROM equipment WHERE id = ?").
 		WithArgs(1).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
 	// Вызываем метод
 	err = service.DeleteEquipment(1)
@@ -153,3 +167,408 @@ func TestGetAllEquipment(t *testing.T) {
 		t.Fatalf("Ожидания не были удовлетворены: %v", err)
 	}
 }
+
+func TestTransferEquipment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Закрытие выдачи прежнему владельцу и открытие новой выполняются в одной транзакции
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT assigned_to FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"assigned_to"}).AddRow(5))
+	mock.ExpectQuery("SELECT assigned_to FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"assigned_to"}).AddRow(5))
+	mock.ExpectExec("UPDATE equipment SET assigned_to = NULL, (.+) WHERE id = \\$1").
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE equipment_logs SET returned_at = NOW\\(\\)").
+		WithArgs(services.HistoryStatusTransferred, "team change", 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE return_tasks SET status = \\$1, resolved_at = NOW\\(\\)").
+		WithArgs(services.HistoryStatusTransferred, 7, services.ReturnTaskStatusOpen).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLockEquipment(mock, 7, "available", nil)
+	mock.ExpectQuery("SELECT status FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(services.EmployeeStatusActive))
+	expectNoPolicies(mock)
+	mock.ExpectExec("UPDATE equipment SET assigned_to = \\$1, (.+) WHERE id = \\$2").
+		WithArgs(9, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO equipment_logs").
+		WithArgs(7, 9, services.HistoryStatusIssued, nil, "team change").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Вызываем метод
+	result, err := service.TransferEquipment(7, 9, "team change", nil)
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, 5, result.FromUserID)
+	assert.Equal(t, 9, result.ToUserID)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+// expectLockEquipment ожидает блокировку строки оборудования с указанными статусом и владельцем
+func expectLockEquipment(mock sqlmock.Sqlmock, id int, status string, assignedTo interface{}) {
+	mock.ExpectQuery("SELECT assigned_to, status, version FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"assigned_to", "status", "version"}).AddRow(assignedTo, status, 1))
+}
+
+// patchColumns столбцы выборки оборудования перед частичным обновлением
+var patchColumns = []string{"id", "model", "serial_number", "status", "assigned_to", "location", "category", "purchase_cost",
+	"purchase_date", "warranty_expires_at", "support_contract", "currency", "depreciation_method", "useful_life_months", "version"}
+
+func TestPatchEquipment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	purchaseDate := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	warrantyExpiresAt := time.Date(2028, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	// Серийный номер не передан и сохраняется, пустое место размещения очищается
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(patchColumns).
+			AddRow(1, "Laptop", "1234", "available", nil, "Room 101", "laptop", nil, nil, nil, nil, "USD", nil, nil, 2))
+	mock.ExpectQuery("UPDATE equipment SET model = \\$1, serial_number = \\$2, status = \\$3, location = NULLIF\\(\\$4, ''\\), category = NULLIF\\(\\$5, ''\\), purchase_cost = \\$6, purchase_date = \\$7, warranty_expires_at = \\$8, support_contract = NULLIF\\(\\$9, ''\\), currency = \\$10, depreciation_method = NULLIF\\(\\$11, ''\\), useful_life_months = \\$12, (.+) WHERE id = \\$13 RETURNING version, updated_at").
+		WithArgs("Laptop", "1234", "in repair", "", "laptop", 1499.5, purchaseDate, warrantyExpiresAt, "SC-2026-114", "EUR", services.DepreciationStraightLine, 36, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "updated_at"}).AddRow(3, "2026-10-19T10:00:00Z"))
+	mock.ExpectCommit()
+
+	// Вызываем метод
+	status, location, cost := "in repair", "", "1499.50"
+	purchased, warranty, contract := "2026-03-02", "2028-03-02", "SC-2026-114"
+	currency, method, life := "EUR", services.DepreciationStraightLine, "36"
+	equipment, err := service.PatchEquipment(1, 2, services.EquipmentPatch{
+		Status:             &status,
+		Location:           &location,
+		PurchaseCost:       &cost,
+		PurchaseDate:       &purchased,
+		WarrantyExpiresAt:  &warranty,
+		SupportContract:    &contract,
+		Currency:           &currency,
+		DepreciationMethod: &method,
+		UsefulLifeMonths:   &life,
+	})
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, "1234", equipment.SerialNumber)
+	assert.Equal(t, "in repair", equipment.Status)
+	assert.Equal(t, 3, equipment.Version)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestPatchEquipmentValidation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Очистка обязательного поля отклоняется, и транзакция откатывается
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(patchColumns).
+			AddRow(1, "Laptop", "1234", "available", nil, nil, nil, nil, nil, nil, nil, "USD", nil, nil, 2))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	model := ""
+	_, err = service.PatchEquipment(1, 0, services.EquipmentPatch{Model: &model})
+
+	// Проверяем результаты
+	var validationErr *services.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "model", validationErr.Fields[0].Field)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestGetEquipmentDetails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Владелец подставляется из таблицы сотрудников, поставщик из заказа, история ограничена последними записями
+	mock.ExpectQuery("SELECT (.+) FROM equipment e (.+) LEFT JOIN employees emp ON emp.id = e.assigned_to (.+) LEFT JOIN vendors v ON v.id = po.vendor_id WHERE e.id = \\$1").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "model", "serial_number", "status", "assigned_to", "location", "category", "purchase_cost",
+			"purchase_date", "warranty_expires_at", "support_contract", "currency", "depreciation_method", "useful_life_months", "purchase_order_line_id", "version", "due_at", "name",
+			"id", "number", "id", "name"}).
+			AddRow(7, "Laptop", "1234", "in use", 5, "Room 101", "laptop", 1200.0, nil, nil, "", "USD", "", nil, 12, 3, nil, "John Doe",
+				4, "PO-2026-014", 2, "Acme Supplies"))
+	mock.ExpectQuery("SELECT (.+) FROM equipment_logs l (.+) ORDER BY l.issued_at DESC, l.id DESC LIMIT \\$2").
+		WithArgs(7, services.DetailsHistoryLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "equipment_id", "user_id", "name", "issued_at", "due_at", "returned_at", "status", "note"}).
+			AddRow(11, 7, 5, "John Doe", time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC), nil, nil, "issued", ""))
+
+	// Вызываем метод
+	details, err := service.GetEquipmentDetails(7)
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, "INV-000007", details.AssetTag)
+	assert.Equal(t, &services.Employee{ID: 5, Name: "John Doe"}, details.Assignee)
+	assert.Equal(t, "Room 101", details.Location)
+	assert.Equal(t, &services.EquipmentSource{PurchaseOrderID: 4, PurchaseOrderNumber: "PO-2026-014", VendorID: 2, VendorName: "Acme Supplies"}, details.Source)
+	assert.Len(t, details.RecentHistory, 1)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+// assertStatusNotAllowed проверяет, что изменение отклонено из-за статуса процесса
+func assertStatusNotAllowed(t *testing.T, err error) {
+	var validationErr *services.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "status", validationErr.Fields[0].Field)
+		assert.Equal(t, "not_allowed", validationErr.Fields[0].Code)
+	}
+}
+
+func TestCreateEquipmentWithWorkflowStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Новое оборудование не может сразу быть утилизированным или списанным
+	for _, status := range []string{services.EquipmentStatusDisposed, services.HistoryStatusWrittenOff} {
+		_, err := service.CreateEquipment("Laptop", "1234", status)
+		assertStatusNotAllowed(t, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestUpdateEquipmentWorkflowStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		status  string
+	}{
+		{"утилизация в обход заявки", "available", services.EquipmentStatusDisposed},
+		{"списание в обход увольнения", "available", services.HistoryStatusWrittenOff},
+		{"возврат списанного в оборот", services.HistoryStatusWrittenOff, "available"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Ошибка при создании mock DB: %v", err)
+			}
+			defer db.Close()
+
+			service := services.NewEquipmentService(db)
+			mock.ExpectBegin()
+			expectLockEquipment(mock, 1, tt.current, nil)
+			mock.ExpectRollback()
+
+			// Вызываем метод
+			err = service.UpdateEquipment(1, "Laptop", "1234", tt.status)
+
+			// Проверяем результаты
+			assertStatusNotAllowed(t, err)
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("Ожидания не были удовлетворены: %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdateWrittenOffEquipmentKeepingStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Описание списанного оборудования можно исправить, если статус не меняется
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 1, services.HistoryStatusWrittenOff, nil)
+	mock.ExpectQuery("UPDATE equipment SET model = \\$1").
+		WithArgs("Laptop Pro", "1234", services.HistoryStatusWrittenOff, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectCommit()
+
+	// Вызываем метод
+	err = service.UpdateEquipment(1, "Laptop Pro", "1234", services.HistoryStatusWrittenOff)
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestPatchEquipmentWorkflowStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+	status := services.EquipmentStatusDisposed
+
+	// PATCH не утилизирует оборудование в обход заявки, очистки данных и одобрения
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(patchColumns).
+			AddRow(1, "Laptop", "1234", "available", nil, nil, nil, nil, nil, nil, nil, "USD", nil, nil, 2))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.PatchEquipment(1, 0, services.EquipmentPatch{Status: &status})
+
+	// Проверяем результаты
+	assertStatusNotAllowed(t, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestExecuteBulkWorkflowStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Пакетная смена статуса не списывает оборудование
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 1, "available", nil)
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	results, err := service.ExecuteBulk([]services.BulkOperation{
+		{Op: services.BulkUpdateStatus, ID: 1, Status: services.HistoryStatusWrittenOff},
+	})
+
+	// Проверяем результаты
+	assertStatusNotAllowed(t, err)
+	assert.Equal(t, services.BulkResultFailed, results[0].Result)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestMoveEquipment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Оборудование блокируется перед перемещением
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 1, "available", nil)
+	mock.ExpectExec("UPDATE equipment SET location = \\$1, (.+) WHERE id = \\$2").
+		WithArgs("Warehouse", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Вызываем метод
+	err = service.MoveEquipment(1, "Warehouse")
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestMoveDisposedEquipment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 1, services.EquipmentStatusDisposed, nil)
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	err = service.MoveEquipment(1, "Warehouse")
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrEquipmentDisposed)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestUpdateEquipmentHandlerReturnsETag(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	// Условное обновление проходит по версии 1 и возвращает новую версию
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 1, "available", nil)
+	mock.ExpectQuery("UPDATE equipment SET model = \\$1, (.+) WHERE id = \\$4 RETURNING version").
+		WithArgs("Laptop Pro", "1234", "available", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectCommit()
+
+	// Вызываем метод
+	handler := handlers.NewEquipmentHandler(services.NewEquipmentService(db))
+	request := httptest.NewRequest(http.MethodPut, "/equipment/1",
+		strings.NewReader(`{"model": "Laptop Pro", "serial_number": "1234", "status": "available"}`))
+	request.Header.Set("If-Match", `"1"`)
+	request = mux.SetURLVars(request, map[string]string{"id": "1"})
+	recorder := httptest.NewRecorder()
+	handler.UpdateEquipmentHandler(recorder, request)
+
+	// Проверяем результаты
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, `"2"`, recorder.Header().Get("ETag"))
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package services_test
+
+import (
+	"bytes"
+	"errors"
+	"inva/handlers"
+	"inva/pkg/export"
+	"inva/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportEquipmentCSV(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewExportService(db)
+
+	// Фильтр применяется к таблице оборудования с псевдонимом e
+	rows := sqlmock.NewRows([]string{"id", "model", "serial_number", "status", "assigned_to", "name"}).
+		AddRow(1, "Laptop", "1234", "in use", 5, "John Doe").
+		AddRow(2, "Monitor, 27\"", "5678", "in use", 5, "John Doe")
+	mock.ExpectQuery(`FROM equipment e LEFT JOIN employees emp ON emp.id = e.assigned_to WHERE e.assigned_to = \$1 ORDER BY e.id`).
+		WithArgs(5).
+		WillReturnRows(rows)
+
+	var buf bytes.Buffer
+	writer, err := export.NewWriter(&buf, export.CSV)
+	assert.NoError(t, err)
+
+	// Вызываем метод
+	userID := 5
+	err = service.ExportEquipment(services.EquipmentFilter{AssignedTo: &userID}, writer)
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, "id,asset_tag,model,serial_number,status,assigned_to,assignee_name\n"+
+		"1,INV-000001,Laptop,1234,in use,5,John Doe\n"+
+		"2,INV-000002,\"Monitor, 27\"\"\",5678,in use,5,John Doe\n", buf.String())
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestExportEmployeesNDJSON(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewExportService(db)
+
+	mock.ExpectQuery("SELECT id, name FROM employees ORDER BY id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "John Doe"))
+
+	var buf bytes.Buffer
+	writer, err := export.NewWriter(&buf, export.NDJSON)
+	assert.NoError(t, err)
+
+	// Вызываем метод
+	err = service.ExportEmployees(writer)
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id": 1, "badge": "EMP-000001", "name": "John Doe"}`, buf.String())
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := export.NewWriter(&buf, export.XLSX)
+	assert.NoError(t, err)
+
+	assert.NoError(t, writer.WriteHeader([]string{"id", "assigned_to"}))
+	assert.NoError(t, writer.WriteRow([]interface{}{1, (*int)(nil)}))
+	assert.NoError(t, writer.Close())
+
+	// Книга XLSX — это ZIP-архив
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("PK")))
+}
+
+func TestExportHandlerQueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	handler := handlers.NewExportHandler(services.NewExportService(db))
+
+	// Ошибка запроса до первой строки возвращается статусом 500, а не пустым файлом
+	mock.ExpectQuery("FROM employees").WillReturnError(errors.New("connection reset"))
+
+	// Вызываем метод
+	recorder := httptest.NewRecorder()
+	handler.ExportEmployeesHandler(recorder, httptest.NewRequest(http.MethodGet, "/export/employees?format=csv", nil))
+
+	// Проверяем результаты
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Content-Disposition"))
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
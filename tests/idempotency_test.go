@@ -0,0 +1,163 @@
+package services_test
+
+import (
+	"database/sql/driver"
+	"inva/handlers"
+	"inva/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyKeyReused(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewIdempotencyService(db)
+
+	// Ключ уже занят запросом с другим телом
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE key = \\$1 AND created_at < \\$2").
+		WithArgs("key-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO idempotency_keys").
+		WithArgs("key-1", "hash-2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT request_hash, status_code, response_headers, response_body FROM idempotency_keys WHERE key = \\$1").
+		WithArgs("key-1").
+		WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "response_headers", "response_body"}).
+			AddRow("hash-1", 201, []byte(`{}`), []byte(`{"id": 1}`)))
+
+	// Вызываем метод
+	_, err = service.Begin("key-1", "hash-2")
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrIdempotencyKeyReused)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	calls := 0
+	hashArg := &capturedArg{}
+	handler := handlers.Idempotency(services.NewIdempotencyService(db), "/api/v1")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 7}`))
+	}))
+
+	// Первый запрос выполняется, и его ответ сохраняется
+	mock.ExpectExec("DELETE FROM idempotency_keys").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO idempotency_keys").
+		WithArgs("key-1", hashArg).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE idempotency_keys SET status_code = \\$2, response_headers = \\$3, response_body = \\$4").
+		WithArgs("key-1", http.StatusCreated, []byte(`{"Content-Type":"application/json"}`), []byte(`{"id": 7}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	request := httptest.NewRequest(http.MethodPost, "/equipment", strings.NewReader(`{"model": "Laptop"}`))
+	request.Header.Set("Idempotency-Key", "key-1")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, 1, calls)
+
+	// Повтор с тем же ключом через путь с версией получает сохранённый ответ без повторного выполнения
+	mock.ExpectExec("DELETE FROM idempotency_keys").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO idempotency_keys").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT request_hash").
+		WithArgs("key-1").
+		WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "response_headers", "response_body"}).
+			AddRow(hashArg.value, 201, []byte(`{"Content-Type":"application/json"}`), []byte(`{"id": 7}`)))
+
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/equipment", strings.NewReader(`{"model": "Laptop"}`))
+	request.Header.Set("Idempotency-Key", "key-1")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, `{"id": 7}`, recorder.Body.String())
+	assert.Equal(t, "true", recorder.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, calls)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestIdempotencyMiddlewareReleasesKeyOnPanic(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	handler := handlers.Idempotency(services.NewIdempotencyService(db), "/api/v1")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("unexpected state")
+	}))
+
+	// Ключ запроса, завершившегося паникой, освобождается, чтобы повтор мог выполниться
+	mock.ExpectExec("DELETE FROM idempotency_keys").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO idempotency_keys").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE key = \\$1 AND completed_at IS NULL").
+		WithArgs("key-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/equipment", strings.NewReader(`{"model": "Laptop"}`))
+	request.Header.Set("Idempotency-Key", "key-1")
+
+	// Паника передаётся дальше серверу
+	assert.Panics(t, func() { handler.ServeHTTP(httptest.NewRecorder(), request) })
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestIdempotencyMiddlewareLimitsJSONBody(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	handler := handlers.Idempotency(services.NewIdempotencyService(db), "/api/v1")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("обработчик не должен вызываться")
+	}))
+
+	// Тело JSON-запроса больше 1 МБ отклоняется до обращения к базе
+	body := strings.Repeat("a", 2<<20)
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/equipment", strings.NewReader(body))
+	request.Header.Set("Idempotency-Key", "key-1")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+// capturedArg запоминает значение аргумента запроса, чтобы вернуть его в следующем ожидании
+type capturedArg struct {
+	value string
+}
+
+// Match реализует sqlmock.Argument
+func (a *capturedArg) Match(v driver.Value) bool {
+	a.value, _ = v.(string)
+	return a.value != ""
+}
@@ -0,0 +1,145 @@
+package services_test
+
+import (
+	"inva/services"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportEquipmentDryRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewImportService(db)
+
+	// Колонки файла сопоставляются с полями, пустой статус заменяется на available
+	csv := "Inventory Model,SN,Status\n" +
+		"Laptop,A1,\n" +
+		",A2,in use\n" +
+		"Monitor,A1,available\n"
+	mapping := map[string]string{"Inventory Model": "model", "SN": "serial_number"}
+
+	// Вызываем метод
+	result, err := service.ImportEquipment(strings.NewReader(csv), mapping, true)
+
+	// Проверяем результаты: в пробном запуске база данных не затрагивается
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 0, result.Imported)
+	assert.Equal(t, []services.RowError{
+		{Row: 3, Field: "model", Code: "required", Message: "обязательное поле"},
+		{Row: 4, Field: "serial_number", Code: "duplicate", Message: "серийный номер повторяет строку 2"},
+	}, result.Errors)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestImportEquipmentWorkflowStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewImportService(db)
+
+	// Импорт не создаёт утилизированное оборудование
+	csv := "model,serial_number,status\n" +
+		"Laptop,A1,disposed\n"
+
+	// Вызываем метод
+	result, err := service.ImportEquipment(strings.NewReader(csv), nil, true)
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, 2, result.Errors[0].Row)
+		assert.Equal(t, "status", result.Errors[0].Field)
+		assert.Equal(t, "not_allowed", result.Errors[0].Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestImportEmployeesCommit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewImportService(db)
+
+	// Все строки сохраняются в одной транзакции
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO employees").
+		WithArgs("John Doe", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("INSERT INTO employees").
+		WithArgs("Jane Doe", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectCommit()
+
+	// Вызываем метод
+	result, err := service.ImportEmployees(strings.NewReader("name\nJohn Doe\nJane Doe\n"), nil, false)
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	assert.Empty(t, result.Errors)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestImportEmployeesDuplicateEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewImportService(db)
+
+	// Адреса сравниваются без учёта регистра
+	csv := "name,email\n" +
+		"John Doe,john@example.com\n" +
+		"Jane Doe,jane\n" +
+		"John Smith,John@Example.com\n"
+
+	// Вызываем метод
+	result, err := service.ImportEmployees(strings.NewReader(csv), nil, true)
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, []services.RowError{
+		{Row: 3, Field: "email", Code: "invalid", Message: "ожидается адрес электронной почты"},
+		{Row: 4, Field: "email", Code: "duplicate", Message: "email повторяет строку 2"},
+	}, result.Errors)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestImportUnknownColumn(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewImportService(db)
+
+	_, err = service.ImportEmployees(strings.NewReader("name,phone\nJohn,123\n"), nil, true)
+
+	var validationErr *services.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
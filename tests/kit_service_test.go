@@ -0,0 +1,115 @@
+package services_test
+
+import (
+	"inva/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectKit ожидает поиск комплекта по названию вместе с его позициями
+func expectKit(mock sqlmock.Sqlmock, items ...services.KitItem) {
+	mock.ExpectQuery("SELECT id, name, created_at FROM kits WHERE lower\\(name\\) = lower\\(\\$1\\)").
+		WithArgs("developer").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at"}).
+			AddRow(2, "developer", time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)))
+	rows := sqlmock.NewRows([]string{"category", "model", "quantity"})
+	for _, item := range items {
+		rows.AddRow(item.Category, item.Model, item.Quantity)
+	}
+	mock.ExpectQuery("SELECT (.+) FROM kit_items WHERE kit_id = \\$1 ORDER BY position").
+		WithArgs(2).
+		WillReturnRows(rows)
+	mock.ExpectQuery("SELECT status FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(services.EmployeeStatusActive))
+}
+
+// expectAssign ожидает выдачу единицы оборудования сотруднику 5
+func expectAssign(mock sqlmock.Sqlmock, equipmentID int) {
+	expectLockEquipment(mock, equipmentID, "available", nil)
+	mock.ExpectQuery("SELECT status FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(services.EmployeeStatusActive))
+	expectNoPolicies(mock)
+	mock.ExpectExec("UPDATE equipment SET assigned_to = \\$1, (.+) WHERE id = \\$2").
+		WithArgs(5, equipmentID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO equipment_logs").
+		WithArgs(equipmentID, 5, services.HistoryStatusIssued, nil, "комплект developer").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func TestAssignKit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewKitService(db)
+
+	// Два монитора подбираются из свободного оборудования и выдаются в одной транзакции
+	mock.ExpectBegin()
+	expectKit(mock, services.KitItem{Category: "monitor", Quantity: 2})
+	mock.ExpectQuery("SELECT (.+) FROM equipment WHERE assigned_to IS NULL AND status = \\$1 (.+) FOR UPDATE SKIP LOCKED").
+		WithArgs(services.KitAvailableStatus, "monitor", "", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "model", "serial_number", "category"}).
+			AddRow(11, "Dell U2720Q", "SN-11", "monitor").
+			AddRow(12, "Dell U2720Q", "SN-12", "monitor"))
+	expectAssign(mock, 11)
+	expectAssign(mock, 12)
+	mock.ExpectCommit()
+
+	// Вызываем метод
+	assignment, err := service.AssignKit(5, "developer", services.AssignOptions{})
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, "developer", assignment.Kit)
+	assert.Len(t, assignment.Items, 2)
+	assert.Equal(t, "INV-000011", assignment.Items[0].AssetTag)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestAssignKitShortage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewKitService(db)
+
+	// Ноутбуков не хватает: выданный монитор откатывается вместе с транзакцией
+	mock.ExpectBegin()
+	expectKit(mock,
+		services.KitItem{Category: "monitor", Quantity: 1},
+		services.KitItem{Category: "laptop", Model: "ThinkPad X1", Quantity: 1},
+	)
+	mock.ExpectQuery("SELECT (.+) FROM equipment WHERE assigned_to IS NULL").
+		WithArgs(services.KitAvailableStatus, "monitor", "", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "model", "serial_number", "category"}).
+			AddRow(11, "Dell U2720Q", "SN-11", "monitor"))
+	expectAssign(mock, 11)
+	mock.ExpectQuery("SELECT (.+) FROM equipment WHERE assigned_to IS NULL").
+		WithArgs(services.KitAvailableStatus, "laptop", "ThinkPad X1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "model", "serial_number", "category"}))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.AssignKit(5, "developer", services.AssignOptions{})
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrKitShortage)
+	var shortageErr *services.KitShortageError
+	assert.ErrorAs(t, err, &shortageErr)
+	assert.Equal(t, []services.KitShortage{{Category: "laptop", Model: "ThinkPad X1", Requested: 1, Available: 0}}, shortageErr.Shortages)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package services_test
+
+import (
+	"bytes"
+	"image/png"
+	"inva/handlers"
+	"inva/pkg/labels"
+	"inva/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssetTag(t *testing.T) {
+	tag := services.AssetTag(42)
+	assert.Equal(t, "INV-000042", tag)
+
+	id, ok := services.ParseAssetTag(" inv-000042 ")
+	assert.True(t, ok)
+	assert.Equal(t, 42, id)
+
+	_, ok = services.ParseAssetTag("SN-42")
+	assert.False(t, ok)
+}
+
+func TestRenderLabels(t *testing.T) {
+	for _, symbology := range []labels.Symbology{labels.QR, labels.Code128} {
+		code, err := labels.Encode("INV-000001", symbology)
+		assert.NoError(t, err)
+
+		// PNG должен корректно декодироваться
+		var pngBuf bytes.Buffer
+		assert.NoError(t, labels.WritePNG(&pngBuf, code, 2))
+		_, err = png.Decode(&pngBuf)
+		assert.NoError(t, err)
+
+		var svgBuf bytes.Buffer
+		assert.NoError(t, labels.WriteSVG(&svgBuf, code, 2))
+		assert.True(t, strings.HasPrefix(svgBuf.String(), "<svg"))
+		assert.Contains(t, svgBuf.String(), "<rect x=")
+	}
+
+	var pdfBuf bytes.Buffer
+	err := labels.WriteSheetPDF(&pdfBuf, []labels.Label{
+		{Tag: "INV-000001", Title: "Laptop", Subtitle: "1234"},
+		{Tag: "INV-000002", Title: "Monitor", Subtitle: "5678"},
+	})
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdfBuf.Bytes(), []byte("%PDF")))
+}
+
+func TestWriteSheetPDFCyrillic(t *testing.T) {
+	var buf bytes.Buffer
+	err := labels.WriteSheetPDF(&buf, []labels.Label{
+		{Tag: "INV-000003", Title: "Монитор", Subtitle: "СН-15"},
+	})
+
+	assert.NoError(t, err)
+
+	// Подписи выводятся шрифтом UTF-8 и записываются в содержимое страницы в UTF-16BE
+	content := pdfStreams(t, buf.Bytes())
+	for _, text := range []string{"INV-000003", "Монитор", "СН-15"} {
+		assert.True(t, bytes.Contains(content, utf16be(text)), text)
+	}
+}
+
+func TestFindEquipment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Ожидаемый запрос с условиями фильтра
+	rows := sqlmock.NewRows([]string{"id", "model", "serial_number", "status", "assigned_to"}).
+		AddRow(2, "Laptop", "1234", "available", nil)
+	mock.ExpectQuery(`^SELECT id, model, serial_number, status, assigned_to FROM equipment WHERE id IN \(\$1, \$2\) AND status = \$3 AND assigned_to IS NULL ORDER BY id$`).
+		WithArgs(2, 3, "available").
+		WillReturnRows(rows)
+
+	// Вызываем метод
+	result, err := service.FindEquipment(services.EquipmentFilter{IDs: []int{2, 3}, Status: "available", Unassigned: true})
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "Laptop", result[0].Model)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestLabelSheetTooManyItems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	// Фильтр по статусу отбирает больше допустимого: запрос ограничен одной лишней строкой
+	rows := sqlmock.NewRows([]string{"id", "model", "serial_number", "status", "assigned_to"})
+	for id := 1; id <= handlers.MaxLabelSheetItems+1; id++ {
+		rows.AddRow(id, "Laptop", "SN", "available", nil)
+	}
+	mock.ExpectQuery(`^SELECT id, model, serial_number, status, assigned_to FROM equipment WHERE status = \$1 ORDER BY id LIMIT \$2$`).
+		WithArgs("available", handlers.MaxLabelSheetItems+1).
+		WillReturnRows(rows)
+
+	// Вызываем метод
+	handler := handlers.NewLabelHandler(services.NewEquipmentService(db))
+	recorder := httptest.NewRecorder()
+	handler.GetEquipmentLabelSheetHandler(recorder, httptest.NewRequest(http.MethodGet, "/equipment/labels?status=available", nil))
+
+	// Проверяем результаты
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"field":"filter"`)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
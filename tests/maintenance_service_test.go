@@ -0,0 +1,289 @@
+package services_test
+
+import (
+	"inva/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// maintenanceColumns столбцы выборки записи о ремонте
+var maintenanceColumns = []string{"id", "equipment_id", "model", "serial_number", "assigned_to", "issue", "vendor", "cost",
+	"outcome", "note", "previous_status", "opened_at", "closed_at"}
+
+func TestOpenMaintenance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewMaintenanceService(db)
+	openedAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	// Выданный ноутбук уходит в ремонт, прежний статус запоминается в записи
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("in use"))
+	mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM maintenance_records WHERE equipment_id = \\$1 AND closed_at IS NULL\\)").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery("INSERT INTO maintenance_records").
+		WithArgs(7, "Broken keyboard", "ServiceCo", nil, "in use", openedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	expectLockEquipment(mock, 7, "in use", 5)
+	mock.ExpectExec("UPDATE equipment SET status = \\$1, (.+) WHERE id = \\$2").
+		WithArgs(services.EquipmentStatusInRepair, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT (.+) FROM maintenance_records m (.+) WHERE m.id = \\$1").
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows(maintenanceColumns).
+			AddRow(4, 7, "Laptop", "1234", 5, "Broken keyboard", "ServiceCo", nil, "", "", "in use", openedAt, nil))
+
+	// Вызываем метод
+	record, err := service.OpenMaintenance(7, services.MaintenanceOpening{Issue: "Broken keyboard", Vendor: "ServiceCo", OpenedAt: &openedAt})
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, "INV-000007", record.AssetTag)
+	assert.Equal(t, "in use", record.PreviousStatus)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestOpenMaintenanceAlreadyInRepair(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewMaintenanceService(db)
+
+	// Второй открытый ремонт того же оборудования не создаётся
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(services.EquipmentStatusInRepair))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.OpenMaintenance(7, services.MaintenanceOpening{Issue: "Broken keyboard"})
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrMaintenanceOpen)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestCloseMaintenanceNotRepaired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewMaintenanceService(db)
+	openedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	closedAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	cost := 120.0
+
+	// Неудачный ремонт переводит оборудование в статус broken вместо прежнего
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT equipment_id, previous_status, opened_at, closed_at FROM maintenance_records WHERE id = \\$1 FOR UPDATE").
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"equipment_id", "previous_status", "opened_at", "closed_at"}).
+			AddRow(7, "in use", openedAt, nil))
+	mock.ExpectExec("UPDATE maintenance_records SET outcome = \\$1").
+		WithArgs(services.MaintenanceOutcomeNotRepaired, &cost, "Motherboard is not produced anymore", closedAt, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectLockEquipment(mock, 7, services.EquipmentStatusInRepair, 5)
+	mock.ExpectExec("UPDATE equipment SET status = \\$1, (.+) WHERE id = \\$2").
+		WithArgs(services.EquipmentStatusBroken, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT (.+) FROM maintenance_records m (.+) WHERE m.id = \\$1").
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows(maintenanceColumns).
+			AddRow(4, 7, "Laptop", "1234", 5, "Broken keyboard", "ServiceCo", cost, services.MaintenanceOutcomeNotRepaired,
+				"Motherboard is not produced anymore", "in use", openedAt, closedAt))
+
+	// Вызываем метод
+	record, err := service.CloseMaintenance(4, services.MaintenanceClosing{
+		Outcome:  services.MaintenanceOutcomeNotRepaired,
+		Cost:     &cost,
+		Note:     "Motherboard is not produced anymore",
+		ClosedAt: &closedAt,
+	})
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, services.MaintenanceOutcomeNotRepaired, record.Outcome)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestCloseMaintenanceAfterWriteOff(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewMaintenanceService(db)
+	openedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	closedAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	// Оборудование списали во время ремонта: прежний статус ему не возвращается
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT equipment_id, previous_status, opened_at, closed_at FROM maintenance_records WHERE id = \\$1 FOR UPDATE").
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"equipment_id", "previous_status", "opened_at", "closed_at"}).
+			AddRow(7, "in use", openedAt, nil))
+	mock.ExpectExec("UPDATE maintenance_records SET outcome = \\$1").
+		WithArgs(services.MaintenanceOutcomeRepaired, nil, "", closedAt, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectLockEquipment(mock, 7, services.HistoryStatusWrittenOff, nil)
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT (.+) FROM maintenance_records m (.+) WHERE m.id = \\$1").
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows(maintenanceColumns).
+			AddRow(4, 7, "Laptop", "1234", nil, "Broken keyboard", "ServiceCo", nil, services.MaintenanceOutcomeRepaired,
+				"", "in use", openedAt, closedAt))
+
+	// Вызываем метод
+	record, err := service.CloseMaintenance(4, services.MaintenanceClosing{
+		Outcome:  services.MaintenanceOutcomeRepaired,
+		ClosedAt: &closedAt,
+	})
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, services.MaintenanceOutcomeRepaired, record.Outcome)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestAssignEquipmentInRepair(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Оборудование в ремонте не выдаётся, пока ремонт не закрыт
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 7, services.EquipmentStatusInRepair, nil)
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	err = service.AssignEquipment(7, 5, services.AssignOptions{})
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrMaintenanceOpen)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestUpdateEquipmentInRepairStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		status  string
+	}{
+		{"ремонт без записи о ремонте", "available", services.EquipmentStatusInRepair},
+		{"выход из ремонта без его закрытия", services.EquipmentStatusInRepair, "available"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Ошибка при создании mock DB: %v", err)
+			}
+			defer db.Close()
+
+			service := services.NewEquipmentService(db)
+			mock.ExpectBegin()
+			expectLockEquipment(mock, 7, tt.current, nil)
+			mock.ExpectRollback()
+
+			// Вызываем метод
+			err = service.UpdateEquipment(7, "Laptop", "1234", tt.status)
+
+			// Проверяем результаты
+			assertStatusNotAllowed(t, err)
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("Ожидания не были удовлетворены: %v", err)
+			}
+		})
+	}
+}
+
+func TestPatchEquipmentOutOfRepair(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+	status := "available"
+
+	// Пока ремонт открыт, PATCH не возвращает оборудование в оборот
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows(patchColumns).
+			AddRow(7, "Laptop", "1234", services.EquipmentStatusInRepair, 5, nil, nil, nil, nil, nil, nil, "USD", nil, nil, 3))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.PatchEquipment(7, 0, services.EquipmentPatch{Status: &status})
+
+	// Проверяем результаты
+	assertStatusNotAllowed(t, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestExecuteBulkInRepairStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Пакетная смена статуса не отправляет оборудование в ремонт
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 7, "available", nil)
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	results, err := service.ExecuteBulk([]services.BulkOperation{
+		{Op: services.BulkUpdateStatus, ID: 7, Status: services.EquipmentStatusInRepair},
+	})
+
+	// Проверяем результаты
+	assertStatusNotAllowed(t, err)
+	assert.Equal(t, services.BulkResultFailed, results[0].Result)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package services_test
+
+import (
+	"inva/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffboard(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewOffboardingService(db)
+	dueAt := time.Date(2026, time.November, 2, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+
+	// Сотрудник становится неактивным, а на закреплённое оборудование создаются задачи на возврат
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(services.EmployeeStatusActive))
+	mock.ExpectExec("UPDATE employees SET status = \\$1, (.+) WHERE id = \\$2").
+		WithArgs(services.EmployeeStatusInactive, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO return_tasks (.+) WHERE e.assigned_to = \\$1 AND NOT EXISTS").
+		WithArgs(5, dueAt, services.ReturnTaskStatusOpen).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("SELECT (.+) FROM return_tasks t JOIN equipment e ON e.id = t.equipment_id WHERE t.employee_id = \\$1").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "employee_id", "equipment_id", "model", "serial_number",
+			"due_at", "status", "note", "created_at", "resolved_at"}).
+			AddRow(1, 5, 7, "Laptop", "SN-7", dueAt, services.ReturnTaskStatusOpen, "", createdAt, nil).
+			AddRow(2, 5, 8, "Monitor", "SN-8", dueAt, services.ReturnTaskStatusOpen, "", createdAt, nil))
+	mock.ExpectCommit()
+
+	// Вызываем метод
+	offboarding, err := service.Offboard(5, dueAt)
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, services.EmployeeStatusInactive, offboarding.Status)
+	assert.Equal(t, 2, offboarding.OpenTasks)
+	assert.Equal(t, "INV-000007", offboarding.Tasks[0].AssetTag)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestDeactivateWithOpenReturnTasks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewOffboardingService(db)
+
+	// Пока не всё оборудование возвращено или списано, сотрудник остаётся неактивным
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(services.EmployeeStatusInactive))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM return_tasks WHERE employee_id = \\$1 AND status = \\$2").
+		WithArgs(5, services.ReturnTaskStatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.Deactivate(5)
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrOffboardingIncomplete)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestDeactivateWithAssignedEquipment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewOffboardingService(db)
+
+	// Задачи на возврат закрыты, но за сотрудником ещё числится оборудование без задачи
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(services.EmployeeStatusInactive))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM return_tasks WHERE employee_id = \\$1 AND status = \\$2").
+		WithArgs(5, services.ReturnTaskStatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM equipment WHERE assigned_to = \\$1").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.Deactivate(5)
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrOffboardingIncomplete)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestAssignEquipmentToInactiveEmployee(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// За увольняемым сотрудником новое оборудование не закрепляется; строка сотрудника блокируется,
+	// чтобы выдача и увольнение не шли одновременно
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 7, "available", nil)
+	mock.ExpectQuery("SELECT status FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(services.EmployeeStatusInactive))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	err = service.AssignEquipment(7, 5, services.AssignOptions{})
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrEmployeeInactive)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestAssignWrittenOffEquipment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Списанное оборудование больше не выдаётся, сотрудник не проверяется
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 7, services.HistoryStatusWrittenOff, nil)
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	err = service.AssignEquipment(7, 5, services.AssignOptions{})
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrEquipmentWrittenOff)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
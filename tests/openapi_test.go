@@ -0,0 +1,110 @@
+package services_test
+
+import (
+	"inva/pkg/openapi"
+	"inva/routes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// routeVariable переменная шаблона маршрута mux с регулярным выражением, например {id:[0-9]+}
+var routeVariable = regexp.MustCompile(`\{([^}:]+):[^}]+\}`)
+
+// newRouter создаёт маршрутизатор приложения с тестовой базой данных
+func newRouter(t *testing.T) *mux.Router {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	r := mux.NewRouter()
+	routes.SetupRoutes(r, db)
+	return r
+}
+
+// registeredOperations возвращает операции всех маршрутов в виде "метод путь" в нотации OpenAPI
+func registeredOperations(t *testing.T) map[string]bool {
+	operations := make(map[string]bool)
+	err := newRouter(t).Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		path := routeVariable.ReplaceAllString(template, "{$1}")
+		for _, method := range methods {
+			operations[strings.ToLower(method)+" "+path] = true
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	return operations
+}
+
+func TestOpenAPICoversAllRoutes(t *testing.T) {
+	document, err := openapi.Parse()
+	if err != nil {
+		t.Fatalf("Описание API не разбирается: %v", err)
+	}
+	assert.True(t, strings.HasPrefix(document.OpenAPI, "3."))
+
+	// Пути в описании указаны относительно сервера /api/v1
+	registered := make(map[string]bool)
+	legacy := make(map[string]bool)
+	for operation := range registeredOperations(t) {
+		method, path, _ := strings.Cut(operation, " ")
+		if strings.HasPrefix(path, routes.APIv1Prefix+"/") {
+			registered[method+" "+strings.TrimPrefix(path, routes.APIv1Prefix)] = true
+		} else {
+			legacy[operation] = true
+		}
+	}
+	assert.NotEmpty(t, registered)
+	assert.Equal(t, registered, legacy, "пути без версии должны повторять маршруты v1")
+
+	// Каждый маршрут должен быть описан
+	for operation := range registered {
+		method, path, _ := strings.Cut(operation, " ")
+		_, ok := document.Paths[path][method]
+		assert.True(t, ok, "маршрут %s %s отсутствует в openapi.json", strings.ToUpper(method), path)
+	}
+
+	// И в описании не должно быть несуществующих маршрутов
+	for path, item := range document.Paths {
+		for method := range item {
+			if method == "parameters" {
+				continue
+			}
+			assert.True(t, registered[method+" "+path], "операция %s %s из openapi.json не зарегистрирована", strings.ToUpper(method), path)
+		}
+	}
+}
+
+func TestLegacyRoutesAreDeprecated(t *testing.T) {
+	r := newRouter(t)
+
+	// Путь без версии работает, но сообщает о замене
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/openapi.json?pretty=1", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.True(t, strings.HasPrefix(recorder.Header().Get("Deprecation"), "@"))
+	assert.NotEmpty(t, recorder.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v1/openapi.json?pretty=1>; rel="successor-version"`, recorder.Header().Get("Link"))
+
+	// Версионированный путь заголовков устаревания не содержит
+	recorder = httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Deprecation"))
+}
@@ -0,0 +1,49 @@
+package services_test
+
+import (
+	"inva/pkg/events"
+	"inva/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverdueCheckerPublishesEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	issuedAt := now.AddDate(0, 0, -10)
+	dueAt := now.AddDate(0, 0, -3)
+
+	// Выдачи, о которых сообщали меньше суток назад, не отмечаются и не возвращаются запросом
+	rows := sqlmock.NewRows([]string{"id", "model", "serial_number", "user_id", "name", "issued_at", "due_at"}).
+		AddRow(4, "Projector", "P-1", 5, "John Doe", issuedAt, dueAt)
+	mock.ExpectQuery("UPDATE equipment_logs SET overdue_notified_at = \\$1 (.+) AND \\(overdue_notified_at IS NULL OR overdue_notified_at <= \\$2\\)").
+		WithArgs(now, now.Add(-24*time.Hour)).
+		WillReturnRows(rows)
+
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe(func(event events.Event) {
+		published = append(published, event)
+	})
+
+	// Вызываем метод
+	checker := services.NewOverdueChecker(services.NewEquipmentService(db), bus, time.Hour)
+	checker.Check(now)
+
+	// Проверяем результаты
+	assert.Len(t, published, 1)
+	assert.Equal(t, services.EventEquipmentOverdue, published[0].Type)
+	assert.Equal(t, "INV-000004", published[0].Payload["asset_tag"])
+	assert.Equal(t, 3, published[0].Payload["overdue_days"])
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
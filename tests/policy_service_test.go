@@ -0,0 +1,186 @@
+package services_test
+
+import (
+	"inva/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// policyColumns столбцы выборки политик выдачи
+var policyColumns = []string{"id", "rule", "category", "max_items", "allowed_departments", "allowed_positions", "value_threshold", "created_at"}
+
+// expectNoPolicies ожидает проверку политик выдачи при пустом списке правил
+func expectNoPolicies(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT (.+) FROM assignment_policies ORDER BY id").
+		WillReturnRows(sqlmock.NewRows(policyColumns))
+}
+
+// expectPolicyCheck ожидает проверку выдачи ноутбука стоимостью 2500 сотруднику 5 из отдела engineering
+func expectPolicyCheck(mock sqlmock.Sqlmock) {
+	createdAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 7, "available", nil)
+	mock.ExpectQuery("SELECT status FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(services.EmployeeStatusActive))
+	mock.ExpectQuery("SELECT (.+) FROM assignment_policies ORDER BY id").
+		WillReturnRows(sqlmock.NewRows(policyColumns).
+			AddRow(1, services.PolicyMaxPerCategory, "laptop", 1, "", "", 0, createdAt).
+			AddRow(2, services.PolicyRestrictedCategory, "monitor", 0, "design", "", 0, createdAt).
+			AddRow(3, services.PolicyApprovalThreshold, "", 0, "", "", 2000, createdAt))
+	mock.ExpectQuery("SELECT COALESCE\\(category, ''\\), purchase_cost FROM equipment WHERE id = \\$1").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"category", "purchase_cost"}).AddRow("laptop", 2500.0))
+	// Сотрудник блокируется до подсчёта выданного ему оборудования
+	mock.ExpectQuery("SELECT COALESCE\\(department, ''\\), COALESCE\\(position, ''\\) FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"department", "position"}).AddRow("engineering", "developer"))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM equipment WHERE assigned_to = \\$1").
+		WithArgs(5, "laptop").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+}
+
+func TestAssignEquipmentPolicyViolations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Второй ноутбук дороже порога нарушает лимит и требует одобрения; правило для мониторов не применяется
+	expectPolicyCheck(mock)
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	err = service.AssignEquipmentToUser(7, 5)
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrPolicyViolation)
+	var policyErr *services.PolicyViolationError
+	assert.ErrorAs(t, err, &policyErr)
+	if assert.Len(t, policyErr.Violations, 2) {
+		assert.Equal(t, services.ViolationLimitExceeded, policyErr.Violations[0].Code)
+		assert.Equal(t, services.ViolationApprovalNeeded, policyErr.Violations[1].Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestMaxPerCategoryLocksEmployee(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+	createdAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	// Лимит считается только после блокировки сотрудника, иначе две одновременные выдачи
+	// увидели бы одно и то же число и вместе превысили лимит
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 7, "available", nil)
+	mock.ExpectQuery("SELECT status FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(services.EmployeeStatusActive))
+	mock.ExpectQuery("SELECT (.+) FROM assignment_policies ORDER BY id").
+		WillReturnRows(sqlmock.NewRows(policyColumns).
+			AddRow(1, services.PolicyMaxPerCategory, "laptop", 2, "", "", 0, createdAt))
+	mock.ExpectQuery("SELECT COALESCE\\(category, ''\\), purchase_cost FROM equipment WHERE id = \\$1").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"category", "purchase_cost"}).AddRow("laptop", nil))
+	mock.ExpectQuery("SELECT (.+) FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"department", "position"}).AddRow("engineering", "developer"))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM equipment WHERE assigned_to = \\$1").
+		WithArgs(5, "laptop").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec("UPDATE equipment SET assigned_to = \\$1").
+		WithArgs(5, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO equipment_logs").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Вызываем метод
+	err = service.AssignEquipmentToUser(7, 5)
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestAssignEquipmentPolicyOverride(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Администратор разрешает выдачу, исключение записывается в журнал вместе с обоснованием
+	expectPolicyCheck(mock)
+	mock.ExpectQuery("SELECT role FROM employees WHERE id = \\$1").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(services.EmployeeRoleAdmin))
+	mock.ExpectExec("INSERT INTO policy_overrides").
+		WithArgs(7, 5, 2, "Replacement while the first laptop is in repair", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE equipment SET assigned_to = \\$1, (.+) WHERE id = \\$2").
+		WithArgs(5, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO equipment_logs").
+		WithArgs(7, 5, services.HistoryStatusIssued, nil, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Вызываем метод
+	err = service.AssignEquipment(7, 5, services.AssignOptions{Override: &services.PolicyOverride{
+		AdminID:       2,
+		Justification: "Replacement while the first laptop is in repair",
+	}})
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestAssignEquipmentPolicyOverrideRequiresAdmin(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Исключение от обычного сотрудника отклоняется
+	expectPolicyCheck(mock)
+	mock.ExpectQuery("SELECT role FROM employees WHERE id = \\$1").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(services.EmployeeRoleUser))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	err = service.AssignEquipment(7, 5, services.AssignOptions{Override: &services.PolicyOverride{
+		AdminID:       3,
+		Justification: "Urgent",
+	}})
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrPolicyOverrideDenied)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
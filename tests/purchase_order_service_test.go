@@ -0,0 +1,129 @@
+package services_test
+
+import (
+	"inva/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// receiveLineQuery выборка позиции заказа с блокировкой перед приёмкой
+const receiveLineQuery = "SELECT l.model, (.+) FROM purchase_order_lines l (.+) WHERE l.id = \\$1 AND l.order_id = \\$2 FOR UPDATE OF l, o"
+
+// receiveLineColumns столбцы выборки позиции заказа перед приёмкой
+var receiveLineColumns = []string{"model", "category", "quantity", "unit_cost", "warranty_months", "received_quantity", "currency"}
+
+func TestReceivePurchaseOrderLine(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewPurchaseOrderService(db)
+	receivedAt := time.Date(2026, 10, 19, 15, 30, 0, 0, time.UTC)
+	purchaseDate := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	warrantyExpiresAt := time.Date(2029, 10, 19, 0, 0, 0, 0, time.UTC)
+	serials := []string{"SN-001", "SN-002"}
+
+	// Два ноутбука из пяти заказанных: оборудование получает цену, валюту и гарантию из заказа
+	mock.ExpectBegin()
+	mock.ExpectQuery(receiveLineQuery).
+		WithArgs(12, 4).
+		WillReturnRows(sqlmock.NewRows(receiveLineColumns).AddRow("ThinkPad T14", "laptop", 5, 1350.0, 36, 1, "EUR"))
+	mock.ExpectQuery("SELECT serial_number FROM equipment WHERE serial_number = ANY\\(\\$1\\)").
+		WithArgs(pq.Array(serials)).
+		WillReturnRows(sqlmock.NewRows([]string{"serial_number"}))
+	for i, serial := range serials {
+		mock.ExpectQuery("INSERT INTO equipment (.+) RETURNING id, version").
+			WithArgs("ThinkPad T14", serial, "available", "Warehouse", "laptop", 1350.0, purchaseDate, warrantyExpiresAt, "EUR", 12).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(30+i, 1))
+	}
+	mock.ExpectExec("UPDATE purchase_order_lines SET received_quantity = received_quantity \\+ \\$1 WHERE id = \\$2").
+		WithArgs(2, 12).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("UPDATE purchase_orders SET status = CASE (.+) RETURNING status").
+		WithArgs(4, services.PurchaseOrderPartiallyReceived, services.PurchaseOrderReceived).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(services.PurchaseOrderPartiallyReceived))
+	mock.ExpectCommit()
+
+	// Вызываем метод
+	result, err := service.ReceiveLine(4, 12, services.Receipt{SerialNumbers: serials, Location: "Warehouse", ReceivedAt: &receivedAt})
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Remaining)
+	assert.Equal(t, services.PurchaseOrderPartiallyReceived, result.OrderStatus)
+	if assert.Len(t, result.Equipment, 2) {
+		assert.Equal(t, 31, result.Equipment[1].ID)
+		assert.Equal(t, "SN-002", result.Equipment[1].SerialNumber)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestReceivePurchaseOrderLineRejectsTakenSerial(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewPurchaseOrderService(db)
+	serials := []string{"SN-001", "SN-002"}
+
+	// Серийный номер уже есть в инвентаре: ничего не создаётся, транзакция откатывается
+	mock.ExpectBegin()
+	mock.ExpectQuery(receiveLineQuery).
+		WithArgs(12, 4).
+		WillReturnRows(sqlmock.NewRows(receiveLineColumns).AddRow("ThinkPad T14", "laptop", 5, 1350.0, 0, 0, "USD"))
+	mock.ExpectQuery("SELECT serial_number FROM equipment WHERE serial_number = ANY\\(\\$1\\)").
+		WithArgs(pq.Array(serials)).
+		WillReturnRows(sqlmock.NewRows([]string{"serial_number"}).AddRow("SN-002"))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.ReceiveLine(4, 12, services.Receipt{SerialNumbers: serials})
+
+	// Проверяем результаты
+	var validationErr *services.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "serial_numbers[1]", validationErr.Fields[0].Field)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestReceivePurchaseOrderLineOverQuantity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewPurchaseOrderService(db)
+
+	// По позиции осталась одна единица, а принимаются две
+	mock.ExpectBegin()
+	mock.ExpectQuery(receiveLineQuery).
+		WithArgs(12, 4).
+		WillReturnRows(sqlmock.NewRows(receiveLineColumns).AddRow("ThinkPad T14", "laptop", 5, 1350.0, 0, 4, "USD"))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.ReceiveLine(4, 12, services.Receipt{SerialNumbers: []string{"SN-001", "SN-002"}})
+
+	// Проверяем результаты
+	var validationErr *services.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "serial_numbers", validationErr.Fields[0].Field)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package services_test
+
+import (
+	"inva/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// requestColumns столбцы выборки заявки на оборудование
+var requestColumns = []string{"id", "employee_id", "category", "justification", "status", "manager_id",
+	"decision_note", "equipment_id", "created_at", "decided_at", "fulfilled_at"}
+
+// expectLockRequest ожидает блокировку заявки 3 сотрудника 5 на ноутбук с указанным статусом
+func expectLockRequest(mock sqlmock.Sqlmock, status string) {
+	mock.ExpectQuery("SELECT status, employee_id, category FROM equipment_requests WHERE id = \\$1 FOR UPDATE").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "employee_id", "category"}).AddRow(status, 5, "laptop"))
+}
+
+func TestApproveRequest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewRequestService(db)
+	createdAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	decidedAt := createdAt.Add(2 * time.Hour)
+
+	// Руководитель одобряет заявку, ожидающую рассмотрения
+	mock.ExpectBegin()
+	expectLockRequest(mock, services.RequestStatusPending)
+	mock.ExpectQuery("SELECT role FROM employees WHERE id = \\$1").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(services.EmployeeRoleManager))
+	mock.ExpectExec("UPDATE equipment_requests SET status = \\$1, manager_id = \\$2").
+		WithArgs(services.RequestStatusApproved, 2, "", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT (.+) FROM equipment_requests WHERE id = \\$1").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows(requestColumns).
+			AddRow(3, 5, "laptop", "Current laptop is too slow", services.RequestStatusApproved, 2, "", nil, createdAt, decidedAt, nil))
+
+	// Вызываем метод
+	request, err := service.Approve(3, 2, "")
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, services.RequestStatusApproved, request.Status)
+	if assert.NotNil(t, request.ManagerID) {
+		assert.Equal(t, 2, *request.ManagerID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestApproveRequestByRegularEmployee(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewRequestService(db)
+
+	// Обычный сотрудник не может принимать решения по заявкам
+	mock.ExpectBegin()
+	expectLockRequest(mock, services.RequestStatusPending)
+	mock.ExpectQuery("SELECT role FROM employees WHERE id = \\$1").
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(services.EmployeeRoleUser))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.Approve(3, 4, "")
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrApprovalDenied)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestApproveOwnRequest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewRequestService(db)
+
+	// Руководитель не может одобрить собственную заявку, даже имея нужную роль
+	mock.ExpectBegin()
+	expectLockRequest(mock, services.RequestStatusPending)
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.Approve(3, 5, "")
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrApprovalDenied)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestFulfillRequest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewRequestService(db)
+	createdAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	// Одобренная заявка выполняется выдачей ноутбука автору заявки
+	mock.ExpectBegin()
+	expectLockRequest(mock, services.RequestStatusApproved)
+	mock.ExpectQuery("SELECT COALESCE\\(category, ''\\) FROM equipment WHERE id = \\$1").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"category"}).AddRow("Laptop"))
+	expectLockEquipment(mock, 7, "available", nil)
+	mock.ExpectQuery("SELECT status FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(services.EmployeeStatusActive))
+	expectNoPolicies(mock)
+	mock.ExpectExec("UPDATE equipment SET assigned_to = \\$1, (.+) WHERE id = \\$2").
+		WithArgs(5, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO equipment_logs").
+		WithArgs(7, 5, services.HistoryStatusIssued, nil, "заявка #3").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE equipment_requests SET status = \\$1, equipment_id = \\$2, fulfilled_at = NOW\\(\\) WHERE id = \\$3").
+		WithArgs(services.RequestStatusFulfilled, 7, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT (.+) FROM equipment_requests WHERE id = \\$1").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows(requestColumns).
+			AddRow(3, 5, "laptop", "Current laptop is too slow", services.RequestStatusFulfilled, 2, "", 7, createdAt, createdAt, createdAt))
+
+	// Вызываем метод
+	request, err := service.Fulfill(3, 7, services.AssignOptions{})
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, services.RequestStatusFulfilled, request.Status)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestFulfillPendingRequest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewRequestService(db)
+
+	// Заявку без одобрения выполнить нельзя
+	mock.ExpectBegin()
+	expectLockRequest(mock, services.RequestStatusPending)
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.Fulfill(3, 7, services.AssignOptions{})
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrRequestNotApproved)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
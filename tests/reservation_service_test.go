@@ -0,0 +1,124 @@
+package services_test
+
+import (
+	"bytes"
+	"database/sql"
+	"inva/pkg/ical"
+	"inva/services"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateReservationConflictWithAssignment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewReservationService(db)
+	startsAt := time.Now().Add(24 * time.Hour)
+	endsAt := startsAt.Add(4 * time.Hour)
+
+	// Оборудование выдано бессрочно, поэтому бронирование невозможно
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 3, "in use", 5)
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(8).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("SELECT id FROM reservations").
+		WithArgs(3, services.ReservationStatusActive, startsAt, endsAt).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT due_at FROM equipment_logs").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"due_at"}).AddRow(nil))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	_, err = service.CreateReservation(&services.Reservation{EquipmentID: 3, UserID: 8, StartsAt: startsAt, EndsAt: endsAt})
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrReservationConflict)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestCreateReservationUnavailableStatus(t *testing.T) {
+	startsAt := time.Now().Add(24 * time.Hour)
+	endsAt := startsAt.Add(4 * time.Hour)
+
+	// Утилизированное, списанное и находящееся в ремонте оборудование не бронируется
+	for status, want := range map[string]error{
+		services.EquipmentStatusDisposed: services.ErrEquipmentDisposed,
+		services.HistoryStatusWrittenOff: services.ErrEquipmentWrittenOff,
+		services.EquipmentStatusInRepair: services.ErrMaintenanceOpen,
+	} {
+		t.Run(status, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Ошибка при создании mock DB: %v", err)
+			}
+			defer db.Close()
+
+			service := services.NewReservationService(db)
+
+			mock.ExpectBegin()
+			expectLockEquipment(mock, 3, status, nil)
+			mock.ExpectRollback()
+
+			// Вызываем метод
+			_, err = service.CreateReservation(&services.Reservation{EquipmentID: 3, UserID: 8, StartsAt: startsAt, EndsAt: endsAt})
+
+			// Проверяем результаты
+			assert.ErrorIs(t, err, want)
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("Ожидания не были удовлетворены: %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateReservationInvalidInterval(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewReservationService(db)
+	startsAt := time.Now().Add(time.Hour)
+
+	_, err = service.CreateReservation(&services.Reservation{EquipmentID: 3, UserID: 8, StartsAt: startsAt, EndsAt: startsAt})
+	assert.ErrorIs(t, err, services.ErrInvalidReservation)
+}
+
+func TestCalendarWrite(t *testing.T) {
+	start := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	calendar := ical.Calendar{
+		ProductID: "-//inva//test//EN",
+		Name:      "Camera",
+		Events: []ical.Event{{
+			UID:         "reservation-1@inva",
+			Start:       start,
+			End:         start.Add(2 * time.Hour),
+			Summary:     "Съёмка, студия; этаж 2",
+			Description: strings.Repeat("длинное описание ", 10),
+		}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, calendar.Write(&buf))
+	output := buf.String()
+
+	assert.True(t, strings.HasPrefix(output, "BEGIN:VCALENDAR\r\n"))
+	assert.Contains(t, output, "DTSTART:20260504T090000Z\r\n")
+	assert.Contains(t, output, `SUMMARY:Съёмка\, студия\; этаж 2`)
+	for _, line := range strings.Split(output, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+}
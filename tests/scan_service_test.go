@@ -0,0 +1,81 @@
+package services_test
+
+import (
+	"inva/services"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanCheckout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	equipmentService := services.NewEquipmentService(db)
+	service := services.NewScanService(equipmentService, services.NewEmployeeService(db))
+
+	// Поиск оборудования по инвентарному номеру и сотрудника по email
+	mock.ExpectQuery("SELECT (.+) FROM equipment WHERE id = \\$1").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "model", "serial_number", "status", "assigned_to", "version", "updated_at"}).
+			AddRow(7, "Laptop", "1234", "available", nil, 1, "2026-10-01T10:00:00Z"))
+	mock.ExpectQuery("SELECT id, name FROM employees WHERE lower\\(email\\) = lower\\(\\$1\\)").
+		WithArgs("john@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(5, "John Doe"))
+
+	// Выдача выполняется в транзакции с записью в историю
+	mock.ExpectBegin()
+	expectLockEquipment(mock, 7, "available", nil)
+	mock.ExpectQuery("SELECT status FROM employees WHERE id = \\$1 FOR UPDATE").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(services.EmployeeStatusActive))
+	expectNoPolicies(mock)
+	mock.ExpectExec("UPDATE equipment SET assigned_to = \\$1, (.+) WHERE id = \\$2").
+		WithArgs(5, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO equipment_logs").
+		WithArgs(7, 5, services.HistoryStatusIssued, nil, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Вызываем метод
+	result, err := service.Checkout("inv-000007", "john@example.com")
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, services.ScanActionCheckout, result.Action)
+	assert.Equal(t, "INV-000007", result.AssetTag)
+	assert.Equal(t, "Laptop (INV-000007) checked out to John Doe", result.Message)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestScanCheckinNotAssigned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewScanService(services.NewEquipmentService(db), services.NewEmployeeService(db))
+
+	// Поиск оборудования по серийному номеру
+	mock.ExpectQuery("SELECT id, model, serial_number, status, assigned_to FROM equipment WHERE serial_number = \\$1").
+		WithArgs("SN-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "model", "serial_number", "status", "assigned_to"}).
+			AddRow(3, "Projector", "SN-1", "available", nil))
+
+	// Вызываем метод
+	_, err = service.Checkin("SN-1")
+
+	// Проверяем результаты
+	assert.ErrorIs(t, err, services.ErrEquipmentNotAssigned)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package services_test
+
+import (
+	"inva/pkg/events"
+	"inva/pkg/schedule"
+	"inva/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// Пятница, 17 октября 2026 года
+	after := time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"@every 6mo", time.Date(2027, 4, 16, 10, 30, 0, 0, time.UTC)},
+		{"@every 2w", time.Date(2026, 10, 30, 10, 30, 0, 0, time.UTC)},
+		{"@every 12h", time.Date(2026, 10, 16, 22, 30, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"*/15 10 * * *", time.Date(2026, 10, 16, 10, 45, 0, 0, time.UTC)},
+		{"0 0 1 1,7 *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// День месяца и день недели ограничены оба: срок наступает при совпадении любого из них
+		{"0 8 1 * 0", time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * 7", time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		s, err := schedule.Parse(tt.spec)
+		if assert.NoError(t, err, tt.spec) {
+			assert.Equal(t, tt.want, s.Next(after), tt.spec)
+		}
+	}
+
+	// 30 февраля не наступает никогда
+	s, err := schedule.Parse("0 0 30 2 *")
+	assert.NoError(t, err)
+	assert.True(t, s.Next(after).IsZero())
+
+	for _, spec := range []string{"", "@every", "@every 0d", "@every 3m", "* * *", "60 * * * *", "0 0 * 13 *", "5-1 * * * *", "*/0 * * * *"} {
+		_, err := schedule.Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestMaintenanceSchedulerGeneratesTasks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	completedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	// Калибровка осциллографов раз в полгода: прибор 3 ещё не обслуживался, прибор 4 обслужен в марте
+	mock.ExpectQuery("SELECT (.+) FROM maintenance_schedules ORDER BY id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "equipment_id", "category", "name", "spec", "created_at"}).
+			AddRow(1, nil, "oscilloscope", "Calibration", "@every 6mo", createdAt))
+	mock.ExpectQuery("SELECT e.id, (.+) FROM equipment e").
+		WithArgs(1, 0, "oscilloscope", services.HistoryStatusWrittenOff, services.EquipmentStatusDisposed, services.MaintenanceTaskOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "max"}).AddRow(3, nil).AddRow(4, completedAt))
+	mock.ExpectQuery("INSERT INTO maintenance_tasks").
+		WithArgs(1, 3, time.Date(2026, 7, 10, 9, 0, 0, 0, time.UTC), services.MaintenanceTaskOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+	// Задачу для прибора 4 успело создать выполнение предыдущей задачи: вставка ничего не возвращает
+	mock.ExpectQuery("INSERT INTO maintenance_tasks (.+) WHERE NOT EXISTS (.+) ON CONFLICT DO NOTHING").
+		WithArgs(1, 4, time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC), services.MaintenanceTaskOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	// Уведомление отправляется только о задачах, ещё не отмеченных предыдущими проходами
+	mock.ExpectQuery("WITH claimed AS \\(\\s*UPDATE maintenance_tasks SET notified_at = \\$2 (.+) AND notified_at IS NULL (.+) WHERE t.id IN \\(SELECT id FROM claimed\\)").
+		WithArgs(services.MaintenanceTaskOpen, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "schedule_id", "name", "equipment_id", "model", "due_at", "status", "completed_at", "note"}).
+			AddRow(10, 1, "Calibration", 3, "Rigol DS1054Z", time.Date(2026, 7, 10, 9, 0, 0, 0, time.UTC), services.MaintenanceTaskOpen, nil, ""))
+
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe(func(event events.Event) {
+		published = append(published, event)
+	})
+
+	// Вызываем метод
+	scheduler := services.NewMaintenanceScheduler(services.NewMaintenanceScheduleService(db), bus, time.Hour)
+	scheduler.Tick(now)
+
+	// Проверяем результаты
+	assert.Len(t, published, 1)
+	assert.Equal(t, services.EventMaintenanceDue, published[0].Type)
+	assert.Equal(t, "INV-000003", published[0].Payload["asset_tag"])
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestCompleteMaintenanceTask(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewMaintenanceScheduleService(db)
+	completedAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	nextDue := time.Date(2027, 4, 19, 9, 0, 0, 0, time.UTC)
+
+	// Следующий срок отсчитывается от даты выполнения
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT t.schedule_id, t.equipment_id, t.status, s.spec (.+) FOR UPDATE OF t").
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"schedule_id", "equipment_id", "status", "spec"}).
+			AddRow(1, 3, services.MaintenanceTaskOpen, "@every 6mo"))
+	mock.ExpectExec("UPDATE maintenance_tasks SET status = \\$1, completed_at = \\$2").
+		WithArgs(services.MaintenanceTaskDone, completedAt, "Calibrated, certificate #118", 10).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO maintenance_tasks").
+		WithArgs(1, 3, nextDue, services.MaintenanceTaskOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT (.+) FROM maintenance_tasks t (.+) WHERE t.id = \\$1").
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "schedule_id", "name", "equipment_id", "model", "due_at", "status", "completed_at", "note"}).
+			AddRow(10, 1, "Calibration", 3, "Rigol DS1054Z", time.Date(2026, 7, 10, 9, 0, 0, 0, time.UTC),
+				services.MaintenanceTaskDone, completedAt, "Calibrated, certificate #118"))
+
+	// Вызываем метод
+	task, err := service.CompleteTask(10, "Calibrated, certificate #118", completedAt)
+
+	// Проверяем результаты
+	assert.NoError(t, err)
+	assert.Equal(t, services.MaintenanceTaskDone, task.Status)
+	if assert.NotNil(t, task.NextDueAt) {
+		assert.Equal(t, nextDue, *task.NextDueAt)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package services_test
+
+import (
+	"inva/pkg/validation"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// itemRequest тестовое тело запроса с декларативными правилами
+type itemRequest struct {
+	Model  string       `json:"model" validate:"required,max=10"`
+	Status string       `json:"status" validate:"oneof=available in_use"`
+	DueAt  *time.Time   `json:"due_at" validate:"future"`
+	Parts  []partDetail `json:"parts" validate:"max=2"`
+}
+
+type partDetail struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func decodeItem(body string) error {
+	var request itemRequest
+	r := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	return validation.DecodeJSON(httptest.NewRecorder(), r, &request)
+}
+
+func TestValidationRules(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	err := validation.Struct(&itemRequest{
+		Model:  strings.Repeat("x", 11),
+		Status: "lost",
+		DueAt:  &past,
+		Parts:  []partDetail{{Name: "battery"}, {Name: " "}},
+	})
+
+	var validationErr *validation.Error
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []validation.FieldError{
+		{Field: "model", Code: validation.CodeTooLong, Message: "длина не должна превышать 10 символов"},
+		{Field: "status", Code: validation.CodeNotAllowed, Message: "допустимые значения: available, in_use"},
+		{Field: "due_at", Code: validation.CodeOutOfRange, Message: "должно быть в будущем"},
+		{Field: "parts[1].name", Code: validation.CodeRequired, Message: "обязательное поле"},
+	}, validationErr.Fields)
+
+	assert.NoError(t, validation.Struct(&itemRequest{Model: "Laptop"}))
+}
+
+func TestDecodeJSONRejectsMalformedBodies(t *testing.T) {
+	cases := map[string]struct {
+		body  string
+		field string
+		code  string
+	}{
+		"неизвестное поле":     {`{"model": "Laptop", "color": "black"}`, "color", validation.CodeUnknownField},
+		"данные после JSON":    {`{"model": "Laptop"} garbage`, "body", validation.CodeMalformed},
+		"неверный тип":         {`{"model": 42}`, "model", validation.CodeInvalidType},
+		"пустое тело":          {``, "body", validation.CodeRequired},
+		"обязательное поле":    {`{"status": "available"}`, "model", validation.CodeRequired},
+		"слишком большое тело": {`{"model": "` + strings.Repeat("x", validation.MaxBodyBytes) + `"}`, "body", validation.CodeTooLarge},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var validationErr *validation.Error
+			if assert.ErrorAs(t, decodeItem(c.body), &validationErr) {
+				assert.Equal(t, c.field, validationErr.Fields[0].Field)
+				assert.Equal(t, c.code, validationErr.Fields[0].Code)
+			}
+		})
+	}
+}
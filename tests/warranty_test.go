@@ -0,0 +1,118 @@
+package services_test
+
+import (
+	"inva/pkg/events"
+	"inva/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// warrantyColumns столбцы выборки отчёта об истекающих гарантиях
+var warrantyColumns = []string{"id", "model", "serial_number", "category", "assigned_to", "purchase_date", "warranty_expires_at", "support_contract"}
+
+func TestWarrantyCheckerPublishesExpiring(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+	today := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	expiresAt := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+
+	// Гарантия ноутбука истекает через две недели, в пределах срока предупреждения, и о ней ещё не предупреждали
+	mock.ExpectQuery("WITH claimed AS \\(\\s*UPDATE equipment SET warranty_notified_for = warranty_expires_at "+
+		"WHERE warranty_expires_at >= \\$1 AND warranty_expires_at <= \\$2 AND status NOT IN \\(\\$3, \\$4\\) "+
+		"AND warranty_notified_for IS DISTINCT FROM warranty_expires_at").
+		WithArgs(today, now.Add(30*24*time.Hour), services.HistoryStatusWrittenOff, services.EquipmentStatusDisposed).
+		WillReturnRows(sqlmock.NewRows(warrantyColumns).
+			AddRow(7, "Laptop", "1234", "laptop", 5, time.Date(2024, 11, 2, 0, 0, 0, 0, time.UTC), expiresAt, "SC-2024-031"))
+
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe(func(event events.Event) {
+		published = append(published, event)
+	})
+
+	// Вызываем метод
+	checker := services.NewWarrantyChecker(services.NewEquipmentService(db), bus, time.Hour, 0)
+	checker.Check(now)
+
+	// Проверяем результаты
+	if assert.Len(t, published, 1) {
+		assert.Equal(t, services.EventWarrantyExpiring, published[0].Type)
+		assert.Equal(t, "INV-000007", published[0].Payload["asset_tag"])
+		assert.Equal(t, "2026-11-02", published[0].Payload["warranty_expires_at"])
+		assert.Equal(t, 14, published[0].Payload["days_left"])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestWarrantyCheckerSkipsNotified(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Date(2026, 10, 20, 9, 30, 0, 0, time.UTC)
+
+	// О той же дате окончания гарантии уже предупредили накануне: отмечать нечего
+	mock.ExpectQuery("WITH claimed AS \\(\\s*UPDATE equipment SET warranty_notified_for = warranty_expires_at").
+		WithArgs(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), now.Add(30*24*time.Hour),
+			services.HistoryStatusWrittenOff, services.EquipmentStatusDisposed).
+		WillReturnRows(sqlmock.NewRows(warrantyColumns))
+
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe(func(event events.Event) {
+		published = append(published, event)
+	})
+
+	// Вызываем метод
+	checker := services.NewWarrantyChecker(services.NewEquipmentService(db), bus, time.Hour, 0)
+	checker.Check(now)
+
+	// Проверяем результаты
+	assert.Empty(t, published)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}
+
+func TestPatchEquipmentWarrantyBeforePurchase(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании mock DB: %v", err)
+	}
+	defer db.Close()
+
+	service := services.NewEquipmentService(db)
+
+	// Дата окончания гарантии сверяется с уже сохранённой датой покупки
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM equipment WHERE id = \\$1 FOR UPDATE").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(patchColumns).
+			AddRow(1, "Laptop", "1234", "available", nil, nil, nil, nil, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), nil, nil, "USD", nil, nil, 2))
+	mock.ExpectRollback()
+
+	// Вызываем метод
+	warranty := "2025-03-02"
+	_, err = service.PatchEquipment(1, 0, services.EquipmentPatch{WarrantyExpiresAt: &warranty})
+
+	// Проверяем результаты
+	var validationErr *services.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "warranty_expires_at", validationErr.Fields[0].Field)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Ожидания не были удовлетворены: %v", err)
+	}
+}